@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/service"
+)
+
+var ErrUsage = errors.New("unknown command")
+
+const usage = `Usage: fyningtime [-d] <command>
+
+Commands:
+  in       Clock in now
+  out      Clock out now
+  toggle   Clock in or out depending on the entries of today
+  status   Show whether you are clocked in
+  today    Show the time entries and figures of today
+  balance  Show the total overtime
+`
+
+// CLI records time entries from the command line without starting a window
+type CLI struct {
+	out      io.Writer
+	ts       *service.TimeEntryService
+	settings *model.Settings
+
+	// Returns the current time, can be replaced for testing
+	Now func() time.Time
+}
+
+func NewCLI(out io.Writer, ts *service.TimeEntryService, settings *model.Settings) *CLI {
+	return &CLI{
+		out:      out,
+		ts:       ts,
+		settings: settings,
+		Now:      time.Now,
+	}
+}
+
+// Runs the command given by the arguments
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(c.out, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "in":
+		wt, err := c.ts.ClockIn(c.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Clocked in at %s\n", wt.Time.Format(time.TimeOnly))
+	case "out":
+		wt, err := c.ts.ClockOut(c.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Clocked out at %s\n", wt.Time.Format(time.TimeOnly))
+	case "toggle":
+		wt, err := c.ts.Toggle(c.Now())
+		if err != nil {
+			return err
+		}
+		if wt.Type == "Begin" {
+			fmt.Fprintf(c.out, "Clocked in at %s\n", wt.Time.Format(time.TimeOnly))
+		} else {
+			fmt.Fprintf(c.out, "Clocked out at %s\n", wt.Time.Format(time.TimeOnly))
+		}
+	case "status":
+		return c.status()
+	case "today":
+		return c.today()
+	case "balance":
+		result, err := c.ts.Calculate(c.settings)
+		if err != nil {
+			return err
+		}
+		// Same format as the total overtime of the GUI
+		fmt.Fprintf(c.out, "Total overtime: %s\n", result.Total.String())
+	case "help":
+		fmt.Fprint(c.out, usage)
+	default:
+		fmt.Fprint(c.out, usage)
+		return fmt.Errorf("%w: %s", ErrUsage, args[0])
+	}
+	return nil
+}
+
+func (c *CLI) status() error {
+	now := c.Now()
+	status, err := c.ts.Status(c.settings, now)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Open != nil:
+		fmt.Fprintf(c.out, "Clocked in since %s (%s)\n",
+			status.Open.Time.Format(time.TimeOnly), model.FormatDuration(now.Sub(status.Open.Time)))
+	case status.Workday != nil:
+		fmt.Fprintln(c.out, "Clocked out")
+	default:
+		fmt.Fprintln(c.out, "No time entries today")
+	}
+	return nil
+}
+
+func (c *CLI) today() error {
+	status, err := c.ts.Status(c.settings, c.Now())
+	if err != nil {
+		return err
+	}
+	if status.Workday == nil {
+		fmt.Fprintln(c.out, "No time entries today")
+		return nil
+	}
+
+	for _, wt := range status.Worktimes {
+		fmt.Fprintf(c.out, "%-6s %s\n", wt.Type, wt.Time.Format(time.TimeOnly))
+	}
+	r := status.Result
+	// Same format as the timetable of the GUI
+	fmt.Fprintf(c.out, "Worktime:  %s\n", r.Worktime.String())
+	fmt.Fprintf(c.out, "Breaktime: %s\n", model.FormatDuration(r.Breaktime))
+	fmt.Fprintf(c.out, "Target:    %s\n", model.FormatDuration(r.Target))
+	fmt.Fprintf(c.out, "Overtime:  %s\n", r.Overtime.String())
+	return nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+	"github.com/FyningTime/FyningTime/app/service"
+)
+
+func newTestCLI(t *testing.T) (*CLI, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	ts := service.NewTimeEntryService(repotest.NewRepository(t))
+	return NewCLI(&out, ts, model.NewSettings("", "")), &out
+}
+
+func TestRun(t *testing.T) {
+	c, out := newTestCLI(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		clock   string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{"07:00:00", []string{"status"}, "No time entries today", nil},
+		{"08:00:00", []string{"in"}, "Clocked in at 08:00:00", nil},
+		{"08:05:00", []string{"in"}, "", service.ErrAlreadyClockedIn},
+		{"10:30:00", []string{"status"}, "Clocked in since 08:00:00 (2h30m)", nil},
+		{"12:00:00", []string{"toggle"}, "Clocked out at 12:00:00", nil},
+		{"12:30:00", []string{"toggle"}, "Clocked in at 12:30:00", nil},
+		{"16:30:00", []string{"out"}, "Clocked out at 16:30:00", nil},
+		{"16:31:00", []string{"out"}, "", service.ErrNotClockedIn},
+		{"17:00:00", []string{"today"}, "Worktime:  8h0m0s", nil},
+		{"17:00:00", []string{"balance"}, "Total overtime: 0s", nil},
+		{"17:00:00", []string{"lunch"}, "Usage:", ErrUsage},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " ")+" at "+tt.clock, func(t *testing.T) {
+			now, err := time.ParseInLocation(time.DateTime, "2025-08-04 "+tt.clock, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Now = func() time.Time { return now }
+			out.Reset()
+
+			err = c.Run(tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run(%v) error = %v, want %v", tt.args, err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("Run(%v) output %q does not contain %q", tt.args, out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/lang"
+)
+
+// BreakRule defines the mandatory break from a certain worked time on
+type BreakRule struct {
+	// Worked time from which the break is mandatory
+	Threshold time.Duration `json:"threshold"`
+	Break     time.Duration `json:"break"`
+}
+
+type BreakPolicy string
+
+const (
+	// §4 Arbeitszeitgesetz
+	BreakPolicyGermany BreakPolicy = "germany"
+	// §11 Arbeitszeitgesetz
+	BreakPolicyAustria BreakPolicy = "austria"
+	// Art. 15 Arbeitsgesetz
+	BreakPolicySwitzerland BreakPolicy = "switzerland"
+	// Art. L3121-16 Code du travail
+	BreakPolicyFrance BreakPolicy = "france"
+	// Working Time Regulations 1998
+	BreakPolicyUnitedKingdom BreakPolicy = "unitedKingdom"
+	// No mandatory breaks, only recorded pauses count
+	BreakPolicyNone BreakPolicy = "none"
+	// Rules defined by the user
+	BreakPolicyCustom BreakPolicy = "custom"
+)
+
+// All break policies in the order they are offered to the user
+var BreakPolicies = []BreakPolicy{
+	BreakPolicyGermany,
+	BreakPolicyAustria,
+	BreakPolicySwitzerland,
+	BreakPolicyFrance,
+	BreakPolicyUnitedKingdom,
+	BreakPolicyNone,
+	BreakPolicyCustom,
+}
+
+// Returns the preset rules of a policy, custom rules are not known here
+func BreakRulesForPolicy(policy BreakPolicy) []BreakRule {
+	switch policy {
+	case BreakPolicyGermany:
+		return []BreakRule{
+			{Threshold: 6 * time.Hour, Break: 30 * time.Minute},
+			{Threshold: 9 * time.Hour, Break: 45 * time.Minute},
+		}
+	case BreakPolicyAustria:
+		return []BreakRule{
+			{Threshold: 6 * time.Hour, Break: 30 * time.Minute},
+		}
+	case BreakPolicySwitzerland:
+		return []BreakRule{
+			{Threshold: 5*time.Hour + 30*time.Minute, Break: 15 * time.Minute},
+			{Threshold: 7 * time.Hour, Break: 30 * time.Minute},
+			{Threshold: 9 * time.Hour, Break: 60 * time.Minute},
+		}
+	case BreakPolicyFrance, BreakPolicyUnitedKingdom:
+		return []BreakRule{
+			{Threshold: 6 * time.Hour, Break: 20 * time.Minute},
+		}
+	default:
+		return []BreakRule{}
+	}
+}
+
+// Returns the mandatory break for the worked time, rules have to be sorted by threshold
+func MandatoryBreak(rules []BreakRule, worktime time.Duration) time.Duration {
+	var mandatory time.Duration
+	for _, r := range rules {
+		if worktime >= r.Threshold {
+			mandatory = r.Break
+		}
+	}
+	return mandatory
+}
+
+/*
+Parses break rules with one rule per line in the format
+
+	6h = 30m
+	9h = 45m
+
+The returned rules are sorted by threshold.
+*/
+func ParseBreakRules(text string) ([]BreakRule, error) {
+	rules := []BreakRule{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		threshold, brk, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid break rule %q", line)
+		}
+
+		t, err := time.ParseDuration(strings.TrimSpace(threshold))
+		if err != nil {
+			return nil, err
+		}
+		b, err := time.ParseDuration(strings.TrimSpace(brk))
+		if err != nil {
+			return nil, err
+		}
+		if t < 0 || b < 0 {
+			return nil, errors.New("break rules cannot be negative")
+		}
+
+		rules = append(rules, BreakRule{Threshold: t, Break: b})
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Threshold < rules[j].Threshold
+	})
+	return rules, nil
+}
+
+// Formats break rules in the format read by ParseBreakRules
+func FormatBreakRules(rules []BreakRule) string {
+	lines := make([]string, 0, len(rules))
+	for _, r := range rules {
+		lines = append(lines, FormatDuration(r.Threshold)+" = "+FormatDuration(r.Break))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func BreakPolicyToString(policy BreakPolicy) string {
+	switch policy {
+	case BreakPolicyGermany:
+		return lang.L("breakPolicyGermany")
+	case BreakPolicyAustria:
+		return lang.L("breakPolicyAustria")
+	case BreakPolicySwitzerland:
+		return lang.L("breakPolicySwitzerland")
+	case BreakPolicyFrance:
+		return lang.L("breakPolicyFrance")
+	case BreakPolicyUnitedKingdom:
+		return lang.L("breakPolicyUnitedKingdom")
+	case BreakPolicyNone:
+		return lang.L("breakPolicyNone")
+	default:
+		return lang.L("breakPolicyCustom")
+	}
+}
+
+func StringToBreakPolicy(policy string) BreakPolicy {
+	for _, p := range BreakPolicies {
+		if BreakPolicyToString(p) == policy {
+			return p
+		}
+	}
+	return BreakPolicyCustom
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBreakRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []BreakRule
+		wantErr bool
+	}{
+		{"empty", "", []BreakRule{}, false},
+		{"sorted by threshold", "9h = 45m\n\n 6h=30m ", BreakRulesForPolicy(BreakPolicyGermany), false},
+		{"fractional hours", "5h30m = 15m", []BreakRule{{5*time.Hour + 30*time.Minute, 15 * time.Minute}}, false},
+		{"missing separator", "6h 30m", nil, true},
+		{"invalid duration", "6 = 30", nil, true},
+		{"negative", "6h = -30m", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBreakRules(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBreakRulesRoundTrip(t *testing.T) {
+	for _, p := range BreakPolicies {
+		rules := BreakRulesForPolicy(p)
+		got, err := ParseBreakRules(FormatBreakRules(rules))
+		if err != nil || !reflect.DeepEqual(got, rules) {
+			t.Errorf("%s: got %v (%v), want %v", p, got, err, rules)
+		}
+	}
+}
+
+func TestMandatoryBreak(t *testing.T) {
+	rules := BreakRulesForPolicy(BreakPolicySwitzerland)
+	tests := []struct {
+		worktime time.Duration
+		want     time.Duration
+	}{
+		{5 * time.Hour, 0},
+		{5*time.Hour + 30*time.Minute, 15 * time.Minute},
+		{8 * time.Hour, 30 * time.Minute},
+		{10 * time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := MandatoryBreak(rules, tt.worktime); got != tt.want {
+			t.Errorf("MandatoryBreak(%v) = %v, want %v", tt.worktime, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,10 @@ const (
 	FYNINGTIMEDIR string = ".fyningtime"
 	SETTINGSFILE  string = "settings.json"
 	DBFILE        string = "fyningtime.db"
+	// Last time the app was running, removed on a clean shutdown
+	HEARTBEATFILE string = "heartbeat"
 
 	DATEFORMAT = "02.01.2006"
+	// Day and month without year, e.g. the carry over cut-off date
+	CARRYOVERFORMAT = "02.01"
 )
@@ -0,0 +1,71 @@
+package db
+
+import "time"
+
+type AbsenceType string
+
+const (
+	AbsenceVacation     AbsenceType = "vacation"
+	AbsenceSick         AbsenceType = "sick"
+	AbsenceCompTime     AbsenceType = "comptime"
+	AbsenceBusinessTrip AbsenceType = "businesstrip"
+	AbsenceTraining     AbsenceType = "training"
+	AbsenceParental     AbsenceType = "parental"
+	AbsenceUnpaid       AbsenceType = "unpaid"
+)
+
+var AbsenceTypes = []AbsenceType{
+	AbsenceVacation,
+	AbsenceSick,
+	AbsenceCompTime,
+	AbsenceBusinessTrip,
+	AbsenceTraining,
+	AbsenceParental,
+	AbsenceUnpaid,
+}
+
+type Absence struct {
+	ID        int64
+	Type      AbsenceType
+	StartDate time.Time
+	EndDate   time.Time
+	// Only the afternoon of the first day is absent
+	HalfDayStart bool
+	// Only the morning of the last day is absent
+	HalfDayEnd bool
+}
+
+// Returns which part of the given day is absent: 0, 0.5 or 1
+func (a *Absence) DayShare(date time.Time) float64 {
+	day := date.Format(time.DateOnly)
+	start := a.StartDate.Format(time.DateOnly)
+	end := a.EndDate.Format(time.DateOnly)
+
+	if day < start || day > end {
+		return 0
+	}
+	if (day == start && a.HalfDayStart) || (day == end && a.HalfDayEnd) {
+		return 0.5
+	}
+	return 1
+}
+
+/*
+Reports whether both absences share a day. An absence ending at noon and
+another one starting at noon on the same day do not overlap.
+*/
+func (a *Absence) Overlaps(o *Absence) bool {
+	start, end := a.StartDate.Format(time.DateOnly), a.EndDate.Format(time.DateOnly)
+	otherStart, otherEnd := o.StartDate.Format(time.DateOnly), o.EndDate.Format(time.DateOnly)
+
+	if end < otherStart || otherEnd < start {
+		return false
+	}
+	if end == otherStart && a.HalfDayEnd && o.HalfDayStart {
+		return false
+	}
+	if otherEnd == start && o.HalfDayEnd && a.HalfDayStart {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package db
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditAddWorkday     AuditAction = "addWorkday"
+	AuditAddWorktime    AuditAction = "addWorktime"
+	AuditUpdateWorktime AuditAction = "updateWorktime"
+	AuditDeleteWorktime AuditAction = "deleteWorktime"
+	AuditDeleteWorkday  AuditAction = "deleteWorkday"
+	AuditEditWorkday    AuditAction = "editWorkday"
+	AuditImport         AuditAction = "import"
+	AuditAddAbsence     AuditAction = "addAbsence"
+	AuditUpdateAbsence  AuditAction = "updateAbsence"
+	AuditDeleteAbsence  AuditAction = "deleteAbsence"
+)
+
+type AuditState string
+
+const (
+	// The change is in effect and can be undone
+	AuditDone AuditState = "done"
+	// The change was undone and can be redone
+	AuditUndone AuditState = "undone"
+	// The change was undone and another change was made afterwards
+	AuditDropped AuditState = "dropped"
+	// The change is kept for the correction history but cannot be undone anymore
+	AuditArchived AuditState = "archived"
+)
+
+// AuditSnapshot holds the rows touched by a change
+type AuditSnapshot struct {
+	Workdays  []*Workday  `json:"workdays,omitempty"`
+	Worktimes []*Worktime `json:"worktimes,omitempty"`
+	Absences  []*Absence  `json:"absences,omitempty"`
+}
+
+// AuditEntry is a recorded change with the rows before and after it
+type AuditEntry struct {
+	ID     int64
+	Time   time.Time
+	Action AuditAction
+	Old    AuditSnapshot
+	New    AuditSnapshot
+	State  AuditState
+	// Why the change was made, empty if no reason was given
+	Reason string
+}
@@ -0,0 +1,18 @@
+package db
+
+import "time"
+
+type Contract struct {
+	ID        int64
+	ValidFrom time.Time
+	// Hours per week as agreed in the contract
+	WeekHours float64
+	// Time which should be worked per weekday, indexed by time.Weekday
+	Targets      [7]time.Duration
+	VacationDays int
+}
+
+// Returns the time which should be worked on the weekday of the given date
+func (c *Contract) TargetFor(date time.Time) time.Duration {
+	return c.Targets[date.Weekday()]
+}
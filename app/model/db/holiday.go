@@ -0,0 +1,9 @@
+package db
+
+import "time"
+
+type Holiday struct {
+	ID   int64
+	Date time.Time
+	Name string
+}
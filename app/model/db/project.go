@@ -0,0 +1,45 @@
+package db
+
+import "time"
+
+// Project is a cost center which segments of worktime are booked on
+type Project struct {
+	ID   int64
+	Name string
+	// Short code for the timetable and the reports, e.g. the cost center number
+	Code string
+	// Color as hex value, e.g. #4e79a7
+	Color  string
+	Active bool
+	// Planned hours, 0 if the project has no budget
+	Budget time.Duration
+	// Percent of the budget the last warning was sent for, e.g. 80
+	BudgetWarned int
+}
+
+// Task is a part of a project
+type Task struct {
+	ID        int64
+	ProjectID int64
+	Name      string
+	Code      string
+	Color     string
+	Active    bool
+	Budget    time.Duration
+}
+
+// ProjectHours is the recorded time of a project and task, the ids are 0 if not attributed
+type ProjectHours struct {
+	ProjectID int64
+	TaskID    int64
+	Time      time.Duration
+	// Number of segments
+	Segments int
+}
+
+// ProjectDayHours is the recorded time of a project on a day
+type ProjectDayHours struct {
+	ProjectID int64
+	Date      time.Time
+	Time      time.Duration
+}
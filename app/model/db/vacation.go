@@ -1,9 +0,0 @@
-package db
-
-import "time"
-
-type Vacation struct {
-	ID        int64
-	StartDate time.Time
-	EndDate   time.Time
-}
@@ -5,7 +5,15 @@ import "time"
 type Workday struct {
 	ID        int64
 	Date      time.Time
-	Time      string
-	Breaktime string
-	Overtime  string
+	Time      time.Duration
+	Breaktime time.Duration
+	Overtime  time.Duration
+}
+
+// WorkdayTotals holds aggregated values of several workdays
+type WorkdayTotals struct {
+	Days      int
+	Time      time.Duration
+	Breaktime time.Duration
+	Overtime  time.Duration
 }
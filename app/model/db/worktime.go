@@ -7,4 +7,13 @@ type Worktime struct {
 	Type    string
 	Time    time.Time
 	Workday Workday
+
+	// The segment from a Begin to the next End is attributed to the project and
+	// task of the Begin, the ids are 0 if the segment is not attributed
+	ProjectID int64
+	TaskID    int64
+	Note      string
+
+	// Added or changed by hand after it was recorded
+	Corrected bool
 }
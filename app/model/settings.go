@@ -1,11 +1,23 @@
 package model
 
+import "time"
+
 type Settings struct {
 	SavedPath   string `json:"saved_path"`
 	SavedDbPath string `json:"saved_db_path"`
+	// Name printed on the timesheet
+	EmployeeName string `json:"employee_name"`
+
 	// UI specific configuration
 	RefreshTimeUi int `json:"refresh_time_ui"`
 	ThemeVariant  int `json:"theme_variant"`
+	// Clock in when the app starts and out when it is closed
+	AutoClock bool `json:"auto_clock"`
+	// Start the app on login
+	Autostart bool `json:"autostart"`
+	// Propose idle periods longer than the idle minutes as breaks
+	IdleDetection bool `json:"idle_detection"`
+	IdleMinutes   int  `json:"idle_minutes"`
 
 	// Business logic specific configuration
 	FirstDayOfWeek Weekday `json:"first_day_of_week"`
@@ -13,6 +25,35 @@ type Settings struct {
 	// Necessary for overtime working
 	WeekHours       int `json:"week_hours"`
 	MaxVacationDays int `json:"max_vacation_days"`
+	// Day and month (DD.MM) until unused vacation days of the previous
+	// year can be taken, empty if vacation days are not carried over
+	CarryOverCutoff string `json:"carry_over_cutoff"`
+
+	// Hours which should be worked on each weekday
+	TargetHours map[Weekday]float64 `json:"target_hours"`
+
+	// Project and task of new segments, 0 if none
+	CurrentProject int64 `json:"current_project"`
+	CurrentTask    int64 `json:"current_task"`
+
+	// Region of the built-in public holidays, e.g. DE-BY
+	HolidayRegion string `json:"holiday_region"`
+
+	// Mandatory breaks depending on the worked time
+	BreakPolicy BreakPolicy `json:"break_policy"`
+	BreakRules  []BreakRule `json:"break_rules"`
+
+	// Desktop notifications while clocked in
+	NotifyTargetReached bool `json:"notify_target_reached"`
+	NotifyNoBreak       bool `json:"notify_no_break"`
+	NotifyLongDay       bool `json:"notify_long_day"`
+	NotifyClockOut      bool `json:"notify_clock_out"`
+	// Sent when a project has used 80% and 100% of its budget
+	NotifyBudget bool `json:"notify_budget"`
+	// Worked hours from which the long day notification is sent
+	LongDayHours float64 `json:"long_day_hours"`
+	// Hour of the day from which the clock out reminder is sent
+	ClockOutHour int `json:"clock_out_hour"`
 
 	// Import total overtime from previous systems in hours
 	ImportOvertime     float64 `json:"import_overtime"`
@@ -32,8 +73,57 @@ func NewSettings(savedPath string, savedDbPath string) *Settings {
 		// Business logic specific configuration
 		FirstDayOfWeek:     Monday,
 		MaxVacationDays:    30,
+		CarryOverCutoff:    "31.03",
 		WeekHours:          40,
+		TargetHours:        DefaultTargetHours(40),
+		BreakPolicy:        BreakPolicyGermany,
+		BreakRules:         BreakRulesForPolicy(BreakPolicyGermany),
 		ImportOvertime:     0,
 		LockImportOvertime: false,
+
+		NotifyTargetReached: true,
+		NotifyNoBreak:       true,
+		NotifyLongDay:       true,
+		NotifyClockOut:      true,
+		NotifyBudget:        true,
+		LongDayHours:        9.5,
+		ClockOutHour:        19,
+
+		IdleDetection: false,
+		IdleMinutes:   10,
+	}
+}
+
+// Spreads the week hours evenly over Monday to Friday
+func DefaultTargetHours(weekHours float64) map[Weekday]float64 {
+	targetHours := make(map[Weekday]float64, len(Weekdays))
+	for _, wd := range Weekdays {
+		targetHours[wd] = 0
+	}
+	for _, wd := range Weekdays[:5] {
+		targetHours[wd] = weekHours / float64(5)
+	}
+	return targetHours
+}
+
+// Returns the time which should be worked on the weekday of the given date
+func (s *Settings) TargetFor(date time.Time) time.Duration {
+	return HoursToDuration(s.TargetHours[WeekdayOf(date)])
+}
+
+// Converts hours to a duration rounded to seconds
+func HoursToDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour)).Round(time.Second)
+}
+
+// Returns the carry over cut-off date in the given year, false if there is none
+func (s *Settings) CarryOverCutoffIn(year int) (time.Time, bool) {
+	if s.CarryOverCutoff == "" {
+		return time.Time{}, false
+	}
+	cutoff, err := time.Parse(CARRYOVERFORMAT, s.CarryOverCutoff)
+	if err != nil {
+		return time.Time{}, false
 	}
+	return time.Date(year, cutoff.Month(), cutoff.Day(), 0, 0, 0, 0, time.UTC), true
 }
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
@@ -27,3 +28,12 @@ func New() *TimeEntry {
 		ENTRIES: []time.Time{},
 	}
 }
+
+// Formats a duration truncated to minutes, e.g. 45m or 1h15m
+func FormatDuration(d time.Duration) string {
+	s := d.Truncate(time.Minute).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
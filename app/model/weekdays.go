@@ -1,6 +1,10 @@
 package model
 
-import "fyne.io/fyne/v2/lang"
+import (
+	"time"
+
+	"fyne.io/fyne/v2/lang"
+)
 
 type Weekday string
 
@@ -14,6 +18,24 @@ const (
 	Sunday    Weekday = "Sunday"
 )
 
+// All weekdays in the order of a european week
+var Weekdays = []Weekday{Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday}
+
+// Returns the weekday of the given date
+func WeekdayOf(t time.Time) Weekday {
+	return Weekday(t.Weekday().String())
+}
+
+// Converts the weekday to the weekday of the time package
+func (d Weekday) TimeWeekday() time.Weekday {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if wd.String() == string(d) {
+			return wd
+		}
+	}
+	return time.Monday
+}
+
 func StringToWeekday(day string) Weekday {
 	switch day {
 	case lang.L("monday"):
@@ -0,0 +1,357 @@
+package repo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/charmbracelet/log"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Records the change in the audit log, the undone changes cannot be redone anymore
+func (r *SQLiteRepository) audit(tx *sql.Tx, action db.AuditAction, old db.AuditSnapshot, new db.AuditSnapshot, reason string) error {
+	oldJSON, err := json.Marshal(old)
+	if err != nil {
+		return err
+	}
+	newJSON, err := json.Marshal(new)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE audit_log SET state = ? WHERE state = ?`, db.AuditDropped, db.AuditUndone); err != nil {
+		log.Error(err)
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO audit_log(time, action, old, new, state, reason) VALUES(?, ?, ?, ?, ?, ?)`,
+		time.Now(), action, string(oldJSON), string(newJSON), db.AuditDone, reason)
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+/*
+Reverts the last change which is in effect and returns it. The log is kept
+in the database, so changes from before a restart can be undone as well.
+*/
+func (r *SQLiteRepository) Undo() (*db.AuditEntry, error) {
+	log.Info("Undo last change")
+	return r.replay(`SELECT `+auditColumns+` FROM audit_log
+		WHERE state = 'done' ORDER BY id DESC LIMIT 1`, ErrNothingToUndo, db.AuditUndone)
+}
+
+// Applies the last undone change again and returns it
+func (r *SQLiteRepository) Redo() (*db.AuditEntry, error) {
+	log.Info("Redo last undone change")
+	return r.replay(`SELECT `+auditColumns+` FROM audit_log
+		WHERE state = 'undone' ORDER BY id ASC LIMIT 1`, ErrNothingToRedo, db.AuditDone)
+}
+
+// Returns the recorded changes in the order they were made
+func (r *SQLiteRepository) GetAuditLog() ([]*db.AuditEntry, error) {
+	log.Debug("Getting audit log")
+	rows, err := r.db.Query(`SELECT ` + auditColumns + ` FROM audit_log ORDER BY id ASC`)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*db.AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+/*
+Removes the changes made before the given time from the undo history. The
+changes for which keep returns true stay in the log but cannot be undone or
+redone anymore, the others are deleted. Returns the number of deleted changes.
+*/
+func (r *SQLiteRepository) PruneAuditLog(before time.Time, keep func(*db.AuditEntry) bool) (int64, error) {
+	log.Info("Pruning audit log", "before", before)
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+auditColumns+` FROM audit_log
+		WHERE time < ? AND state != ?`, before, db.AuditArchived)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	var entries []*db.AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			rows.Close()
+			log.Error(err)
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, e := range entries {
+		var err error
+		switch {
+		case !keep(e):
+			_, err = tx.Exec(`DELETE FROM audit_log WHERE id = ?`, e.ID)
+			deleted++
+		case e.State == db.AuditDone:
+			_, err = tx.Exec(`UPDATE audit_log SET state = ? WHERE id = ?`, db.AuditArchived, e.ID)
+		case e.State == db.AuditUndone:
+			_, err = tx.Exec(`UPDATE audit_log SET state = ? WHERE id = ?`, db.AuditDropped, e.ID)
+		}
+		if err != nil {
+			log.Error(err)
+			return 0, err
+		}
+	}
+	return deleted, tx.Commit()
+}
+
+func (r *SQLiteRepository) replay(query string, errNone error, state db.AuditState) (*db.AuditEntry, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := scanAuditEntry(tx.QueryRow(query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNone
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	from, to := entry.New, entry.Old
+	if state == db.AuditDone {
+		from, to = entry.Old, entry.New
+	}
+	if err := restore(tx, from, to); err != nil {
+		log.Error("Could not replay change", "audit-id", entry.ID, "error", err)
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE audit_log SET state = ? WHERE id = ?`, state, entry.ID); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	entry.State = state
+	r.notifySnapshots(entry.Old, entry.New)
+	return entry, nil
+}
+
+/*
+Turns the rows of the from snapshot into the rows of the to snapshot. Rows
+only in from are deleted, the rows of to are inserted with their ids again
+or updated.
+*/
+func restore(tx *sql.Tx, from db.AuditSnapshot, to db.AuditSnapshot) error {
+	keep := make(map[int64]bool)
+	for _, wt := range to.Worktimes {
+		keep[wt.ID] = true
+	}
+	for _, wt := range from.Worktimes {
+		if keep[wt.ID] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM worktime WHERE id = ?`, wt.ID); err != nil {
+			return err
+		}
+	}
+
+	keep = make(map[int64]bool)
+	for _, a := range to.Absences {
+		keep[a.ID] = true
+	}
+	for _, a := range from.Absences {
+		if keep[a.ID] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM absence WHERE id = ?`, a.ID); err != nil {
+			return err
+		}
+	}
+
+	keep = make(map[int64]bool)
+	for _, wd := range to.Workdays {
+		keep[wd.ID] = true
+	}
+	for _, wd := range from.Workdays {
+		if keep[wd.ID] {
+			continue
+		}
+		// Entries recorded later without undo keep their day
+		_, err := tx.Exec(`DELETE FROM workday WHERE id = ?
+			AND NOT EXISTS (SELECT 1 FROM worktime WHERE worktime.workday = workday.id)`, wd.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, wd := range to.Workdays {
+		date := wd.Date.Format(time.DateOnly)
+		// A day added meanwhile without entries gives way to the restored one
+		_, err := tx.Exec(`DELETE FROM workday WHERE date = ? AND id != ?
+			AND NOT EXISTS (SELECT 1 FROM worktime WHERE worktime.workday = workday.id)`, date, wd.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO workday(id, date) VALUES(?, ?)
+			ON CONFLICT(id) DO UPDATE SET date = excluded.date`, wd.ID, date)
+		if err != nil {
+			return err
+		}
+	}
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	for _, wt := range to.Worktimes {
+		// Deleted projects and tasks are not restored, the segment stays unattributed
+		_, err := tx.Exec(`INSERT INTO worktime(id, type, workday, time, project, task, note, corrected)
+			VALUES(?, ?, ?, ?, (SELECT id FROM project WHERE id = ?), (SELECT id FROM task WHERE id = ?), ?, ?)
+			ON CONFLICT(id) DO UPDATE SET type = excluded.type, workday = excluded.workday,
+				time = excluded.time, project = excluded.project, task = excluded.task, note = excluded.note,
+				corrected = excluded.corrected`,
+			wt.ID, wt.Type, wt.Workday.ID, wt.Time.In(loc), wt.ProjectID, wt.TaskID, wt.Note, wt.Corrected)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range to.Absences {
+		_, err := tx.Exec(`INSERT INTO absence(id, type, startdate, enddate, halfdaystart, halfdayend)
+			VALUES(?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET type = excluded.type, startdate = excluded.startdate,
+				enddate = excluded.enddate, halfdaystart = excluded.halfdaystart, halfdayend = excluded.halfdayend`,
+			a.ID, a.Type, a.StartDate.In(loc), a.EndDate.In(loc), a.HalfDayStart, a.HalfDayEnd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notifies the listeners about the rows of the snapshots
+func (r *SQLiteRepository) notifySnapshots(snapshots ...db.AuditSnapshot) {
+	workdays := make(map[int64]bool)
+	absences := false
+	for _, s := range snapshots {
+		for _, wd := range s.Workdays {
+			workdays[wd.ID] = true
+		}
+		for _, wt := range s.Worktimes {
+			workdays[wt.Workday.ID] = true
+		}
+		absences = absences || len(s.Absences) > 0
+	}
+	for id := range workdays {
+		r.notify(Change{Kind: ChangeWorktime, WorkdayID: id})
+	}
+	if absences {
+		r.notify(Change{Kind: ChangeAbsence})
+	}
+}
+
+const auditColumns = `id, time, action, old, new, state, reason`
+
+func scanAuditEntry(row interface{ Scan(dest ...any) error }) (*db.AuditEntry, error) {
+	var e db.AuditEntry
+	var old, new string
+	if err := row.Scan(&e.ID, &e.Time, &e.Action, &old, &new, &e.State, &e.Reason); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(old), &e.Old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(new), &e.New); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Returns the worktime with the given id as it is stored
+func worktimeByID(tx *sql.Tx, id int64) (*db.Worktime, error) {
+	var w db.Worktime
+	var project, task sql.NullInt64
+	err := tx.QueryRow(`SELECT id, type, time, workday, project, task, note, corrected FROM worktime WHERE id = ?`, id).
+		Scan(&w.ID, &w.Type, &w.Time, &w.Workday.ID, &project, &task, &w.Note, &w.Corrected)
+	if err != nil {
+		return nil, err
+	}
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	w.Time = w.Time.In(loc)
+	w.ProjectID, w.TaskID = project.Int64, task.Int64
+	return &w, nil
+}
+
+// Returns the worktimes of the workday as they are stored
+func worktimesOf(tx *sql.Tx, workdayID int64) ([]*db.Worktime, error) {
+	rows, err := tx.Query(`SELECT id FROM worktime WHERE workday = ? ORDER BY time`, workdayID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	worktimes := make([]*db.Worktime, 0, len(ids))
+	for _, id := range ids {
+		wt, err := worktimeByID(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		worktimes = append(worktimes, wt)
+	}
+	return worktimes, nil
+}
+
+// Returns the absence with the given id as it is stored
+func absenceByID(tx *sql.Tx, id int64) (*db.Absence, error) {
+	var a db.Absence
+	err := tx.QueryRow(`SELECT id, type, startdate, enddate, halfdaystart, halfdayend FROM absence WHERE id = ?`, id).
+		Scan(&a.ID, &a.Type, &a.StartDate, &a.EndDate, &a.HalfDayStart, &a.HalfDayEnd)
+	if err != nil {
+		return nil, err
+	}
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	a.StartDate, a.EndDate = a.StartDate.In(loc), a.EndDate.In(loc)
+	return &a, nil
+}
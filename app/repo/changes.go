@@ -0,0 +1,44 @@
+package repo
+
+type ChangeKind string
+
+const (
+	// Time entries of a workday or the workday itself
+	ChangeWorktime ChangeKind = "worktime"
+	ChangeAbsence  ChangeKind = "absence"
+	ChangeContract ChangeKind = "contract"
+	ChangeHoliday  ChangeKind = "holiday"
+	// Projects and their tasks
+	ChangeProject ChangeKind = "project"
+)
+
+/*
+Change describes data which was modified. Calculated figures like the
+breaktime or the overtime of a workday are no changes, they are derived
+from the changed data.
+*/
+type Change struct {
+	Kind ChangeKind
+	// Affected workday of a worktime change
+	WorkdayID int64
+}
+
+/*
+Registers a listener which is called after data was changed. The listener
+is called synchronously by the changing goroutine, so it must not block.
+*/
+func (r *SQLiteRepository) OnChange(listener func(Change)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+func (r *SQLiteRepository) notify(c Change) {
+	r.mu.Lock()
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	for _, l := range listeners {
+		l(c)
+	}
+}
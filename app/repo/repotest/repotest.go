@@ -0,0 +1,31 @@
+// Package repotest provides a migrated repository for tests
+package repotest
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/FyningTime/FyningTime/app/repo"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Returns a migrated repository on a database which is removed after the test
+func NewRepository(t testing.TB) *repo.SQLiteRepository {
+	t.Helper()
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if _, err := database.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := repo.NewSQLiteRepository(database)
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/FyningTime/FyningTime/app/model/db"
@@ -16,10 +17,16 @@ var (
 	ErrNotExists    = errors.New("row not exists")
 	ErrUpdateFailed = errors.New("update failed")
 	ErrDeleteFailed = errors.New("delete failed")
+	ErrInvalidRange = errors.New("end date is before start date")
+	ErrOverlap      = errors.New("overlaps with an existing record")
 )
 
 type SQLiteRepository struct {
 	db *sql.DB
+
+	// Called after data was changed, see OnChange
+	mu        sync.Mutex
+	listeners []func(Change)
 }
 
 type SORTING string
@@ -60,6 +67,15 @@ func (r *SQLiteRepository) Migrate() error {
 	}{
 		{1, r.migrationV1},
 		{2, r.migrationV2},
+		{3, r.migrationV3},
+		{4, r.migrationV4},
+		{5, r.migrationV5},
+		{6, r.migrationV6},
+		{7, r.migrationV7},
+		{8, r.migrationV8},
+		{9, r.migrationV9},
+		{10, r.migrationV10},
+		{11, r.migrationV11},
 	}
 
 	for _, migration := range migrations {
@@ -152,56 +168,482 @@ func (r *SQLiteRepository) migrationV2() error {
 	return err
 }
 
+/*
+Converts the durations of the workday table from Go duration strings
+like "7h30m0s" to integer seconds, so they can be summed up in SQL.
+*/
+func (r *SQLiteRepository) migrationV3() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, time, breaktime, overtime FROM workday`)
+	if err != nil {
+		return err
+	}
+
+	type durations struct {
+		id                        int64
+		time, breaktime, overtime time.Duration
+	}
+	var converted []durations
+	for rows.Next() {
+		var id int64
+		var wt, bt, ot sql.NullString
+		if err := rows.Scan(&id, &wt, &bt, &ot); err != nil {
+			rows.Close()
+			return err
+		}
+		converted = append(converted, durations{
+			id:        id,
+			time:      parseLegacyDuration(wt),
+			breaktime: parseLegacyDuration(bt),
+			overtime:  parseLegacyDuration(ot),
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE workday ADD COLUMN time_seconds INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE workday ADD COLUMN breaktime_seconds INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE workday ADD COLUMN overtime_seconds INTEGER NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`UPDATE workday
+		SET time_seconds = ?, breaktime_seconds = ?, overtime_seconds = ?
+		WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range converted {
+		_, err := stmt.Exec(toSeconds(c.time), toSeconds(c.breaktime), toSeconds(c.overtime), c.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		ALTER TABLE workday DROP COLUMN time;
+		ALTER TABLE workday DROP COLUMN breaktime;
+		ALTER TABLE workday DROP COLUMN overtime;
+		ALTER TABLE workday RENAME COLUMN time_seconds TO time;
+		ALTER TABLE workday RENAME COLUMN breaktime_seconds TO breaktime;
+		ALTER TABLE workday RENAME COLUMN overtime_seconds TO overtime;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) migrationV4() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS contract(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		validfrom DATETIME NOT NULL UNIQUE,
+		weekhours REAL NOT NULL DEFAULT 40,
+		sunday INTEGER NOT NULL DEFAULT 0,
+		monday INTEGER NOT NULL DEFAULT 0,
+		tuesday INTEGER NOT NULL DEFAULT 0,
+		wednesday INTEGER NOT NULL DEFAULT 0,
+		thursday INTEGER NOT NULL DEFAULT 0,
+		friday INTEGER NOT NULL DEFAULT 0,
+		saturday INTEGER NOT NULL DEFAULT 0,
+		vacationdays INTEGER NOT NULL DEFAULT 30
+	);
+	`
+	_, err := r.db.Exec(query)
+	return err
+}
+
+func (r *SQLiteRepository) migrationV5() error {
+	_, err := r.db.Exec(`
+		ALTER TABLE vacations ADD COLUMN halfdaystart INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE vacations ADD COLUMN halfdayend INTEGER NOT NULL DEFAULT 0;
+	`)
+	return err
+}
+
+func (r *SQLiteRepository) migrationV6() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS holiday(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date DATETIME NOT NULL UNIQUE,
+		name TEXT NOT NULL DEFAULT ''
+	);
+	`
+	_, err := r.db.Exec(query)
+	return err
+}
+
+// Replaces the vacations with typed absences, the start and end date are no longer unique
+func (r *SQLiteRepository) migrationV7() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	CREATE TABLE IF NOT EXISTS absence(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL DEFAULT 'vacation',
+		startdate DATETIME NOT NULL,
+		enddate DATETIME NOT NULL,
+		halfdaystart INTEGER NOT NULL DEFAULT 0,
+		halfdayend INTEGER NOT NULL DEFAULT 0
+	);
+	INSERT INTO absence(id, type, startdate, enddate, halfdaystart, halfdayend)
+		SELECT id, 'vacation', startdate, enddate, halfdaystart, halfdayend FROM vacations;
+	DROP TABLE vacations;
+	`)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Adds projects and tasks, the Begin of a segment carries its project, task and note
+func (r *SQLiteRepository) migrationV8() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	CREATE TABLE IF NOT EXISTS project(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		code TEXT NOT NULL DEFAULT '',
+		color TEXT NOT NULL DEFAULT '',
+		active INTEGER NOT NULL DEFAULT 1,
+		budget INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS task(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project INTEGER NOT NULL REFERENCES project(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		code TEXT NOT NULL DEFAULT '',
+		color TEXT NOT NULL DEFAULT '',
+		active INTEGER NOT NULL DEFAULT 1,
+		budget INTEGER NOT NULL DEFAULT 0
+	);
+	ALTER TABLE worktime ADD COLUMN project INTEGER REFERENCES project(id) ON DELETE SET NULL;
+	ALTER TABLE worktime ADD COLUMN task INTEGER REFERENCES task(id) ON DELETE SET NULL;
+	ALTER TABLE worktime ADD COLUMN note TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Remembers the budget warning that was sent last per project
+func (r *SQLiteRepository) migrationV9() error {
+	_, err := r.db.Exec(`ALTER TABLE project ADD COLUMN budget_warned INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
+// Adds the audit log which the changes are undone and redone with
+func (r *SQLiteRepository) migrationV10() error {
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS audit_log(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		time DATETIME NOT NULL,
+		action TEXT NOT NULL,
+		old TEXT NOT NULL DEFAULT '{}',
+		new TEXT NOT NULL DEFAULT '{}',
+		state TEXT NOT NULL DEFAULT 'done'
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_state ON audit_log(state);
+	`)
+	return err
+}
+
+// Adds the reason of a change and flags the worktimes which were corrected by hand
+func (r *SQLiteRepository) migrationV11() error {
+	_, err := r.db.Exec(`
+	ALTER TABLE audit_log ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+	ALTER TABLE worktime ADD COLUMN corrected INTEGER NOT NULL DEFAULT 0;
+	`)
+	return err
+}
+
+// Parses the old string representation, invalid or empty values become 0
+func parseLegacyDuration(s sql.NullString) time.Duration {
+	if !s.Valid || s.String == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(s.String)
+	if err != nil {
+		log.Warn("Could not convert duration, using 0", "value", s.String, "error", err)
+		return 0
+	}
+	return d
+}
+
+// Ids of optional references are stored as NULL if they are 0
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// Durations are stored as integer seconds in the database
+func toSeconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+func fromSeconds(s int64) time.Duration {
+	return time.Duration(s) * time.Second
+}
+
 func (r *SQLiteRepository) AddWorkday(workday *db.Workday) (*db.Workday, error) {
 	log.Info("Adding workday", "date", workday.Date)
-	query := `INSERT INTO workday(date) VALUES(?)`
-	res, err := r.db.Exec(query, workday.Date.Format(time.DateOnly))
+	tx, err := r.db.Begin()
 	if err != nil {
+		log.Error(err)
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	id, err := res.LastInsertId()
+	res, err := tx.Exec(`INSERT INTO workday(date) VALUES(?)`, workday.Date.Format(time.DateOnly))
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
-
-	workday.ID = id
+	if workday.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	if err := r.audit(tx, db.AuditAddWorkday, db.AuditSnapshot{}, db.AuditSnapshot{Workdays: []*db.Workday{workday}}, ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.notify(Change{Kind: ChangeWorktime, WorkdayID: workday.ID})
 
 	return workday, nil
 }
 
+/*
+Adds the workdays with their worktimes and the absences in a single
+transaction, the worktimes of a workday have the same index as the workday.
+Nothing is added if one of the workdays already exists or one of the
+absences overlaps another. The import is undone at once.
+*/
+func (r *SQLiteRepository) AddWorkdaysWithWorktimes(workdays []*db.Workday, worktimes [][]*db.Worktime, absences []*db.Absence) error {
+	log.Info("Adding workdays with worktimes", "size", len(workdays), "absences", len(absences))
+	if len(workdays) != len(worktimes) {
+		return fmt.Errorf("got %d workdays but worktimes for %d", len(workdays), len(worktimes))
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	for i, a := range absences {
+		a.StartDate = a.StartDate.In(loc)
+		a.EndDate = a.EndDate.In(loc)
+		if err := validateAbsence(tx, a); err != nil {
+			log.Error(err)
+			return err
+		}
+		for _, other := range absences[:i] {
+			if a.Overlaps(other) {
+				err := fmt.Errorf("%w: %s - %s", ErrOverlap,
+					other.StartDate.Format(time.DateOnly), other.EndDate.Format(time.DateOnly))
+				log.Error(err)
+				return err
+			}
+		}
+	}
+
+	var added []*db.Worktime
+	for i, wd := range workdays {
+		res, err := tx.Exec(`INSERT INTO workday(date) VALUES(?)`, wd.Date.Format(time.DateOnly))
+		if err != nil {
+			log.Error(err)
+			return fmt.Errorf("%s: %w", wd.Date.Format(time.DateOnly), err)
+		}
+		if wd.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+
+		for _, wt := range worktimes[i] {
+			wt.Workday = *wd
+			res, err := tx.Exec(`INSERT INTO worktime(type, workday, time, project, task, note) VALUES(?, ?, ?, ?, ?, ?)`,
+				wt.Type, wd.ID, wt.Time, nullID(wt.ProjectID), nullID(wt.TaskID), wt.Note)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			if wt.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+			added = append(added, wt)
+		}
+	}
+	for _, a := range absences {
+		res, err := tx.Exec(`INSERT INTO absence(type, startdate, enddate, halfdaystart, halfdayend) VALUES(?, ?, ?, ?, ?)`,
+			a.Type, a.StartDate, a.EndDate, a.HalfDayStart, a.HalfDayEnd)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		if a.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	imported := db.AuditSnapshot{Workdays: workdays, Worktimes: added, Absences: absences}
+	if err := r.audit(tx, db.AuditImport, db.AuditSnapshot{}, imported, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, wd := range workdays {
+		r.notify(Change{Kind: ChangeWorktime, WorkdayID: wd.ID})
+	}
+	if len(absences) > 0 {
+		r.notify(Change{Kind: ChangeAbsence})
+	}
+	return nil
+}
+
+// Adds a worktime as a command of the user which can be undone
 func (r *SQLiteRepository) AddWorktime(worktime *db.Worktime) (*db.Worktime, error) {
 	log.Info("Adding worktime", "type", worktime.Type, "time", worktime.Time)
-	query := `INSERT INTO worktime(type, workday, time) VALUES(?, ?, ?)`
-	res, err := r.db.Exec(query, worktime.Type, worktime.Workday.ID, worktime.Time)
-	if err != nil {
-		log.Fatal(err)
+	if err := r.addWorktimes([]*db.Worktime{worktime}, true, ""); err != nil {
 		return nil, err
 	}
+	return worktime, nil
+}
 
-	id, err := res.LastInsertId()
-	if err != nil {
+// Adds a worktime which was entered by hand, it is flagged as corrected
+func (r *SQLiteRepository) AddCorrectedWorktime(worktime *db.Worktime, reason string) (*db.Worktime, error) {
+	log.Info("Adding corrected worktime", "type", worktime.Type, "time", worktime.Time)
+	worktime.Corrected = true
+	if err := r.addWorktimes([]*db.Worktime{worktime}, true, reason); err != nil {
 		return nil, err
 	}
-
-	worktime.ID = id
 	return worktime, nil
 }
 
+/*
+Adds the worktimes in a single transaction as one command of the user,
+e.g. the End and Begin around a break
+*/
+func (r *SQLiteRepository) AddWorktimes(worktimes []*db.Worktime) error {
+	log.Info("Adding worktimes", "size", len(worktimes))
+	return r.addWorktimes(worktimes, true, "")
+}
+
+/*
+Adds worktimes which were recorded automatically in a single transaction,
+e.g. by the session or a project switch. They are not part of the undo
+history, so undo only reverts commands of the user.
+*/
+func (r *SQLiteRepository) RecordWorktimes(worktimes []*db.Worktime) error {
+	log.Info("Recording worktimes", "size", len(worktimes))
+	return r.addWorktimes(worktimes, false, "")
+}
+
+/*
+Adds the worktimes in one transaction. A worktime whose workday has no id
+yet creates the workday of its date, so undoing the first entry of a day
+removes the day as well.
+*/
+func (r *SQLiteRepository) addWorktimes(worktimes []*db.Worktime, undoable bool, reason string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	created := make(map[string]*db.Workday)
+	var workdays []*db.Workday
+	for _, wt := range worktimes {
+		if wt.Workday.ID != 0 {
+			continue
+		}
+		day := wt.Time.In(loc).Format(time.DateOnly)
+		wd, ok := created[day]
+		if !ok {
+			res, err := tx.Exec(`INSERT INTO workday(date) VALUES(?)`, day)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			t := wt.Time.In(loc)
+			wd = &db.Workday{Date: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)}
+			if wd.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+			created[day] = wd
+			workdays = append(workdays, wd)
+		}
+		wt.Workday = *wd
+	}
+
+	for _, wt := range worktimes {
+		res, err := tx.Exec(`INSERT INTO worktime(type, workday, time, project, task, note, corrected) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+			wt.Type, wt.Workday.ID, wt.Time, nullID(wt.ProjectID), nullID(wt.TaskID), wt.Note, wt.Corrected)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		if wt.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	if undoable {
+		err := r.audit(tx, db.AuditAddWorktime, db.AuditSnapshot{}, db.AuditSnapshot{Workdays: workdays, Worktimes: worktimes}, reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, wt := range worktimes {
+		r.notify(Change{Kind: ChangeWorktime, WorkdayID: wt.Workday.ID})
+	}
+	return nil
+}
+
 func (r *SQLiteRepository) GetWorkday(date time.Time) (*db.Workday, error) {
 	log.Info("Getting workday", "date", date)
 	query := `SELECT id, date, time, breaktime, overtime
 	from workday WHERE date = ? ORDER BY date DESC LIMIT 1`
 
 	var w db.Workday
+	var wt, bt, ot int64
 	loc, _ := time.LoadLocation("Europe/Berlin")
 	tmpDate := w.Date.In(loc)
 	qd := date.In(loc).Format(time.DateOnly)
 	log.Debug("Query date", "date", qd)
 	err := r.db.QueryRow(query, qd).
-		Scan(&w.ID, &tmpDate, &w.Time, &w.Breaktime, &w.Overtime)
+		Scan(&w.ID, &tmpDate, &wt, &bt, &ot)
 	w.Date = tmpDate
+	w.Time, w.Breaktime, w.Overtime = fromSeconds(wt), fromSeconds(bt), fromSeconds(ot)
 
 	if err != nil {
 		log.Error(err)
@@ -211,6 +653,22 @@ func (r *SQLiteRepository) GetWorkday(date time.Time) (*db.Workday, error) {
 	return &w, nil
 }
 
+func (r *SQLiteRepository) GetWorkdayByID(id int64) (*db.Workday, error) {
+	log.Debug("Getting workday", "id", id)
+	query := `SELECT id, date, time, breaktime, overtime FROM workday WHERE id = ?`
+
+	var w db.Workday
+	var wt, bt, ot int64
+	err := r.db.QueryRow(query, id).Scan(&w.ID, &w.Date, &wt, &bt, &ot)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	w.Time, w.Breaktime, w.Overtime = fromSeconds(wt), fromSeconds(bt), fromSeconds(ot)
+
+	return &w, nil
+}
+
 func (r *SQLiteRepository) GetAllWorkday(sorting SORTING) ([]*db.Workday, error) {
 	log.Debug("Getting all workdays")
 	var order string
@@ -232,11 +690,13 @@ func (r *SQLiteRepository) GetAllWorkday(sorting SORTING) ([]*db.Workday, error)
 	var workdays []*db.Workday
 	for rows.Next() {
 		var w db.Workday
-		err := rows.Scan(&w.ID, &w.Date, &w.Time, &w.Breaktime, &w.Overtime)
+		var wt, bt, ot int64
+		err := rows.Scan(&w.ID, &w.Date, &wt, &bt, &ot)
 		if err != nil {
 			log.Error(err)
 			return nil, err
 		}
+		w.Time, w.Breaktime, w.Overtime = fromSeconds(wt), fromSeconds(bt), fromSeconds(ot)
 
 		log.Debug("Workday", "id", w.ID, "date", w.Date)
 		workdays = append(workdays, &w)
@@ -245,9 +705,30 @@ func (r *SQLiteRepository) GetAllWorkday(sorting SORTING) ([]*db.Workday, error)
 	return workdays, nil
 }
 
+/*
+Sums up the durations of all workdays between from and to (both inclusive)
+*/
+func (r *SQLiteRepository) GetWorkdayTotals(from time.Time, to time.Time) (*db.WorkdayTotals, error) {
+	log.Debug("Getting workday totals", "from", from, "to", to)
+	query := `SELECT COUNT(*), COALESCE(SUM(time), 0), COALESCE(SUM(breaktime), 0), COALESCE(SUM(overtime), 0)
+	FROM workday WHERE date BETWEEN ? AND ?`
+
+	var totals db.WorkdayTotals
+	var wt, bt, ot int64
+	err := r.db.QueryRow(query, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Scan(&totals.Days, &wt, &bt, &ot)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	totals.Time, totals.Breaktime, totals.Overtime = fromSeconds(wt), fromSeconds(bt), fromSeconds(ot)
+
+	return &totals, nil
+}
+
 func (r *SQLiteRepository) GetAllWorktime(workday *db.Workday) ([]*db.Worktime, error) {
 	log.Info("Getting all worktimes", "workday-id", workday.ID)
-	query := `SELECT id, type, time, workday FROM worktime WHERE workday = ?`
+	query := `SELECT id, type, time, workday, project, task, note, corrected FROM worktime WHERE workday = ?`
 
 	loc, _ := time.LoadLocation("Europe/Berlin")
 
@@ -261,8 +742,10 @@ func (r *SQLiteRepository) GetAllWorktime(workday *db.Workday) ([]*db.Worktime,
 	for rows.Next() {
 		var w db.Worktime
 		tmpTime := w.Time.In(loc)
-		err := rows.Scan(&w.ID, &w.Type, &tmpTime, &w.Workday.ID)
+		var project, task sql.NullInt64
+		err := rows.Scan(&w.ID, &w.Type, &tmpTime, &w.Workday.ID, &project, &task, &w.Note, &w.Corrected)
 		w.Time = tmpTime
+		w.ProjectID, w.TaskID = project.Int64, task.Int64
 		if err != nil {
 			log.Error(err)
 			return nil, err
@@ -273,51 +756,257 @@ func (r *SQLiteRepository) GetAllWorktime(workday *db.Workday) ([]*db.Worktime,
 	return worktimes, nil
 }
 
-func (r *SQLiteRepository) DeleteWorktime(worktime *db.Worktime) (int64, error) {
-	log.Info("Deleting worktime", "worktime-id", worktime.ID)
-	query := `DELETE FROM worktime WHERE id = ?`
+// Returns the worktimes of all workdays with a single query
+func (r *SQLiteRepository) GetAllWorktimes() ([]*db.Worktime, error) {
+	log.Info("Getting the worktimes of all workdays")
+	query := `SELECT id, type, time, workday, project, task, note, corrected FROM worktime ORDER BY id`
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
 
-	res, err := r.db.Exec(query, worktime.ID)
+	rows, err := r.db.Query(query)
 	if err != nil {
 		log.Error(err)
-		return 0, err
+		return nil, err
 	}
-	return res.RowsAffected()
-}
+	defer rows.Close()
 
-func (r *SQLiteRepository) DeleteWorkday(workday *db.Workday) (int64, error) {
-	log.Info("Deleting workday", "workday-id", workday.ID)
-	queryDeleteWorktimes := `DELETE FROM worktime WHERE workday = ?`
-	resWt, errWt := r.db.Exec(queryDeleteWorktimes, workday.ID)
-	if errWt == nil {
-		query := `DELETE FROM workday WHERE id = ?`
-		resWd, _ := r.db.Exec(query, workday.ID)
-		return resWd.RowsAffected()
-	} else {
-		return resWt.RowsAffected()
+	var worktimes []*db.Worktime
+	for rows.Next() {
+		var w db.Worktime
+		var project, task sql.NullInt64
+		if err := rows.Scan(&w.ID, &w.Type, &w.Time, &w.Workday.ID, &project, &task, &w.Note, &w.Corrected); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		w.Time = w.Time.In(loc)
+		w.ProjectID, w.TaskID = project.Int64, task.Int64
+		worktimes = append(worktimes, &w)
 	}
-
+	return worktimes, rows.Err()
 }
 
-func (r *SQLiteRepository) UpdateOvertimesBatch(workdays []*db.Workday) error {
-	log.Info("Updating overtimes batch", "workdays-size", len(workdays))
+// Deletes the worktime, the reason is kept in the audit log
+func (r *SQLiteRepository) DeleteWorktime(worktime *db.Worktime, reason string) (int64, error) {
+	log.Info("Deleting worktime", "worktime-id", worktime.ID)
 	tx, err := r.db.Begin()
 	if err != nil {
 		log.Error(err)
-		return err
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`UPDATE workday SET overtime = ? WHERE id = ?`)
-	if err != nil {
+	old, err := worktimeByID(tx, worktime.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
 		log.Error(err)
-		return err
+		return 0, err
 	}
-	defer stmt.Close()
 
-	for _, wd := range workdays {
-		_, err := stmt.Exec(wd.Overtime, wd.ID)
-		if err != nil {
-			log.Error(err)
+	res, err := tx.Exec(`DELETE FROM worktime WHERE id = ?`, worktime.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	if err := r.audit(tx, db.AuditDeleteWorktime, db.AuditSnapshot{Worktimes: []*db.Worktime{old}}, db.AuditSnapshot{}, reason); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeWorktime, WorkdayID: old.Workday.ID})
+	return res.RowsAffected()
+}
+
+// Deletes the workday with its worktimes, the reason is kept in the audit log
+func (r *SQLiteRepository) DeleteWorkday(workday *db.Workday, reason string) (int64, error) {
+	log.Info("Deleting workday", "workday-id", workday.ID)
+	old, err := r.GetWorkdayByID(workday.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	worktimes, err := r.GetAllWorktime(old)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM worktime WHERE workday = ?`, workday.ID); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM workday WHERE id = ?`, workday.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	snapshot := db.AuditSnapshot{Workdays: []*db.Workday{old}, Worktimes: worktimes}
+	if err := r.audit(tx, db.AuditDeleteWorkday, snapshot, db.AuditSnapshot{}, reason); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeWorktime, WorkdayID: workday.ID})
+	return res.RowsAffected()
+}
+
+/*
+Replaces the worktimes of the day in a single transaction. Worktimes without
+id are added, the others are updated and the missing ones deleted. The
+workday is created if needed and deleted when no worktime is left, added
+and changed worktimes are flagged as corrected. Returns the workday or nil
+if it does not exist anymore.
+*/
+func (r *SQLiteRepository) SaveWorkday(date time.Time, worktimes []*db.Worktime, reason string) (*db.Workday, error) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	date = date.In(loc)
+	day := date.Format(time.DateOnly)
+	log.Info("Saving workday", "date", day, "worktimes-size", len(worktimes))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	workday := &db.Workday{Date: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)}
+	created := false
+	err = tx.QueryRow(`SELECT id FROM workday WHERE date = ?`, day).Scan(&workday.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if len(worktimes) == 0 {
+			return nil, nil
+		}
+		res, err := tx.Exec(`INSERT INTO workday(date) VALUES(?)`, day)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		if workday.ID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		created = true
+	} else if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	old, err := worktimesOf(tx, workday.ID)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	oldByID := make(map[int64]*db.Worktime, len(old))
+	for _, wt := range old {
+		oldByID[wt.ID] = wt
+	}
+
+	changed := created
+	keep := make(map[int64]bool, len(worktimes))
+	for _, wt := range worktimes {
+		wt.Workday = *workday
+		wt.Time = wt.Time.In(loc)
+		if wt.ID == 0 {
+			wt.Corrected = true
+			res, err := tx.Exec(`INSERT INTO worktime(type, workday, time, project, task, note, corrected) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+				wt.Type, workday.ID, wt.Time, nullID(wt.ProjectID), nullID(wt.TaskID), wt.Note, wt.Corrected)
+			if err != nil {
+				log.Error(err)
+				return nil, err
+			}
+			if wt.ID, err = res.LastInsertId(); err != nil {
+				return nil, err
+			}
+			changed = true
+			continue
+		}
+
+		prev, ok := oldByID[wt.ID]
+		if !ok {
+			return nil, fmt.Errorf("worktime %d does not belong to %s", wt.ID, day)
+		}
+		keep[wt.ID] = true
+		timeChanged := prev.Type != wt.Type || !prev.Time.Equal(wt.Time)
+		wt.Corrected = prev.Corrected || timeChanged
+		if !timeChanged && prev.ProjectID == wt.ProjectID && prev.TaskID == wt.TaskID && prev.Note == wt.Note {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE worktime SET type = ?, time = ?, project = ?, task = ?, note = ?, corrected = ? WHERE id = ?`,
+			wt.Type, wt.Time, nullID(wt.ProjectID), nullID(wt.TaskID), wt.Note, wt.Corrected, wt.ID)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		changed = true
+	}
+
+	for _, wt := range old {
+		if keep[wt.ID] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM worktime WHERE id = ?`, wt.ID); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		changed = true
+	}
+	if !changed {
+		return workday, nil
+	}
+
+	oldSnapshot := db.AuditSnapshot{Worktimes: old}
+	if !created {
+		oldSnapshot.Workdays = []*db.Workday{workday}
+	}
+	newSnapshot := db.AuditSnapshot{Worktimes: worktimes}
+	if len(worktimes) > 0 {
+		newSnapshot.Workdays = []*db.Workday{workday}
+	} else if _, err := tx.Exec(`DELETE FROM workday WHERE id = ?`, workday.ID); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if err := r.audit(tx, db.AuditEditWorkday, oldSnapshot, newSnapshot, reason); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.notify(Change{Kind: ChangeWorktime, WorkdayID: workday.ID})
+	if len(worktimes) == 0 {
+		return nil, nil
+	}
+	return workday, nil
+}
+
+func (r *SQLiteRepository) UpdateOvertimesBatch(workdays []*db.Workday) error {
+	log.Info("Updating overtimes batch", "workdays-size", len(workdays))
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	stmt, err := tx.Prepare(`UPDATE workday SET overtime = ? WHERE id = ?`)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, wd := range workdays {
+		_, err := stmt.Exec(toSeconds(wd.Overtime), wd.ID)
+		if err != nil {
+			log.Error(err)
 			tx.Rollback()
 			return err
 		}
@@ -332,17 +1021,78 @@ func (r *SQLiteRepository) UpdateOvertimesBatch(workdays []*db.Workday) error {
 	return nil
 }
 
-func (r *SQLiteRepository) UpdateWorktime(worktime *db.Worktime) (int64, error) {
+// Stores the worked time and the breaktime of the workdays in one transaction
+func (r *SQLiteRepository) UpdateWorkdaysBatch(workdays []*db.Workday) error {
+	log.Info("Updating workdays batch", "workdays-size", len(workdays))
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE workday SET breaktime = ?, time = ? WHERE id = ?`)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, wd := range workdays {
+		if _, err := stmt.Exec(toSeconds(wd.Breaktime), toSeconds(wd.Time), wd.ID); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+/*
+Updates the worktime, the reason is kept in the audit log. A changed time
+or type flags the worktime as corrected.
+*/
+func (r *SQLiteRepository) UpdateWorktime(worktime *db.Worktime, reason string) (int64, error) {
 	log.Info("Updating worktime", "worktime-id", worktime.ID)
-	query := `UPDATE worktime SET type = ?, time = ? WHERE id = ?`
+	query := `UPDATE worktime SET type = ?, time = ?, project = ?, task = ?, note = ?, corrected = ? WHERE id = ?`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	old, err := worktimeByID(tx, worktime.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		log.Error(err)
+		return 0, err
+	}
 
 	loc, _ := time.LoadLocation("Europe/Berlin")
 	tmpTime := worktime.Time.In(loc)
-	res, err := r.db.Exec(query, worktime.Type, tmpTime, worktime.ID)
+	worktime.Corrected = old.Corrected || old.Type != worktime.Type || !old.Time.Equal(tmpTime)
+	res, err := tx.Exec(query, worktime.Type, tmpTime,
+		nullID(worktime.ProjectID), nullID(worktime.TaskID), worktime.Note, worktime.Corrected, worktime.ID)
 	if err != nil {
 		log.Error(err)
 		return 0, err
 	}
+
+	// The worktime keeps its workday
+	updated := *worktime
+	updated.Workday = old.Workday
+	err = r.audit(tx, db.AuditUpdateWorktime,
+		db.AuditSnapshot{Worktimes: []*db.Worktime{old}}, db.AuditSnapshot{Worktimes: []*db.Worktime{&updated}}, reason)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeWorktime, WorkdayID: old.Workday.ID})
 	return res.RowsAffected()
 }
 
@@ -353,8 +1103,8 @@ func (r *SQLiteRepository) UpdateWorkday(workday *db.Workday) (int64, error) {
 		WHERE id = ?`
 
 	res, err := r.db.Exec(query,
-		workday.Breaktime,
-		workday.Time,
+		toSeconds(workday.Breaktime),
+		toSeconds(workday.Time),
 		workday.ID)
 	if err != nil {
 		log.Error(err)
@@ -363,20 +1113,35 @@ func (r *SQLiteRepository) UpdateWorkday(workday *db.Workday) (int64, error) {
 	return res.RowsAffected()
 }
 
-func (r *SQLiteRepository) AddVacation(vacation *db.Vacation) (*db.Vacation, error) {
-	log.Info("Adding vacation", "start", vacation.StartDate, "end", vacation.EndDate)
-	query := `INSERT INTO vacations(startdate, enddate) VALUES(?, ?)`
+func (r *SQLiteRepository) AddAbsence(absence *db.Absence) (*db.Absence, error) {
+	log.Info("Adding absence", "type", absence.Type, "start", absence.StartDate, "end", absence.EndDate)
+	query := `INSERT INTO absence(type, startdate, enddate, halfdaystart, halfdayend) VALUES(?, ?, ?, ?, ?)`
 
 	loc, _ := time.LoadLocation("Europe/Berlin")
-	vacation.StartDate = vacation.StartDate.In(loc)
-	vacation.EndDate = vacation.EndDate.In(loc)
+	absence.StartDate = absence.StartDate.In(loc)
+	absence.EndDate = absence.EndDate.In(loc)
 
-	res, err := r.db.Exec(query,
-		vacation.StartDate,
-		vacation.EndDate,
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := validateAbsence(tx, absence); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	res, err := tx.Exec(query,
+		absence.Type,
+		absence.StartDate,
+		absence.EndDate,
+		absence.HalfDayStart,
+		absence.HalfDayEnd,
 	)
 	if err != nil {
-		log.Fatal(err)
+		log.Error(err)
 		return nil, err
 	}
 
@@ -385,15 +1150,22 @@ func (r *SQLiteRepository) AddVacation(vacation *db.Vacation) (*db.Vacation, err
 		return nil, err
 	}
 
-	vacation.ID = id
-	return vacation, nil
+	absence.ID = id
+	if err := r.audit(tx, db.AuditAddAbsence, db.AuditSnapshot{}, db.AuditSnapshot{Absences: []*db.Absence{absence}}, ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.notify(Change{Kind: ChangeAbsence})
+	return absence, nil
 }
 
-func (r *SQLiteRepository) GetAllVacation() ([]*db.Vacation, error) {
-	log.Info("Getting all vacations")
-	query := `SELECT ID, startdate, enddate FROM vacations ORDER BY startdate DESC`
+func (r *SQLiteRepository) GetAllAbsence() ([]*db.Absence, error) {
+	log.Info("Getting all absences")
+	query := `SELECT ID, type, startdate, enddate, halfdaystart, halfdayend FROM absence ORDER BY startdate DESC`
 
-	var v []*db.Vacation
+	var a []*db.Absence
 	rows, err := r.db.Query(query)
 	if err != nil {
 		log.Error(err)
@@ -402,63 +1174,569 @@ func (r *SQLiteRepository) GetAllVacation() ([]*db.Vacation, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var vacation db.Vacation
+		var absence db.Absence
 		loc, _ := time.LoadLocation("Europe/Berlin")
 
-		sd := vacation.StartDate.In(loc)
-		ed := vacation.EndDate.In(loc)
+		sd := absence.StartDate.In(loc)
+		ed := absence.EndDate.In(loc)
 
-		err := rows.Scan(&vacation.ID, &sd, &ed)
+		err := rows.Scan(&absence.ID, &absence.Type, &sd, &ed, &absence.HalfDayStart, &absence.HalfDayEnd)
 
 		if err != nil {
 			log.Error(err)
 			return nil, err
 		}
-		vacation.StartDate = sd
-		vacation.EndDate = ed
-		v = append(v, &vacation)
+		absence.StartDate = sd
+		absence.EndDate = ed
+		a = append(a, &absence)
 	}
-	log.Debug("Vacations", "size", len(v))
+	log.Debug("Absences", "size", len(a))
 
-	return v, nil
+	return a, nil
 }
 
-func (r *SQLiteRepository) DeleteVacation(vacation *db.Vacation) (int64, error) {
-	log.Info("Deleting vacation", "vacation-id", vacation.ID)
-	query := `DELETE FROM vacations WHERE id = ?`
+func (r *SQLiteRepository) DeleteAbsence(absence *db.Absence) (int64, error) {
+	log.Info("Deleting absence", "absence-id", absence.ID)
+	query := `DELETE FROM absence WHERE id = ?`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	old, err := absenceByID(tx, absence.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		log.Error(err)
+		return 0, err
+	}
 
-	res, err := r.db.Exec(query, vacation.ID)
+	res, err := tx.Exec(query, absence.ID)
 	if err != nil {
 		log.Error(err)
 		return 0, err
 	}
+	if err := r.audit(tx, db.AuditDeleteAbsence, db.AuditSnapshot{Absences: []*db.Absence{old}}, db.AuditSnapshot{}, ""); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeAbsence})
 	return res.RowsAffected()
 }
 
-func (r *SQLiteRepository) UpdateVacation(vacation *db.Vacation) (int64, error) {
-	log.Info("Updating vacation", "vacation-id", vacation.ID)
-	query := `UPDATE vacations SET startdate = ?, enddate = ? WHERE id = ?`
+func (r *SQLiteRepository) UpdateAbsence(absence *db.Absence) (int64, error) {
+	log.Info("Updating absence", "absence-id", absence.ID)
+	query := `UPDATE absence SET type = ?, startdate = ?, enddate = ?, halfdaystart = ?, halfdayend = ? WHERE id = ?`
 
 	loc, _ := time.LoadLocation("Europe/Berlin")
-	startDate := vacation.StartDate.In(loc)
-	endDate := vacation.EndDate.In(loc)
+	startDate := absence.StartDate.In(loc)
+	endDate := absence.EndDate.In(loc)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := validateAbsence(tx, absence); err != nil {
+		log.Error(err)
+		return 0, err
+	}
 
-	res, err := r.db.Exec(query, startDate, endDate, vacation.ID)
+	old, err := absenceByID(tx, absence.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	res, err := tx.Exec(query, absence.Type, startDate, endDate, absence.HalfDayStart, absence.HalfDayEnd, absence.ID)
 	if err != nil {
 		log.Error(err)
 		return 0, err
 	}
+	updated := *absence
+	updated.StartDate, updated.EndDate = startDate, endDate
+	err = r.audit(tx, db.AuditUpdateAbsence,
+		db.AuditSnapshot{Absences: []*db.Absence{old}}, db.AuditSnapshot{Absences: []*db.Absence{&updated}}, "")
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeAbsence})
 	return res.RowsAffected()
 }
 
+/*
+Rejects absences ending before they start or overlapping other absences.
+The other absences are read within the transaction which stores the
+absence, so no overlapping absence can be added in between.
+*/
+func validateAbsence(tx *sql.Tx, absence *db.Absence) error {
+	if absence.EndDate.Format(time.DateOnly) < absence.StartDate.Format(time.DateOnly) {
+		return fmt.Errorf("%w: %s - %s", ErrInvalidRange,
+			absence.StartDate.Format(time.DateOnly), absence.EndDate.Format(time.DateOnly))
+	}
+
+	rows, err := tx.Query(`SELECT id, type, startdate, enddate, halfdaystart, halfdayend FROM absence WHERE id != ?`, absence.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	for rows.Next() {
+		var a db.Absence
+		if err := rows.Scan(&a.ID, &a.Type, &a.StartDate, &a.EndDate, &a.HalfDayStart, &a.HalfDayEnd); err != nil {
+			return err
+		}
+		a.StartDate, a.EndDate = a.StartDate.In(loc), a.EndDate.In(loc)
+		if a.Overlaps(absence) {
+			return fmt.Errorf("%w: %s - %s", ErrOverlap,
+				a.StartDate.Format(time.DateOnly), a.EndDate.Format(time.DateOnly))
+		}
+	}
+	return rows.Err()
+}
+
 func (r *SQLiteRepository) UpdateOvertimes(workday *db.Workday) (int64, error) {
 	log.Info("Updating overtimes", "wd", workday)
 	query := `UPDATE workday SET overtime = ? WHERE id = ?`
 
-	res, err := r.db.Exec(query, workday.Overtime, workday.ID)
+	res, err := r.db.Exec(query, toSeconds(workday.Overtime), workday.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) AddContract(contract *db.Contract) (*db.Contract, error) {
+	log.Info("Adding contract", "valid-from", contract.ValidFrom)
+	query := `INSERT INTO contract(validfrom, weekhours,
+		sunday, monday, tuesday, wednesday, thursday, friday, saturday, vacationdays)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	args := []any{contract.ValidFrom.Format(time.DateOnly), contract.WeekHours}
+	for _, t := range contract.Targets {
+		args = append(args, toSeconds(t))
+	}
+	args = append(args, contract.VacationDays)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	contract.ID = id
+	r.notify(Change{Kind: ChangeContract})
+	return contract, nil
+}
+
+func (r *SQLiteRepository) GetAllContract() ([]*db.Contract, error) {
+	log.Debug("Getting all contracts")
+	query := `SELECT id, validfrom, weekhours,
+		sunday, monday, tuesday, wednesday, thursday, friday, saturday, vacationdays
+		FROM contract ORDER BY validfrom ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contracts []*db.Contract
+	for rows.Next() {
+		var c db.Contract
+		var targets [7]int64
+		err := rows.Scan(&c.ID, &c.ValidFrom, &c.WeekHours,
+			&targets[0], &targets[1], &targets[2], &targets[3],
+			&targets[4], &targets[5], &targets[6], &c.VacationDays)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		for i, t := range targets {
+			c.Targets[i] = fromSeconds(t)
+		}
+		contracts = append(contracts, &c)
+	}
+	log.Debug("Contracts", "size", len(contracts))
+
+	return contracts, nil
+}
+
+func (r *SQLiteRepository) UpdateContract(contract *db.Contract) (int64, error) {
+	log.Info("Updating contract", "contract-id", contract.ID)
+	query := `UPDATE contract SET validfrom = ?, weekhours = ?,
+		sunday = ?, monday = ?, tuesday = ?, wednesday = ?, thursday = ?, friday = ?, saturday = ?,
+		vacationdays = ?
+		WHERE id = ?`
+
+	args := []any{contract.ValidFrom.Format(time.DateOnly), contract.WeekHours}
+	for _, t := range contract.Targets {
+		args = append(args, toSeconds(t))
+	}
+	args = append(args, contract.VacationDays, contract.ID)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeContract})
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) DeleteContract(contract *db.Contract) (int64, error) {
+	log.Info("Deleting contract", "contract-id", contract.ID)
+	query := `DELETE FROM contract WHERE id = ?`
+
+	res, err := r.db.Exec(query, contract.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeContract})
+	return res.RowsAffected()
+}
+
+/*
+Adds imported holidays in one transaction,
+an existing holiday on the same date is renamed
+*/
+func (r *SQLiteRepository) AddHolidays(holidays []*db.Holiday) error {
+	log.Info("Adding holidays", "size", len(holidays))
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO holiday(date, name) VALUES(?, ?)
+		ON CONFLICT(date) DO UPDATE SET name = excluded.name`)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, h := range holidays {
+		if _, err := stmt.Exec(h.Date.Format(time.DateOnly), h.Name); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	r.notify(Change{Kind: ChangeHoliday})
+	return nil
+}
+
+func (r *SQLiteRepository) GetAllHoliday() ([]*db.Holiday, error) {
+	log.Debug("Getting all holidays")
+	query := `SELECT id, date, name FROM holiday ORDER BY date ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holidays []*db.Holiday
+	for rows.Next() {
+		var h db.Holiday
+		if err := rows.Scan(&h.ID, &h.Date, &h.Name); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		holidays = append(holidays, &h)
+	}
+
+	return holidays, nil
+}
+
+// Removes all imported holidays
+func (r *SQLiteRepository) DeleteAllHoliday() (int64, error) {
+	log.Info("Deleting all holidays")
+	res, err := r.db.Exec(`DELETE FROM holiday`)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeHoliday})
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) AddProject(project *db.Project) (*db.Project, error) {
+	log.Info("Adding project", "name", project.Name)
+	query := `INSERT INTO project(name, code, color, active, budget) VALUES(?, ?, ?, ?, ?)`
+
+	res, err := r.db.Exec(query, project.Name, project.Code, project.Color, project.Active, toSeconds(project.Budget))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	project.ID = id
+	r.notify(Change{Kind: ChangeProject})
+	return project, nil
+}
+
+func (r *SQLiteRepository) GetAllProject() ([]*db.Project, error) {
+	log.Debug("Getting all projects")
+	query := `SELECT id, name, code, color, active, budget, budget_warned FROM project ORDER BY name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*db.Project
+	for rows.Next() {
+		var p db.Project
+		var budget int64
+		if err := rows.Scan(&p.ID, &p.Name, &p.Code, &p.Color, &p.Active, &budget, &p.BudgetWarned); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		p.Budget = fromSeconds(budget)
+		projects = append(projects, &p)
+	}
+
+	return projects, nil
+}
+
+func (r *SQLiteRepository) UpdateProject(project *db.Project) (int64, error) {
+	log.Info("Updating project", "project-id", project.ID)
+	query := `UPDATE project SET name = ?, code = ?, color = ?, active = ?, budget = ? WHERE id = ?`
+
+	res, err := r.db.Exec(query, project.Name, project.Code, project.Color, project.Active,
+		toSeconds(project.Budget), project.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeProject})
+	return res.RowsAffected()
+}
+
+/*
+Stores the percent of the budget the last warning was sent for. Like the
+figures of a workday it is derived data, the listeners are not notified.
+*/
+func (r *SQLiteRepository) UpdateProjectBudgetWarned(project *db.Project) error {
+	log.Debug("Updating budget warning", "project-id", project.ID, "percent", project.BudgetWarned)
+	query := `UPDATE project SET budget_warned = ? WHERE id = ?`
+
+	if _, err := r.db.Exec(query, project.BudgetWarned, project.ID); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Deletes the project with its tasks, the attributed worktime is kept without project
+func (r *SQLiteRepository) DeleteProject(project *db.Project) (int64, error) {
+	log.Info("Deleting project", "project-id", project.ID)
+	query := `DELETE FROM project WHERE id = ?`
+
+	res, err := r.db.Exec(query, project.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeProject})
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) AddTask(task *db.Task) (*db.Task, error) {
+	log.Info("Adding task", "project-id", task.ProjectID, "name", task.Name)
+	query := `INSERT INTO task(project, name, code, color, active, budget) VALUES(?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.Exec(query, task.ProjectID, task.Name, task.Code, task.Color, task.Active, toSeconds(task.Budget))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	task.ID = id
+	r.notify(Change{Kind: ChangeProject})
+	return task, nil
+}
+
+func (r *SQLiteRepository) GetAllTask() ([]*db.Task, error) {
+	log.Debug("Getting all tasks")
+	query := `SELECT id, project, name, code, color, active, budget FROM task ORDER BY name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*db.Task
+	for rows.Next() {
+		var t db.Task
+		var budget int64
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Name, &t.Code, &t.Color, &t.Active, &budget); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		t.Budget = fromSeconds(budget)
+		tasks = append(tasks, &t)
+	}
+
+	return tasks, nil
+}
+
+func (r *SQLiteRepository) UpdateTask(task *db.Task) (int64, error) {
+	log.Info("Updating task", "task-id", task.ID)
+	query := `UPDATE task SET name = ?, code = ?, color = ?, active = ?, budget = ? WHERE id = ?`
+
+	res, err := r.db.Exec(query, task.Name, task.Code, task.Color, task.Active, toSeconds(task.Budget), task.ID)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	r.notify(Change{Kind: ChangeProject})
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRepository) DeleteTask(task *db.Task) (int64, error) {
+	log.Info("Deleting task", "task-id", task.ID)
+	query := `DELETE FROM task WHERE id = ?`
+
+	res, err := r.db.Exec(query, task.ID)
 	if err != nil {
 		log.Error(err)
 		return 0, err
 	}
+	r.notify(Change{Kind: ChangeProject})
 	return res.RowsAffected()
 }
+
+/*
+Sums up the segments per project and task of the workdays between from and
+to (both inclusive). A segment is a Begin and the following End of the same
+workday, an open Begin is not counted.
+*/
+func (r *SQLiteRepository) GetProjectHours(from time.Time, to time.Time) ([]*db.ProjectHours, error) {
+	log.Debug("Getting project hours", "from", from, "to", to)
+	query := `SELECT COALESCE(b.project, 0), COALESCE(b.task, 0),
+		SUM(strftime('%s', e.time) - strftime('%s', b.time)), COUNT(*)` +
+		projectSegments + `
+	GROUP BY 1, 2
+	ORDER BY 1, 2`
+
+	rows, err := r.db.Query(query, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []*db.ProjectHours
+	for rows.Next() {
+		var h db.ProjectHours
+		var seconds int64
+		if err := rows.Scan(&h.ProjectID, &h.TaskID, &seconds, &h.Segments); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		h.Time = fromSeconds(seconds)
+		hours = append(hours, &h)
+	}
+
+	return hours, nil
+}
+
+/*
+Sums up the recorded segments per project and day between from and to
+(both inclusive), the days without segments are left out.
+*/
+func (r *SQLiteRepository) GetProjectDailyHours(from time.Time, to time.Time) ([]*db.ProjectDayHours, error) {
+	log.Debug("Getting daily project hours", "from", from, "to", to)
+	query := `SELECT COALESCE(b.project, 0), d.date,
+		SUM(strftime('%s', e.time) - strftime('%s', b.time))` +
+		projectSegments + `
+	GROUP BY 1, 2
+	ORDER BY 1, 2`
+
+	rows, err := r.db.Query(query, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	var hours []*db.ProjectDayHours
+	for rows.Next() {
+		var h db.ProjectDayHours
+		var date string
+		var seconds int64
+		if err := rows.Scan(&h.ProjectID, &date, &seconds); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		if len(date) > len(time.DateOnly) {
+			date = date[:len(time.DateOnly)]
+		}
+		if h.Date, err = time.ParseInLocation(time.DateOnly, date, loc); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		h.Time = fromSeconds(seconds)
+		hours = append(hours, &h)
+	}
+
+	return hours, nil
+}
+
+/*
+Pairs each Begin with the following End of its workday between two dates,
+an open Begin has no segment. At the same time an End comes first.
+*/
+const projectSegments = `
+	FROM worktime b
+	JOIN workday d ON d.id = b.workday
+	JOIN worktime e ON e.id = (
+		SELECT n.id FROM worktime n
+		WHERE n.workday = b.workday AND julianday(n.time) > julianday(b.time)
+		ORDER BY julianday(n.time), n.type = 'Begin'
+		LIMIT 1)
+	WHERE b.type = 'Begin' AND e.type = 'End' AND d.date BETWEEN ? AND ?`
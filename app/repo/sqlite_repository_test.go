@@ -0,0 +1,800 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func newTestRepository(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	r := NewSQLiteRepository(openTestDB(t))
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMigrationV3ConvertsDurations(t *testing.T) {
+	r := NewSQLiteRepository(openTestDB(t))
+
+	// Prepare a database in the state of schema version 2
+	if _, err := r.db.Exec(`CREATE TABLE schema_version(
+		version INTEGER PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	for v, up := range []func() error{r.migrationV1, r.migrationV2} {
+		if err := up(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.setSchemaVersion(v + 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO workday(date, time, breaktime, overtime) VALUES ('2025-08-08', '8h14m39s', '30m', '14m39s');
+		INSERT INTO workday(date, time, breaktime, overtime) VALUES ('2025-08-09', '7h4m0s', '35m', '-55m59s');
+		INSERT INTO workday(date) VALUES ('2025-08-10');
+		INSERT INTO worktime(type, time, workday) VALUES ('Begin', '2025-08-08 07:45:33', 1);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	workdays, err := r.GetAllWorkday(ASC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []db.Workday{
+		{Time: 8*time.Hour + 14*time.Minute + 39*time.Second, Breaktime: 30 * time.Minute, Overtime: 14*time.Minute + 39*time.Second},
+		{Time: 7*time.Hour + 4*time.Minute, Breaktime: 35 * time.Minute, Overtime: -55*time.Minute - 59*time.Second},
+		{},
+	}
+	if len(workdays) != len(want) {
+		t.Fatalf("got %d workdays, want %d", len(workdays), len(want))
+	}
+	for i, w := range want {
+		got := workdays[i]
+		if got.Time != w.Time || got.Breaktime != w.Breaktime || got.Overtime != w.Overtime {
+			t.Errorf("workday %d = %v/%v/%v, want %v/%v/%v", i,
+				got.Time, got.Breaktime, got.Overtime, w.Time, w.Breaktime, w.Overtime)
+		}
+	}
+
+	// The worktimes must still reference their workday
+	wts, err := r.GetAllWorktime(workdays[0])
+	if err != nil || len(wts) != 1 {
+		t.Errorf("got %d worktimes with error %v", len(wts), err)
+	}
+
+	var columnType string
+	if err := r.db.QueryRow(`SELECT type FROM pragma_table_info('workday') WHERE name = 'overtime'`).
+		Scan(&columnType); err != nil || columnType != "INTEGER" {
+		t.Errorf("overtime column type = %q, error %v", columnType, err)
+	}
+}
+
+func TestMigrationV7ConvertsVacations(t *testing.T) {
+	r := NewSQLiteRepository(openTestDB(t))
+
+	// Prepare a database in the state of schema version 6
+	if _, err := r.db.Exec(`CREATE TABLE schema_version(
+		version INTEGER PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	for v, up := range []func() error{r.migrationV1, r.migrationV2, r.migrationV3,
+		r.migrationV4, r.migrationV5, r.migrationV6} {
+		if err := up(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.setSchemaVersion(v + 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 8, 8, 0, 0, 0, 0, time.UTC)
+	if _, err := r.db.Exec(`INSERT INTO vacations(startdate, enddate, halfdayend) VALUES(?, ?, 1)`,
+		start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	absences, err := r.GetAllAbsence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absences) != 1 || absences[0].Type != db.AbsenceVacation || !absences[0].HalfDayEnd ||
+		!absences[0].StartDate.Equal(start) || !absences[0].EndDate.Equal(end) {
+		t.Errorf("unexpected absences %+v", absences)
+	}
+}
+
+func TestGetWorkdayTotals(t *testing.T) {
+	r := newTestRepository(t)
+
+	for i, d := range []time.Duration{8 * time.Hour, 9 * time.Hour, 4 * time.Hour} {
+		date := time.Date(2025, 8, 4+i, 0, 0, 0, 0, time.UTC)
+		wd, err := r.AddWorkday(&db.Workday{Date: date})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wd.Time = d
+		wd.Breaktime = 30 * time.Minute
+		wd.Overtime = d - 8*time.Hour
+		if _, err := r.UpdateWorkday(wd); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.UpdateOvertimesBatch([]*db.Workday{wd}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	totals, err := r.GetWorkdayTotals(
+		time.Date(2025, 8, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 8, 6, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if totals.Days != 2 || totals.Time != 13*time.Hour ||
+		totals.Breaktime != time.Hour || totals.Overtime != -3*time.Hour {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+}
+
+func TestContracts(t *testing.T) {
+	r := newTestRepository(t)
+
+	var targets [7]time.Duration
+	targets[time.Monday] = 8 * time.Hour
+	targets[time.Friday] = 6*time.Hour + 30*time.Minute
+
+	later, err := r.AddContract(&db.Contract{
+		ValidFrom:    time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		WeekHours:    32,
+		Targets:      targets,
+		VacationDays: 24,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddContract(&db.Contract{
+		ValidFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		WeekHours: 40,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := r.GetAllContract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 2 || contracts[1].ID != later.ID {
+		t.Fatalf("contracts are not sorted by valid from: %+v", contracts)
+	}
+	if contracts[1].Targets != targets || contracts[1].VacationDays != 24 || contracts[1].WeekHours != 32 {
+		t.Errorf("unexpected contract %+v", contracts[1])
+	}
+
+	later.WeekHours = 30
+	if _, err := r.UpdateContract(later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DeleteContract(contracts[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err = r.GetAllContract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 1 || contracts[0].WeekHours != 30 {
+		t.Errorf("unexpected contracts %+v", contracts)
+	}
+}
+
+func TestAbsenceHalfDays(t *testing.T) {
+	r := newTestRepository(t)
+
+	_, err := r.AddAbsence(&db.Absence{
+		Type:         db.AbsenceSick,
+		StartDate:    time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC),
+		EndDate:      time.Date(2025, 8, 6, 0, 0, 0, 0, time.UTC),
+		HalfDayStart: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	absences, err := r.GetAllAbsence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absences) != 1 || absences[0].Type != db.AbsenceSick || !absences[0].HalfDayStart || absences[0].HalfDayEnd {
+		t.Fatalf("unexpected absences %+v", absences)
+	}
+
+	absences[0].HalfDayEnd = true
+	if _, err := r.UpdateAbsence(absences[0]); err != nil {
+		t.Fatal(err)
+	}
+	absences, err = r.GetAllAbsence()
+	if err != nil || !absences[0].HalfDayEnd {
+		t.Errorf("half day at the end was not updated: %+v %v", absences, err)
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	r := newTestRepository(t)
+
+	christmasEve := time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)
+	err := r.AddHolidays([]*db.Holiday{
+		{Date: christmasEve, Name: "Christmas"},
+		{Date: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), Name: "New Year's Eve"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Importing the same date again renames the holiday
+	if err := r.AddHolidays([]*db.Holiday{{Date: christmasEve, Name: "Christmas Eve"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	holidays, err := r.GetAllHoliday()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holidays) != 2 || holidays[0].Name != "Christmas Eve" || !holidays[0].Date.Equal(christmasEve) {
+		t.Fatalf("unexpected holidays %+v", holidays)
+	}
+
+	if rows, err := r.DeleteAllHoliday(); err != nil || rows != 2 {
+		t.Errorf("deleted %d holidays with error %v", rows, err)
+	}
+}
+
+func TestAbsenceValidation(t *testing.T) {
+	r := newTestRepository(t)
+
+	date := func(day int) time.Time { return time.Date(2025, 8, day, 0, 0, 0, 0, time.UTC) }
+
+	first, err := r.AddAbsence(&db.Absence{StartDate: date(4), EndDate: date(8), HalfDayEnd: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		absence *db.Absence
+		want    error
+	}{
+		{"end before start", &db.Absence{StartDate: date(20), EndDate: date(18)}, ErrInvalidRange},
+		{"inside", &db.Absence{StartDate: date(5), EndDate: date(6)}, ErrOverlap},
+		{"surrounding", &db.Absence{StartDate: date(1), EndDate: date(15)}, ErrOverlap},
+		{"same last day", &db.Absence{StartDate: date(8), EndDate: date(12)}, ErrOverlap},
+		{"afternoon of the last day", &db.Absence{Type: db.AbsenceTraining, StartDate: date(8), EndDate: date(12), HalfDayStart: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.AddAbsence(tt.absence)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("AddAbsence() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// An absence does not overlap itself when it is updated
+	first.EndDate = date(7)
+	if _, err := r.UpdateAbsence(first); err != nil {
+		t.Errorf("UpdateAbsence() error = %v", err)
+	}
+	first.EndDate = date(9)
+	if _, err := r.UpdateAbsence(first); !errors.Is(err, ErrOverlap) {
+		t.Errorf("UpdateAbsence() error = %v, want %v", err, ErrOverlap)
+	}
+}
+
+func TestAddWorkdaysWithWorktimesRollsBack(t *testing.T) {
+	r := newTestRepository(t)
+
+	day := func(d int) time.Time { return time.Date(2025, 8, d, 0, 0, 0, 0, time.UTC) }
+	pair := func(d int) []*db.Worktime {
+		return []*db.Worktime{
+			{Type: "Begin", Time: day(d).Add(8 * time.Hour)},
+			{Type: "End", Time: day(d).Add(16 * time.Hour)},
+		}
+	}
+
+	// The second workday of the same date fails, nothing is written
+	err := r.AddWorkdaysWithWorktimes(
+		[]*db.Workday{{Date: day(4)}, {Date: day(4)}},
+		[][]*db.Worktime{pair(4), pair(4)},
+		nil,
+	)
+	if err == nil {
+		t.Fatal("duplicate workdays should fail")
+	}
+	if workdays, _ := r.GetAllWorkday(ASC); len(workdays) != 0 {
+		t.Fatalf("failed import left %d workdays", len(workdays))
+	}
+
+	workdays := []*db.Workday{{Date: day(4)}, {Date: day(5)}}
+	if err := r.AddWorkdaysWithWorktimes(workdays, [][]*db.Worktime{pair(4), pair(5)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	worktimes, err := r.GetAllWorktime(workdays[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktimes) != 2 || workdays[1].ID == 0 {
+		t.Errorf("got %d worktimes of workday %d", len(worktimes), workdays[1].ID)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	r := newTestRepository(t)
+	var changes []Change
+	r.OnChange(func(c Change) { changes = append(changes, c) })
+
+	wd, err := r.AddWorkday(&db.Workday{Date: time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := r.AddWorktime(&db.Worktime{Type: "Begin", Time: time.Date(2025, 8, 4, 8, 0, 0, 0, time.UTC), Workday: *wd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Calculated figures are no changes
+	wd.Breaktime = 30 * time.Minute
+	if err := r.UpdateWorkdaysBatch([]*db.Workday{wd}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DeleteWorktime(wt, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddAbsence(&db.Absence{Type: db.AbsenceVacation, StartDate: wd.Date, EndDate: wd.Date}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Kind: ChangeWorktime, WorkdayID: wd.ID},
+		{Kind: ChangeWorktime, WorkdayID: wd.ID},
+		{Kind: ChangeWorktime, WorkdayID: wd.ID},
+		{Kind: ChangeAbsence},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got changes %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d is %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	stored, err := r.GetWorkdayByID(wd.ID)
+	if err != nil || stored.Breaktime != 30*time.Minute {
+		t.Errorf("stored workday %+v, %v", stored, err)
+	}
+}
+
+func TestProjectHours(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int, min int) time.Time {
+		return time.Date(2025, 8, day, hour, min, 0, 0, loc)
+	}
+
+	p, err := r.AddProject(&db.Project{Name: "Website", Code: "WEB", Active: true, Budget: 40 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := r.AddTask(&db.Task{ProjectID: p.ID, Name: "Design", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := r.AddWorkday(&db.Workday{Date: at(4, 0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unattributed morning, switch to the project at 10:00 and a running segment
+	err = r.AddWorktimes([]*db.Worktime{
+		{Type: "Begin", Time: at(4, 8, 0), Workday: *wd},
+		{Type: "End", Time: at(4, 10, 0), Workday: *wd},
+		{Type: "Begin", Time: at(4, 10, 0), Workday: *wd, ProjectID: p.ID, TaskID: task.ID, Note: "Mockups"},
+		{Type: "End", Time: at(4, 12, 30), Workday: *wd},
+		{Type: "Begin", Time: at(4, 13, 0), Workday: *wd, ProjectID: p.ID},
+		{Type: "End", Time: at(4, 14, 0), Workday: *wd},
+		{Type: "Begin", Time: at(4, 15, 0), Workday: *wd, ProjectID: p.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hours, err := r.GetProjectHours(at(1, 0, 0), at(31, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []db.ProjectHours{
+		{ProjectID: 0, TaskID: 0, Time: 2 * time.Hour, Segments: 1},
+		{ProjectID: p.ID, TaskID: 0, Time: time.Hour, Segments: 1},
+		{ProjectID: p.ID, TaskID: task.ID, Time: 2*time.Hour + 30*time.Minute, Segments: 1},
+	}
+	if len(hours) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(hours), len(want))
+	}
+	for i := range want {
+		if *hours[i] != want[i] {
+			t.Errorf("row %d is %+v, want %+v", i, *hours[i], want[i])
+		}
+	}
+
+	worktimes, err := r.GetAllWorktime(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktimes[2].ProjectID != p.ID || worktimes[2].TaskID != task.ID || worktimes[2].Note != "Mockups" {
+		t.Errorf("attribution was not stored: %+v", worktimes[2])
+	}
+
+	// The worktime stays without project
+	if _, err := r.DeleteProject(p); err != nil {
+		t.Fatal(err)
+	}
+	if worktimes, _ = r.GetAllWorktime(wd); worktimes[2].ProjectID != 0 || worktimes[2].TaskID != 0 {
+		t.Errorf("attribution was not removed: %+v", worktimes[2])
+	}
+	if tasks, _ := r.GetAllTask(); len(tasks) != 0 {
+		t.Errorf("tasks were not deleted: %d", len(tasks))
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+	times := func(wd *db.Workday) []int {
+		wts, err := r.GetAllWorktime(wd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hours []int
+		for _, wt := range wts {
+			hours = append(hours, wt.Time.Hour())
+		}
+		return hours
+	}
+	equal := func(got []int, want ...int) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if _, err := r.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("got %v, want ErrNothingToUndo", err)
+	}
+
+	wd, err := r.AddWorkday(&db.Workday{Date: at(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	begin, err := r.AddWorktime(&db.Worktime{Type: "Begin", Time: at(8), Workday: *wd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddWorktime(&db.Worktime{Type: "End", Time: at(16), Workday: *wd}); err != nil {
+		t.Fatal(err)
+	}
+	begin.Time = at(9)
+	if _, err := r.UpdateWorktime(begin, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DeleteWorkday(wd, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The workday is restored with its worktimes and their ids
+	entry, err := r.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Action != db.AuditDeleteWorkday {
+		t.Errorf("undid %s, want %s", entry.Action, db.AuditDeleteWorkday)
+	}
+	if got := times(wd); !equal(got, 9, 16) {
+		t.Errorf("got times %v after undoing the deletion, want [9 16]", got)
+	}
+
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := times(wd); !equal(got, 8, 16) {
+		t.Errorf("got times %v after undoing the update, want [8 16]", got)
+	}
+
+	if _, err := r.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := times(wd); !equal(got, 9, 16) {
+		t.Errorf("got times %v after redoing the update, want [9 16]", got)
+	}
+
+	// A new change drops the undone deletion
+	if _, err := r.AddAbsence(&db.Absence{Type: db.AbsenceSick, StartDate: at(0).AddDate(0, 0, 1), EndDate: at(0).AddDate(0, 0, 1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Fatalf("got %v, want ErrNothingToRedo", err)
+	}
+
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	absences, err := r.GetAllAbsence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absences) != 0 {
+		t.Errorf("got %d absences after undo, want 0", len(absences))
+	}
+}
+
+func TestAuditReasonAndCorrected(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+
+	// The first entry creates the workday
+	begin, err := r.AddWorktime(&db.Worktime{Type: "Begin", Time: at(8)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd := &begin.Workday
+	if _, err := r.AddCorrectedWorktime(&db.Worktime{Type: "End", Time: at(16), Workday: *wd}, "Forgot to clock out"); err != nil {
+		t.Fatal(err)
+	}
+	// Attributing a segment is no correction
+	begin.Note = "Mockups"
+	if _, err := r.UpdateWorktime(begin, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	worktimes, err := r.GetAllWorktime(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktimes[0].Corrected || !worktimes[1].Corrected {
+		t.Errorf("got corrected %v and %v, want false and true", worktimes[0].Corrected, worktimes[1].Corrected)
+	}
+
+	begin.Time = at(9)
+	if _, err := r.UpdateWorktime(begin, "Doctor's appointment"); err != nil {
+		t.Fatal(err)
+	}
+	if !begin.Corrected {
+		t.Error("a changed time is not flagged as corrected")
+	}
+
+	entries, err := r.GetAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d audit entries, want 4", len(entries))
+	}
+	last := entries[3]
+	if last.Action != db.AuditUpdateWorktime || last.Reason != "Doctor's appointment" {
+		t.Errorf("got %s with reason %q", last.Action, last.Reason)
+	}
+	if last.Old.Worktimes[0].Time.Hour() != 8 || last.New.Worktimes[0].Time.Hour() != 9 {
+		t.Errorf("got old %s and new %s, want 8:00 and 9:00",
+			last.Old.Worktimes[0].Time, last.New.Worktimes[0].Time)
+	}
+	if entries[1].Reason != "Forgot to clock out" {
+		t.Errorf("got reason %q for the added entry", entries[1].Reason)
+	}
+}
+
+func TestSaveWorkday(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+
+	// A forgotten day is created with its worktimes
+	begin := &db.Worktime{Type: "Begin", Time: at(8)}
+	end := &db.Worktime{Type: "End", Time: at(12)}
+	wd, err := r.SaveWorkday(at(0), []*db.Worktime{begin, end}, "Forgot the whole day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd == nil || begin.ID == 0 || !begin.Corrected || end.Workday.ID != wd.ID {
+		t.Fatalf("got workday %+v and begin %+v", wd, begin)
+	}
+
+	// The end is changed, an afternoon is added and nothing else is touched
+	end.Time = at(11)
+	worktimes := []*db.Worktime{begin, end,
+		{Type: "Begin", Time: at(13)}, {Type: "End", Time: at(17)}}
+	if _, err := r.SaveWorkday(at(0), worktimes, ""); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := r.GetAllWorktime(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 4 || stored[1].Time.Hour() != 11 {
+		t.Fatalf("got %d worktimes, want 4 with the end at 11", len(stored))
+	}
+
+	// Saving the same worktimes again records nothing
+	entries, err := r.GetAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SaveWorkday(at(0), worktimes, ""); err != nil {
+		t.Fatal(err)
+	}
+	again, err := r.GetAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(entries) || again[len(again)-1].Action != db.AuditEditWorkday {
+		t.Errorf("got %d audit entries after saving unchanged worktimes, want %d", len(again), len(entries))
+	}
+
+	// Without worktimes the workday is removed, undo brings it back
+	if wd, err := r.SaveWorkday(at(0), nil, ""); err != nil || wd != nil {
+		t.Fatalf("SaveWorkday() without worktimes = %+v, %v", wd, err)
+	}
+	if _, err := r.GetWorkday(at(0)); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("got %v, want the workday to be deleted", err)
+	}
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := r.GetAllWorktime(wd); err != nil || len(stored) != 4 {
+		t.Fatalf("got %d worktimes after undo, want 4 (%v)", len(stored), err)
+	}
+
+	// Undoing the creation removes the workday again
+	for range 2 {
+		if _, err := r.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.GetWorkday(at(0)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got %v, want the created workday to be undone", err)
+	}
+
+	// Worktimes of another day are rejected
+	other, err := r.AddWorkday(&db.Workday{Date: at(0).AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := r.AddWorktime(&db.Worktime{Type: "Begin", Time: at(8).AddDate(0, 0, 1), Workday: *other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SaveWorkday(at(0), []*db.Worktime{foreign}, ""); err == nil {
+		t.Error("a worktime of another day was accepted")
+	}
+}
+
+func TestGetAllWorktimes(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	for day := 4; day <= 5; day++ {
+		wd, err := r.AddWorkday(&db.Workday{Date: time.Date(2025, 8, day, 0, 0, 0, 0, loc)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for hour, wtType := range map[int]string{8: "Begin", 16: "End"} {
+			if _, err := r.AddWorktime(&db.Worktime{Type: wtType, Time: time.Date(2025, 8, day, hour, 0, 0, 0, loc), Workday: *wd}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	worktimes, err := r.GetAllWorktimes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktimes) != 4 {
+		t.Fatalf("got %d worktimes, want 4", len(worktimes))
+	}
+	for _, wt := range worktimes {
+		if wt.Workday.ID == 0 || wt.Time.Day() != 3+int(wt.Workday.ID) {
+			t.Errorf("worktime %d at %s belongs to workday %d", wt.ID, wt.Time, wt.Workday.ID)
+		}
+	}
+}
+
+func TestUndoCreatedWorkdays(t *testing.T) {
+	r := newTestRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 8, day, hour, 0, 0, 0, loc)
+	}
+
+	// Undoing the first entry of a day leaves no empty workday behind
+	if _, err := r.AddWorktime(&db.Worktime{Type: "Begin", Time: at(4, 8)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetWorkday(at(4, 0)); err != nil {
+		t.Fatalf("workday was not created: %v", err)
+	}
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetWorkday(at(4, 0)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got %v after undo, want the workday to be removed", err)
+	}
+
+	// An import is undone at once
+	workdays := []*db.Workday{{Date: at(5, 0)}, {Date: at(6, 0)}}
+	worktimes := [][]*db.Worktime{
+		{{Type: "Begin", Time: at(5, 8)}, {Type: "End", Time: at(5, 16)}},
+		{{Type: "Begin", Time: at(6, 8)}, {Type: "End", Time: at(6, 16)}},
+	}
+	if err := r.AddWorkdaysWithWorktimes(workdays, worktimes, nil); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := r.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Action != db.AuditImport {
+		t.Errorf("undid %s, want %s", entry.Action, db.AuditImport)
+	}
+	all, err := r.GetAllWorkday(DESC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Errorf("got %d workdays after undoing the import, want 0", len(all))
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// AbsenceEffect describes how an absence changes the overtime
+type AbsenceEffect int
+
+const (
+	// The target time is credited, worked time counts as overtime
+	AbsenceEffectCredit AbsenceEffect = iota
+	// Worked time is topped up to the target time
+	AbsenceEffectTopUp
+	// There is no target time and nothing is credited
+	AbsenceEffectNoTarget
+	// The target time is taken from the overtime, even without a workday
+	AbsenceEffectCompTime
+)
+
+var absenceEffects = map[db.AbsenceType]AbsenceEffect{
+	db.AbsenceVacation:     AbsenceEffectCredit,
+	db.AbsenceSick:         AbsenceEffectCredit,
+	db.AbsenceCompTime:     AbsenceEffectCompTime,
+	db.AbsenceBusinessTrip: AbsenceEffectTopUp,
+	db.AbsenceTraining:     AbsenceEffectTopUp,
+	db.AbsenceParental:     AbsenceEffectNoTarget,
+	db.AbsenceUnpaid:       AbsenceEffectNoTarget,
+}
+
+// Returns the effect of an absence type, unknown types are credited like vacation
+func AbsenceEffectOf(t db.AbsenceType) AbsenceEffect {
+	if effect, ok := absenceEffects[t]; ok {
+		return effect
+	}
+	return AbsenceEffectCredit
+}
+
+// Only vacation days are taken from the vacation budget
+func ConsumesVacationBudget(t db.AbsenceType) bool {
+	return t == db.AbsenceVacation
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/charmbracelet/log"
+)
+
+// AuditService lists the manual corrections of the time entries
+type AuditService struct {
+	repo *repo.SQLiteRepository
+}
+
+func NewAuditService(repo *repo.SQLiteRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+/*
+Returns the corrections of the days between both dates including in the
+order they were made. Undone corrections are listed with their state.
+*/
+func (as *AuditService) Corrections(from time.Time, to time.Time) ([]*ExportCorrection, error) {
+	entries, err := as.repo.GetAuditLog()
+	if err != nil {
+		return nil, err
+	}
+
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	corrections := []*ExportCorrection{}
+	for _, e := range entries {
+		if !isCorrection(e) {
+			continue
+		}
+		date := correctionDate(e)
+		if date < first || date > last {
+			continue
+		}
+		corrections = append(corrections, &ExportCorrection{
+			Date:      date,
+			ChangedAt: e.Time.Format(time.RFC3339),
+			Action:    e.Action,
+			Old:       formatAuditWorktimes(e.Old.Worktimes),
+			New:       formatAuditWorktimes(e.New.Worktimes),
+			Reason:    e.Reason,
+			State:     e.State,
+		})
+	}
+	return corrections, nil
+}
+
+/*
+Removes the changes made before the given time from the undo history, the
+corrections are kept for the exports
+*/
+func (as *AuditService) Prune(before time.Time) error {
+	deleted, err := as.repo.PruneAuditLog(before, isCorrection)
+	if err != nil {
+		return err
+	}
+	log.Info("Pruned audit log", "deleted", deleted)
+	return nil
+}
+
+// Clocking in and out is recorded as well, only entries added by hand are corrections
+func isCorrection(e *db.AuditEntry) bool {
+	switch e.Action {
+	case db.AuditUpdateWorktime:
+		// Attributing a segment to a project is no correction
+		return len(e.New.Worktimes) > 0 && e.New.Worktimes[0].Corrected &&
+			(len(e.Old.Worktimes) == 0 || !e.Old.Worktimes[0].Time.Equal(e.New.Worktimes[0].Time) ||
+				e.Old.Worktimes[0].Type != e.New.Worktimes[0].Type)
+	case db.AuditDeleteWorktime, db.AuditDeleteWorkday, db.AuditEditWorkday:
+		return true
+	case db.AuditAddWorktime:
+		for _, wt := range e.New.Worktimes {
+			if wt.Corrected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the changed day as YYYY-MM-DD
+func correctionDate(e *db.AuditEntry) string {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	for _, s := range []db.AuditSnapshot{e.Old, e.New} {
+		if len(s.Worktimes) > 0 {
+			return s.Worktimes[0].Time.In(loc).Format(time.DateOnly)
+		}
+		if len(s.Workdays) > 0 {
+			return s.Workdays[0].Date.Format(time.DateOnly)
+		}
+	}
+	return ""
+}
+
+// Formats time entries like Begin 08:00, End 16:30
+func formatAuditWorktimes(worktimes []*db.Worktime) string {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	sorted := sortedWorktimes(worktimes)
+	parts := make([]string, len(sorted))
+	for i, wt := range sorted {
+		parts[i] = wt.Type + " " + wt.Time.In(loc).Format("15:04")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestCorrections(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	as := NewAuditService(ts.repo)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 8, day, hour, 0, 0, 0, loc)
+	}
+
+	// Clocking in is no correction
+	begin, err := ts.Toggle(at(4, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.repo.AddCorrectedWorktime(&db.Worktime{Type: "End", Time: at(4, 16), Workday: begin.Workday},
+		"Forgot to clock out"); err != nil {
+		t.Fatal(err)
+	}
+	begin.Time = at(4, 9)
+	if _, err := ts.repo.UpdateWorktime(begin, "Doctor's appointment"); err != nil {
+		t.Fatal(err)
+	}
+	begin.Note = "Mockups"
+	if _, err := ts.repo.UpdateWorktime(begin, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Outside of the range
+	other, err := ts.Toggle(at(11, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.repo.DeleteWorktime(other, "Clocked in by mistake"); err != nil {
+		t.Fatal(err)
+	}
+
+	corrections, err := as.Corrections(at(1, 0), at(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExportCorrection{
+		{Date: "2025-08-04", Action: db.AuditAddWorktime, New: "End 16:00", Reason: "Forgot to clock out", State: db.AuditDone},
+		{Date: "2025-08-04", Action: db.AuditUpdateWorktime, Old: "Begin 08:00", New: "Begin 09:00", Reason: "Doctor's appointment", State: db.AuditDone},
+	}
+	if len(corrections) != len(want) {
+		t.Fatalf("got %d corrections, want %d", len(corrections), len(want))
+	}
+	for i, c := range corrections {
+		got := *c
+		got.ChangedAt = ""
+		if got != want[i] {
+			t.Errorf("correction %d is %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	export := &Export{Version: ExportSchemaVersion, Days: []*ExportDay{}, Corrections: corrections}
+	var buf bytes.Buffer
+	if err := WriteExportCSV(&buf, export, ExportLocaleFor("de-DE")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Begin 08:00;Begin 09:00;Doctor's appointment;done") {
+		t.Errorf("the correction history is missing in the CSV:\n%s", buf.String())
+	}
+
+	sheet := &Timesheet{Month: at(1, 0), Corrections: corrections}
+	labels := TimesheetLabels{Action: func(a db.AuditAction) string { return string(a) }}
+	buf.Reset()
+	if err := WriteTimesheetPDF(&buf, sheet, labels); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUndoCoversUserCommands(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	ss := NewSessionService(ts, filepath.Join(t.TempDir(), "heartbeat"))
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+
+	// Recorded automatically
+	if _, err := ss.Start(at(8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.SwitchProject(at(10), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Command of the user
+	end, err := ts.Toggle(at(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ts.repo.GetAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].New.Worktimes[0].ID != end.ID {
+		t.Fatalf("got %d audit entries, want only the toggle", len(entries))
+	}
+
+	// Undo reverts the toggle, the automatic entries stay
+	if _, err := ts.repo.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	pairs, err := ts.DayEntries(at(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 2 || pairs[1].End != nil {
+		t.Errorf("got %d entries after undo, want the session and the switched segment", len(pairs))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	as := NewAuditService(ts.repo)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+
+	begin, err := ts.Toggle(at(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.repo.AddCorrectedWorktime(&db.Worktime{Type: "End", Time: at(16), Workday: begin.Workday},
+		"Forgot to clock out"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := as.Prune(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ts.repo.GetAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].State != db.AuditArchived {
+		t.Fatalf("got %d audit entries, want the archived correction", len(entries))
+	}
+	if _, err := ts.repo.Undo(); err == nil {
+		t.Error("a pruned change was undone")
+	}
+	corrections, err := as.Corrections(at(0), at(0))
+	if err != nil || len(corrections) != 1 {
+		t.Errorf("got %d corrections after pruning, want 1 (%v)", len(corrections), err)
+	}
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// DayResult holds the calculated figures of a single workday
+type DayResult struct {
+	Workday *db.Workday
+
+	// Worked time without breaks
+	Worktime  time.Duration
+	Breaktime time.Duration
+	Overtime  time.Duration
+
+	// Time which should have been worked on this day
+	Target time.Duration
+	// Part of the target which is fulfilled by absences
+	Credit time.Duration
+
+	// Running overtime total up to and including this day
+	Balance time.Duration
+}
+
+// CalculationResult is the outcome of a calculation over several workdays
+type CalculationResult struct {
+	// Calculated days, sorted ascending by date
+	Days []*DayResult
+
+	// Overtime consumed by compensation days without a workday
+	CompTime time.Duration
+
+	// Total overtime including the imported overtime
+	Total time.Duration
+}
+
+// Day returns the result of the workday with the given id or nil
+func (cr *CalculationResult) Day(workdayID int64) *DayResult {
+	for _, d := range cr.Days {
+		if d.Workday.ID == workdayID {
+			return d
+		}
+	}
+	return nil
+}
+
+// Calculator calculates the figures of workdays based on settings and contracts
+type Calculator struct {
+	Settings *model.Settings
+
+	// Contracts in any order, days before the first contract use the settings
+	Contracts []*db.Contract
+
+	// Absences change the target time depending on their type
+	Absences []*db.Absence
+
+	// There is no target time on holidays
+	Holidays *HolidayCalendar
+}
+
+func NewCalculator(settings *model.Settings, contracts []*db.Contract) *Calculator {
+	sorted := make([]*db.Contract, len(contracts))
+	copy(sorted, contracts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ValidFrom.Before(sorted[j].ValidFrom)
+	})
+
+	return &Calculator{
+		Settings:  settings,
+		Contracts: sorted,
+	}
+}
+
+/*
+Calculates worktime, breaktime and overtime of all given workdays.
+The worktimes are assigned to their workday by the workday id,
+the order of both slices does not matter. Compensation days until
+today without a workday are taken from the total overtime as well.
+*/
+func (c *Calculator) Calculate(workdays []*db.Workday, worktimes []*db.Worktime) *CalculationResult {
+	return c.Recalculate(&CalculationResult{}, time.Time{}, workdays, worktimes)
+}
+
+/*
+Calculates the workdays from the given date on like Calculate, the days
+before keep the figures of the previous result. Only the balance of the
+later days changes, so nothing before the date may have changed since the
+previous result. The workdays are all workdays, the worktimes are needed
+for the workdays from the date on.
+*/
+func (c *Calculator) Recalculate(previous *CalculationResult, from time.Time, workdays []*db.Workday, worktimes []*db.Worktime) *CalculationResult {
+	byWorkday := make(map[int64][]*db.Worktime)
+	for _, wt := range worktimes {
+		byWorkday[wt.Workday.ID] = append(byWorkday[wt.Workday.ID], wt)
+	}
+
+	days := make([]*db.Workday, len(workdays))
+	copy(days, workdays)
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
+	result := &CalculationResult{
+		Days: make([]*DayResult, 0, len(days)),
+		// Overtime imported from previous systems is the starting point
+		Total: time.Duration(c.Settings.ImportOvertime * float64(time.Hour)),
+	}
+	from = dateOnly(from)
+	var kept time.Time
+	for _, d := range previous.Days {
+		if !dateOnly(d.Workday.Date).Before(from) {
+			break
+		}
+		result.Days = append(result.Days, d)
+		result.Total = d.Balance
+		kept = dateOnly(d.Workday.Date)
+	}
+
+	compDays := c.compTimeDays(days, time.Now())
+	takeCompDay := func(day time.Time) {
+		consumed := time.Duration(float64(c.TargetFor(day)) *
+			c.AbsenceShare(day, AbsenceEffectCompTime)).Round(time.Second)
+		result.CompTime += consumed
+		// The balance of the kept days already contains their compensation days
+		if day.After(kept) {
+			result.Total -= consumed
+		}
+	}
+
+	for _, wd := range days {
+		if dateOnly(wd.Date).Before(from) {
+			continue
+		}
+		// Keep the balance in order of the dates
+		for len(compDays) > 0 && compDays[0].Before(dateOnly(wd.Date)) {
+			takeCompDay(compDays[0])
+			compDays = compDays[1:]
+		}
+
+		worktime, breaktime := CalculateWorkday(byWorkday[wd.ID], c.Settings.BreakRules)
+		target, credit := c.targetAndCredit(wd.Date, worktime)
+		overtime := worktime + credit - target
+		result.Total += overtime
+
+		result.Days = append(result.Days, &DayResult{
+			Workday:   wd,
+			Worktime:  worktime,
+			Breaktime: breaktime,
+			Overtime:  overtime,
+			Target:    target,
+			Credit:    credit,
+			Balance:   result.Total,
+		})
+	}
+	for _, day := range compDays {
+		takeCompDay(day)
+	}
+
+	return result
+}
+
+/*
+Returns the target of the date reduced by absences without target time
+and the part of it which is credited by absences. Absences which are
+topped up only credit the time which was not worked.
+*/
+func (c *Calculator) targetAndCredit(date time.Time, worktime time.Duration) (target time.Duration, credit time.Duration) {
+	full := c.TargetFor(date)
+	share := func(effect AbsenceEffect) time.Duration {
+		return time.Duration(float64(full) * c.AbsenceShare(date, effect)).Round(time.Second)
+	}
+
+	target = full - share(AbsenceEffectNoTarget)
+	credit = share(AbsenceEffectCredit)
+	if topUp := share(AbsenceEffectTopUp); topUp > 0 {
+		credit += max(0, min(topUp, target-credit-worktime))
+	}
+	return target, credit
+}
+
+// Returns the compensation days until the given day which have no workday, sorted ascending
+func (c *Calculator) compTimeDays(workdays []*db.Workday, until time.Time) []time.Time {
+	hasWorkday := make(map[string]bool, len(workdays))
+	for _, wd := range workdays {
+		hasWorkday[wd.Date.Format(time.DateOnly)] = true
+	}
+
+	var days []time.Time
+	for _, a := range c.Absences {
+		if AbsenceEffectOf(a.Type) != AbsenceEffectCompTime {
+			continue
+		}
+		for d := dateOnly(a.StartDate); !d.After(dateOnly(a.EndDate)) && !d.After(dateOnly(until)); d = d.AddDate(0, 0, 1) {
+			if !hasWorkday[d.Format(time.DateOnly)] && !slices.ContainsFunc(days, d.Equal) {
+				days = append(days, d)
+			}
+		}
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	return days
+}
+
+// Returns the contract which is valid on the given date or nil
+func (c *Calculator) ContractFor(date time.Time) *db.Contract {
+	day := dateOnly(date)
+
+	var valid *db.Contract
+	for _, contract := range c.Contracts {
+		if dateOnly(contract.ValidFrom).After(day) {
+			break
+		}
+		valid = contract
+	}
+	return valid
+}
+
+// Returns which part of the given date is absent with the given effect: 0, 0.5 or 1
+func (c *Calculator) AbsenceShare(date time.Time, effect AbsenceEffect) float64 {
+	var share float64
+	for _, a := range c.Absences {
+		if AbsenceEffectOf(a.Type) == effect {
+			share += a.DayShare(date)
+		}
+	}
+	return min(share, 1)
+}
+
+// Returns which part of the given date is taken from the vacation budget: 0, 0.5 or 1
+func (c *Calculator) VacationShare(date time.Time) float64 {
+	var share float64
+	for _, a := range c.Absences {
+		if ConsumesVacationBudget(a.Type) {
+			share += a.DayShare(date)
+		}
+	}
+	return min(share, 1)
+}
+
+// Counts the days of an absence, days without target time like weekends and holidays are skipped
+func (c *Calculator) AbsenceDays(a *db.Absence) float64 {
+	var days float64
+	for d := dateOnly(a.StartDate); !d.After(dateOnly(a.EndDate)); d = d.AddDate(0, 0, 1) {
+		if c.TargetFor(d) > 0 {
+			days += a.DayShare(d)
+		}
+	}
+	return days
+}
+
+// Returns the time which should be worked on the given date
+func (c *Calculator) TargetFor(date time.Time) time.Duration {
+	if c.Holidays.HolidayOn(date) != nil {
+		return 0
+	}
+	if contract := c.ContractFor(date); contract != nil {
+		return contract.TargetFor(date)
+	}
+	return c.Settings.TargetFor(date)
+}
+
+/*
+Calculates the worked time and the breaktime of a single workday.
+
+The mandatory break is taken from the break rules, e.g. in Germany
+
+	breaktime := 0min when time < 6:00
+	breaktime := 30min when time >= 6:00
+	breaktime := 45min when time >= 9:00
+
+If the day contains recorded pauses (at least two closed Begin/End pairs)
+these count as breaktime. Recorded pauses shorter than the mandatory break
+are topped up to the mandatory break, the difference is taken from the worktime.
+A trailing Begin without End is not counted.
+*/
+func CalculateWorkday(worktimes []*db.Worktime, rules []model.BreakRule) (worktime time.Duration, breaktime time.Duration) {
+	wts := make([]*db.Worktime, len(worktimes))
+	copy(wts, worktimes)
+	sort.SliceStable(wts, func(i, j int) bool {
+		return wts[i].Time.Before(wts[j].Time)
+	})
+
+	var recordedBreak time.Duration
+	for i := 0; i+1 < len(wts); i += 2 {
+		// Remove the nanoseconds from the time
+		begin := wts[i].Time.Truncate(time.Second)
+		end := wts[i+1].Time.Truncate(time.Second)
+		worktime += end.Sub(begin)
+
+		// Pauses are only taken into account for closed days
+		if i > 0 && len(wts)%2 == 0 {
+			recordedBreak += begin.Sub(wts[i-1].Time.Truncate(time.Second))
+		}
+	}
+
+	mandatory := model.MandatoryBreak(rules, worktime)
+	if recordedBreak >= mandatory {
+		return worktime, recordedBreak
+	}
+
+	return worktime - (mandatory - recordedBreak), mandatory
+}
+
+// Strips the time and location of a date so dates can be compared
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,511 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// Creates alternating Begin/End worktimes for the given workday
+func worktimes(wd *db.Workday, clock ...string) []*db.Worktime {
+	var wts []*db.Worktime
+	for i, c := range clock {
+		t, err := time.Parse(time.DateTime, wd.Date.Format(time.DateOnly)+" "+c)
+		if err != nil {
+			panic(err)
+		}
+		wtType := "Begin"
+		if i%2 != 0 {
+			wtType = "End"
+		}
+		wts = append(wts, &db.Worktime{
+			ID:      int64(i + 1),
+			Type:    wtType,
+			Time:    t,
+			Workday: *wd,
+		})
+	}
+	return wts
+}
+
+func workday(id int64, date string) *db.Workday {
+	d, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		panic(err)
+	}
+	return &db.Workday{ID: id, Date: d}
+}
+
+var germany = model.BreakRulesForPolicy(model.BreakPolicyGermany)
+
+func TestCalculateWorkday(t *testing.T) {
+	wd := workday(1, "2025-08-08")
+
+	tests := []struct {
+		name          string
+		clock         []string
+		wantWorktime  time.Duration
+		wantBreaktime time.Duration
+	}{
+		{"no entries", nil, 0, 0},
+		{"below 6h", []string{"08:00:00", "13:59:59"}, 5*time.Hour + 59*time.Minute + 59*time.Second, 0},
+		{"exactly 6h", []string{"08:00:00", "14:00:00"}, 5*time.Hour + 30*time.Minute, 30 * time.Minute},
+		{"between 6h and 9h", []string{"08:00:00", "16:30:00"}, 8 * time.Hour, 30 * time.Minute},
+		{"exactly 9h", []string{"08:00:00", "17:00:00"}, 8*time.Hour + 15*time.Minute, 45 * time.Minute},
+		{"above 9h", []string{"07:00:00", "17:30:00"}, 9*time.Hour + 45*time.Minute, 45 * time.Minute},
+		{"multi segment uses recorded pause",
+			[]string{"07:15:00", "12:10:00", "12:45:00", "16:00:00"},
+			8*time.Hour + 10*time.Minute, 35 * time.Minute},
+		{"short recorded pause is topped up",
+			[]string{"08:00:00", "12:00:00", "12:10:00", "16:00:00"},
+			7*time.Hour + 30*time.Minute, 30 * time.Minute},
+		{"short recorded pause is topped up above 9h",
+			[]string{"07:00:00", "12:00:00", "12:30:00", "17:00:00"},
+			9*time.Hour + 15*time.Minute, 45 * time.Minute},
+		{"three segments",
+			[]string{"08:00:00", "10:00:00", "10:15:00", "12:00:00", "13:00:00", "17:00:00"},
+			7*time.Hour + 45*time.Minute, 1*time.Hour + 15*time.Minute},
+		{"open begin only", []string{"08:00:00"}, 0, 0},
+		{"open entry after segment ignores trailing begin",
+			[]string{"08:00:00", "15:00:00", "15:30:00"},
+			6*time.Hour + 30*time.Minute, 30 * time.Minute},
+		{"open entry after two segments uses break rule",
+			[]string{"08:00:00", "12:00:00", "12:10:00", "15:10:00", "15:30:00"},
+			6*time.Hour + 30*time.Minute, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worktime, breaktime := CalculateWorkday(worktimes(wd, tt.clock...), germany)
+			if worktime != tt.wantWorktime {
+				t.Errorf("worktime = %v, want %v", worktime, tt.wantWorktime)
+			}
+			if breaktime != tt.wantBreaktime {
+				t.Errorf("breaktime = %v, want %v", breaktime, tt.wantBreaktime)
+			}
+		})
+	}
+}
+
+func TestCalculateWorkdayUnsorted(t *testing.T) {
+	wd := workday(1, "2025-08-09")
+	wts := worktimes(wd, "07:15:00", "12:10:00", "12:45:00", "16:00:00")
+	wts[0], wts[3] = wts[3], wts[0]
+
+	worktime, breaktime := CalculateWorkday(wts, germany)
+	if worktime != 8*time.Hour+10*time.Minute || breaktime != 35*time.Minute {
+		t.Errorf("got worktime %v and breaktime %v", worktime, breaktime)
+	}
+}
+
+func TestCalculateWorkdayBreakPolicies(t *testing.T) {
+	wd := workday(1, "2025-08-08")
+	day := worktimes(wd, "08:00:00", "12:00:00", "12:15:00", "17:30:00")
+
+	tests := []struct {
+		policy        model.BreakPolicy
+		wantWorktime  time.Duration
+		wantBreaktime time.Duration
+	}{
+		// 9h15 worked with a recorded pause of 15min
+		{model.BreakPolicyGermany, 8*time.Hour + 45*time.Minute, 45 * time.Minute},
+		{model.BreakPolicyAustria, 9 * time.Hour, 30 * time.Minute},
+		{model.BreakPolicySwitzerland, 8*time.Hour + 30*time.Minute, 60 * time.Minute},
+		{model.BreakPolicyFrance, 9*time.Hour + 10*time.Minute, 20 * time.Minute},
+		{model.BreakPolicyNone, 9*time.Hour + 15*time.Minute, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			worktime, breaktime := CalculateWorkday(day, model.BreakRulesForPolicy(tt.policy))
+			if worktime != tt.wantWorktime {
+				t.Errorf("worktime = %v, want %v", worktime, tt.wantWorktime)
+			}
+			if breaktime != tt.wantBreaktime {
+				t.Errorf("breaktime = %v, want %v", breaktime, tt.wantBreaktime)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	monday := workday(1, "2025-08-04")
+	tuesday := workday(2, "2025-08-05")
+	wednesday := workday(3, "2025-08-06")
+
+	var wts []*db.Worktime
+	// 8h worked, 30min break
+	wts = append(wts, worktimes(monday, "08:00:00", "16:30:00")...)
+	// 9h15 worked, 45min break
+	wts = append(wts, worktimes(tuesday, "07:00:00", "17:00:00")...)
+	// 4h worked, still open
+	wts = append(wts, worktimes(wednesday, "08:00:00", "12:00:00", "12:30:00")...)
+
+	tests := []struct {
+		name           string
+		weekHours      int
+		importOvertime float64
+		wantOvertime   []time.Duration
+		wantTotal      time.Duration
+	}{
+		{"40h week",
+			40, 0,
+			[]time.Duration{0, 1*time.Hour + 15*time.Minute, -4 * time.Hour},
+			-2*time.Hour - 45*time.Minute},
+		{"40h week with imported overtime",
+			40, 10.5,
+			[]time.Duration{0, 1*time.Hour + 15*time.Minute, -4 * time.Hour},
+			7*time.Hour + 45*time.Minute},
+		{"35h week",
+			35, 0,
+			[]time.Duration{1 * time.Hour, 2*time.Hour + 15*time.Minute, -3 * time.Hour},
+			15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := model.NewSettings("", "")
+			settings.WeekHours = tt.weekHours
+			settings.TargetHours = model.DefaultTargetHours(float64(tt.weekHours))
+			settings.ImportOvertime = tt.importOvertime
+
+			// Workdays are passed in descending order like the repository returns them
+			result := NewCalculator(settings, nil).Calculate([]*db.Workday{wednesday, tuesday, monday}, wts)
+
+			if len(result.Days) != len(tt.wantOvertime) {
+				t.Fatalf("got %d days, want %d", len(result.Days), len(tt.wantOvertime))
+			}
+
+			balance := time.Duration(tt.importOvertime * float64(time.Hour))
+			for i, d := range result.Days {
+				if d.Overtime != tt.wantOvertime[i] {
+					t.Errorf("day %d overtime = %v, want %v", i, d.Overtime, tt.wantOvertime[i])
+				}
+				balance += tt.wantOvertime[i]
+				if d.Balance != balance {
+					t.Errorf("day %d balance = %v, want %v", i, d.Balance, balance)
+				}
+			}
+
+			if result.Total != tt.wantTotal {
+				t.Errorf("total = %v, want %v", result.Total, tt.wantTotal)
+			}
+
+			if result.Day(tuesday.ID).Worktime != 9*time.Hour+15*time.Minute {
+				t.Errorf("tuesday worktime = %v", result.Day(tuesday.ID).Worktime)
+			}
+		})
+	}
+}
+
+func TestCalculateTargetSchedule(t *testing.T) {
+	// 2025-08-04 is a Monday
+	days := []*db.Workday{
+		workday(1, "2025-08-04"),
+		workday(2, "2025-08-07"),
+		workday(3, "2025-08-08"),
+		workday(4, "2025-08-09"),
+	}
+
+	var wts []*db.Worktime
+	for _, d := range days {
+		// 6h worked, 30min break
+		wts = append(wts, worktimes(d, "08:00:00", "14:30:00")...)
+	}
+
+	settings := model.NewSettings("", "")
+	settings.TargetHours = map[model.Weekday]float64{
+		model.Monday:    8,
+		model.Tuesday:   8,
+		model.Wednesday: 8,
+		model.Thursday:  6.5,
+	}
+
+	result := NewCalculator(settings, nil).Calculate(days, wts)
+
+	want := []struct {
+		target   time.Duration
+		overtime time.Duration
+	}{
+		{8 * time.Hour, -2 * time.Hour},
+		{6*time.Hour + 30*time.Minute, -30 * time.Minute},
+		// Friday and Saturday are no working days, everything is overtime
+		{0, 6 * time.Hour},
+		{0, 6 * time.Hour},
+	}
+	for i, w := range want {
+		d := result.Days[i]
+		if d.Target != w.target || d.Overtime != w.overtime {
+			t.Errorf("%s: target %v overtime %v, want %v and %v",
+				d.Workday.Date.Weekday(), d.Target, d.Overtime, w.target, w.overtime)
+		}
+	}
+	if result.Total != 9*time.Hour+30*time.Minute {
+		t.Errorf("total = %v", result.Total)
+	}
+}
+
+func TestCalculateContracts(t *testing.T) {
+	target := func(hours time.Duration) [7]time.Duration {
+		var targets [7]time.Duration
+		for d := time.Monday; d <= time.Friday; d++ {
+			targets[d] = hours
+		}
+		return targets
+	}
+	// Unsorted on purpose
+	contracts := []*db.Contract{
+		{ID: 2, ValidFrom: workday(0, "2025-07-01").Date, WeekHours: 32, Targets: target(6*time.Hour + 24*time.Minute)},
+		{ID: 1, ValidFrom: workday(0, "2025-01-01").Date, WeekHours: 40, Targets: target(8 * time.Hour)},
+	}
+
+	days := []*db.Workday{
+		// Before the first contract, the settings are used
+		workday(1, "2024-12-30"),
+		workday(2, "2025-06-30"),
+		workday(3, "2025-07-01"),
+		workday(4, "2025-08-04"),
+	}
+	var wts []*db.Worktime
+	for _, d := range days {
+		// 7h worked, 30min break
+		wts = append(wts, worktimes(d, "08:00:00", "15:30:00")...)
+	}
+
+	settings := model.NewSettings("", "")
+	settings.TargetHours = model.DefaultTargetHours(35)
+
+	result := NewCalculator(settings, contracts).Calculate(days, wts)
+
+	want := []time.Duration{0, -1 * time.Hour, 36 * time.Minute, 36 * time.Minute}
+	for i, w := range want {
+		if got := result.Days[i].Overtime; got != w {
+			t.Errorf("%s: overtime %v, want %v", result.Days[i].Workday.Date.Format(time.DateOnly), got, w)
+		}
+	}
+
+	// Changing the settings must not change the past
+	settings.TargetHours = model.DefaultTargetHours(20)
+	result = NewCalculator(settings, contracts).Calculate(days[1:], wts)
+	if result.Total != 12*time.Minute {
+		t.Errorf("total = %v, want 12m", result.Total)
+	}
+}
+
+func TestCalculateVacations(t *testing.T) {
+	vacations := []*db.Absence{
+		// Monday afternoon to Wednesday morning
+		{ID: 1, Type: db.AbsenceVacation, StartDate: workday(0, "2025-08-04").Date, EndDate: workday(0, "2025-08-06").Date,
+			HalfDayStart: true, HalfDayEnd: true},
+		// Friday
+		{ID: 2, Type: db.AbsenceVacation, StartDate: workday(0, "2025-08-08").Date, EndDate: workday(0, "2025-08-08").Date},
+	}
+
+	days := []*db.Workday{
+		workday(1, "2025-08-04"),
+		workday(2, "2025-08-05"),
+		workday(3, "2025-08-06"),
+		workday(4, "2025-08-07"),
+		workday(5, "2025-08-08"),
+	}
+	var wts []*db.Worktime
+	wts = append(wts, worktimes(days[0], "08:00:00", "12:00:00")...)
+	// Briefly logged in during the vacation
+	wts = append(wts, worktimes(days[1], "10:00:00", "10:15:00")...)
+	wts = append(wts, worktimes(days[2], "12:00:00", "16:00:00")...)
+	wts = append(wts, worktimes(days[3], "08:00:00", "16:30:00")...)
+
+	settings := model.NewSettings("", "")
+	calc := NewCalculator(settings, nil)
+	calc.Absences = vacations
+	result := calc.Calculate(days, wts)
+
+	want := []struct {
+		credit   time.Duration
+		overtime time.Duration
+	}{
+		{4 * time.Hour, 0},
+		{8 * time.Hour, 15 * time.Minute},
+		{4 * time.Hour, 0},
+		{0, 0},
+		{8 * time.Hour, 0},
+	}
+	for i, w := range want {
+		d := result.Days[i]
+		if d.Credit != w.credit || d.Overtime != w.overtime {
+			t.Errorf("%s: credit %v overtime %v, want %v and %v",
+				d.Workday.Date.Format(time.DateOnly), d.Credit, d.Overtime, w.credit, w.overtime)
+		}
+	}
+}
+
+func TestVacationDayShare(t *testing.T) {
+	v := &db.Absence{
+		StartDate:    workday(0, "2025-12-22").Date,
+		EndDate:      workday(0, "2025-12-24").Date,
+		HalfDayStart: false,
+		HalfDayEnd:   true,
+	}
+	tests := []struct {
+		date string
+		want float64
+	}{
+		{"2025-12-21", 0},
+		{"2025-12-22", 1},
+		{"2025-12-23", 1},
+		{"2025-12-24", 0.5},
+		{"2025-12-25", 0},
+	}
+	for _, tt := range tests {
+		if got := v.DayShare(workday(0, tt.date).Date); got != tt.want {
+			t.Errorf("DayShare(%s) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultTargetHours(t *testing.T) {
+	tests := []struct {
+		weekHours int
+		want      time.Duration
+	}{
+		{40, 8 * time.Hour},
+		{38, 7*time.Hour + 36*time.Minute},
+		{0, 0},
+	}
+
+	for _, tt := range tests {
+		settings := model.NewSettings("", "")
+		settings.TargetHours = model.DefaultTargetHours(float64(tt.weekHours))
+
+		// 2025-08-04 is a Monday, 2025-08-10 a Sunday
+		monday := time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)
+		if got := settings.TargetFor(monday); got != tt.want {
+			t.Errorf("%dh week: target on monday = %v, want %v", tt.weekHours, got, tt.want)
+		}
+		if got := settings.TargetFor(monday.AddDate(0, 0, 6)); got != 0 {
+			t.Errorf("%dh week: target on sunday = %v, want 0", tt.weekHours, got)
+		}
+	}
+}
+
+func TestCalculateAbsenceTypes(t *testing.T) {
+	absence := func(absenceType db.AbsenceType, start string, end string) *db.Absence {
+		return &db.Absence{Type: absenceType, StartDate: workday(0, start).Date, EndDate: workday(0, end).Date}
+	}
+	absences := []*db.Absence{
+		absence(db.AbsenceSick, "2025-08-04", "2025-08-04"),
+		absence(db.AbsenceBusinessTrip, "2025-08-05", "2025-08-05"),
+		absence(db.AbsenceTraining, "2025-08-06", "2025-08-06"),
+		absence(db.AbsenceParental, "2025-08-07", "2025-08-07"),
+		// Friday with a workday, Monday without
+		absence(db.AbsenceCompTime, "2025-08-08", "2025-08-11"),
+		// Not taken yet
+		absence(db.AbsenceCompTime, "2999-01-01", "2999-01-01"),
+	}
+
+	days := []*db.Workday{
+		workday(1, "2025-08-04"),
+		workday(2, "2025-08-05"),
+		workday(3, "2025-08-06"),
+		workday(4, "2025-08-07"),
+		workday(5, "2025-08-08"),
+		workday(6, "2025-08-12"),
+	}
+	var wts []*db.Worktime
+	wts = append(wts, worktimes(days[1], "08:00:00", "14:30:00")...)
+	wts = append(wts, worktimes(days[2], "07:00:00", "16:00:00")...)
+	wts = append(wts, worktimes(days[5], "08:00:00", "16:30:00")...)
+
+	calc := NewCalculator(model.NewSettings("", ""), nil)
+	calc.Absences = absences
+	result := calc.Calculate(days, wts)
+
+	want := []struct {
+		target   time.Duration
+		credit   time.Duration
+		overtime time.Duration
+		balance  time.Duration
+	}{
+		// Sick days are credited
+		{8 * time.Hour, 8 * time.Hour, 0, 0},
+		// A business trip is topped up to the target
+		{8 * time.Hour, 2 * time.Hour, 0, 0},
+		// Time beyond the target is still overtime
+		{8 * time.Hour, 0, 15 * time.Minute, 15 * time.Minute},
+		// There is no target during parental leave
+		{0, 0, 0, 15 * time.Minute},
+		// Compensation days consume the target from the overtime
+		{8 * time.Hour, 0, -8 * time.Hour, -7*time.Hour - 45*time.Minute},
+		// Including the Monday without a workday
+		{8 * time.Hour, 0, 0, -15*time.Hour - 45*time.Minute},
+	}
+	for i, w := range want {
+		d := result.Days[i]
+		if d.Target != w.target || d.Credit != w.credit || d.Overtime != w.overtime || d.Balance != w.balance {
+			t.Errorf("%s: target %v credit %v overtime %v balance %v, want %v %v %v %v",
+				d.Workday.Date.Format(time.DateOnly), d.Target, d.Credit, d.Overtime, d.Balance,
+				w.target, w.credit, w.overtime, w.balance)
+		}
+	}
+	if result.CompTime != 8*time.Hour || result.Total != -15*time.Hour-45*time.Minute {
+		t.Errorf("comp time %v total %v", result.CompTime, result.Total)
+	}
+}
+
+func TestRecalculate(t *testing.T) {
+	days := []*db.Workday{
+		workday(1, "2025-08-04"),
+		workday(2, "2025-08-05"),
+		workday(3, "2025-08-07"),
+		workday(4, "2025-08-12"),
+	}
+	var wts []*db.Worktime
+	wts = append(wts, worktimes(days[0], "08:00:00", "17:00:00")...)
+	wts = append(wts, worktimes(days[1], "08:00:00", "16:30:00")...)
+	wts = append(wts, worktimes(days[2], "07:00:00", "17:00:00")...)
+	wts = append(wts, worktimes(days[3], "08:00:00", "12:00:00")...)
+
+	calc := NewCalculator(model.NewSettings("", ""), nil)
+	// Compensation days without a workday before and after the changes
+	calc.Absences = []*db.Absence{
+		{Type: db.AbsenceCompTime, StartDate: days[0].Date.AddDate(0, 0, 2), EndDate: days[0].Date.AddDate(0, 0, 2)},
+		{Type: db.AbsenceCompTime, StartDate: days[0].Date.AddDate(0, 0, 7), EndDate: days[0].Date.AddDate(0, 0, 7)},
+	}
+	previous := calc.Calculate(days, wts)
+
+	longer := append(worktimes(days[1], "07:00:00", "18:00:00"), wts[4:]...)
+	longer = append(wts[:2:2], longer...)
+	tests := []struct {
+		name      string
+		from      *db.Workday
+		workdays  []*db.Workday
+		worktimes []*db.Worktime
+	}{
+		{"changed day", days[1], days, longer},
+		{"added day", days[3], append(days[:4:4], workday(5, "2025-08-13")), wts},
+		{"deleted day", days[2], []*db.Workday{days[0], days[1], days[3]}, wts},
+		{"nothing kept", days[0], days, longer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calc.Recalculate(previous, tt.from.Date, tt.workdays, tt.worktimes)
+			want := calc.Calculate(tt.workdays, tt.worktimes)
+
+			if len(got.Days) != len(want.Days) {
+				t.Fatalf("got %d days, want %d", len(got.Days), len(want.Days))
+			}
+			for i, d := range got.Days {
+				w := want.Days[i]
+				if d.Workday.ID != w.Workday.ID || d.Overtime != w.Overtime || d.Balance != w.Balance {
+					t.Errorf("day %d: workday %d overtime %v balance %v, want %d %v %v",
+						i, d.Workday.ID, d.Overtime, d.Balance, w.Workday.ID, w.Overtime, w.Balance)
+				}
+			}
+			if got.Total != want.Total || got.CompTime != want.CompTime {
+				t.Errorf("total %v comp time %v, want %v %v", got.Total, got.CompTime, want.Total, want.CompTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+type ComplianceRule string
+
+const (
+	// More worked time on a day than allowed
+	RuleMaxDailyWork ComplianceRule = "maxDailyWork"
+	// Too little rest between the last End of a day and the first Begin of the next
+	RuleMinRest ComplianceRule = "minRest"
+	// Worked on a Sunday
+	RuleSundayWork ComplianceRule = "sundayWork"
+	// A single segment is too long without a break
+	RuleMissingBreak ComplianceRule = "missingBreak"
+)
+
+// ComplianceLimits are the limits of the working-time rules
+type ComplianceLimits struct {
+	MaxDailyWork time.Duration
+	MinRest      time.Duration
+	// Longest segment without a break
+	MaxSegment time.Duration
+}
+
+// Limits of the german working-time law, which are common in other countries as well
+var DefaultComplianceLimits = ComplianceLimits{
+	MaxDailyWork: 10 * time.Hour,
+	MinRest:      11 * time.Hour,
+	MaxSegment:   6 * time.Hour,
+}
+
+// ComplianceWarning is a violated rule on a workday
+type ComplianceWarning struct {
+	Workday *db.Workday
+	Rule    ComplianceRule
+	// Worked time, rest or segment length which violates the limit, 0 for Sunday work
+	Value time.Duration
+	Limit time.Duration
+}
+
+/*
+Checks the closed segments of the workdays against the rules. The warnings
+are sorted by date, the rest is reported on the day after the short rest.
+*/
+func CheckCompliance(workdays []*db.Workday, worktimes []*db.Worktime, limits ComplianceLimits) []*ComplianceWarning {
+	byWorkday := make(map[int64][]*db.Worktime)
+	for _, wt := range worktimes {
+		byWorkday[wt.Workday.ID] = append(byWorkday[wt.Workday.ID], wt)
+	}
+
+	days := make([]*db.Workday, len(workdays))
+	copy(days, workdays)
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
+	var warnings []*ComplianceWarning
+	var lastEnd time.Time
+	for _, wd := range days {
+		wts := closedWorktimes(byWorkday[wd.ID])
+		if len(wts) == 0 {
+			continue
+		}
+
+		if !lastEnd.IsZero() {
+			if rest := wts[0].Time.Sub(lastEnd); rest < limits.MinRest {
+				warnings = append(warnings, &ComplianceWarning{Workday: wd, Rule: RuleMinRest, Value: rest, Limit: limits.MinRest})
+			}
+		}
+		lastEnd = wts[len(wts)-1].Time
+
+		if wd.Date.Weekday() == time.Sunday {
+			warnings = append(warnings, &ComplianceWarning{Workday: wd, Rule: RuleSundayWork})
+		}
+
+		var worked, longest time.Duration
+		for i := 0; i+1 < len(wts); i += 2 {
+			segment := wts[i+1].Time.Sub(wts[i].Time)
+			worked += segment
+			longest = max(longest, segment)
+		}
+		if worked > limits.MaxDailyWork {
+			warnings = append(warnings, &ComplianceWarning{Workday: wd, Rule: RuleMaxDailyWork, Value: worked, Limit: limits.MaxDailyWork})
+		}
+		if longest > limits.MaxSegment {
+			warnings = append(warnings, &ComplianceWarning{Workday: wd, Rule: RuleMissingBreak, Value: longest, Limit: limits.MaxSegment})
+		}
+	}
+	return warnings
+}
+
+// Groups the warnings by workday id
+func WarningsByWorkday(warnings []*ComplianceWarning) map[int64][]*ComplianceWarning {
+	byWorkday := make(map[int64][]*ComplianceWarning)
+	for _, w := range warnings {
+		byWorkday[w.Workday.ID] = append(byWorkday[w.Workday.ID], w)
+	}
+	return byWorkday
+}
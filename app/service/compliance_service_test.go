@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+func TestCheckCompliance(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	type day struct {
+		day    int
+		clocks []string
+	}
+	tests := []struct {
+		name  string
+		days  []day
+		rules []ComplianceRule
+	}{
+		{"regular day", []day{{4, []string{"08:00", "12:00", "12:30", "16:30"}}}, nil},
+		{"too long day", []day{{4, []string{"07:00", "12:00", "12:30", "18:00"}}}, []ComplianceRule{RuleMaxDailyWork}},
+		{"segment without break", []day{{4, []string{"08:00", "14:30"}}}, []ComplianceRule{RuleMissingBreak}},
+		{"short rest", []day{
+			{4, []string{"12:00", "16:00", "16:30", "22:00"}},
+			{5, []string{"07:00", "11:00"}},
+		}, []ComplianceRule{RuleMinRest}},
+		{"sunday", []day{{3, []string{"10:00", "12:00"}}}, []ComplianceRule{RuleSundayWork}},
+		{"open entry is ignored", []day{{4, []string{"08:00", "12:00", "12:30"}}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var workdays []*db.Workday
+			var worktimes []*db.Worktime
+			for _, d := range tt.days {
+				wd := &db.Workday{ID: int64(d.day), Date: time.Date(2025, 8, d.day, 0, 0, 0, 0, loc)}
+				workdays = append(workdays, wd)
+				for i, clock := range d.clocks {
+					c, err := time.Parse("15:04", clock)
+					if err != nil {
+						t.Fatal(err)
+					}
+					typ := "Begin"
+					if i%2 == 1 {
+						typ = "End"
+					}
+					worktimes = append(worktimes, &db.Worktime{
+						Type:    typ,
+						Time:    time.Date(2025, 8, d.day, c.Hour(), c.Minute(), 0, 0, loc),
+						Workday: db.Workday{ID: wd.ID},
+					})
+				}
+			}
+
+			warnings := CheckCompliance(workdays, worktimes, DefaultComplianceLimits)
+			if len(warnings) != len(tt.rules) {
+				t.Fatalf("got %d warnings, want %d", len(warnings), len(tt.rules))
+			}
+			for i, w := range warnings {
+				if w.Rule != tt.rules[i] {
+					t.Errorf("warning %d is %s, want %s", i, w.Rule, tt.rules[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/xuri/excelize/v2"
+)
+
+// Version of the JSON export, increased on incompatible changes
+const ExportSchemaVersion = 1
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+var ExportFormats = []ExportFormat{ExportCSV, ExportJSON, ExportXLSX}
+
+// Export is the worktime of a date range, it is written as JSON as is
+type Export struct {
+	Version int `json:"version"`
+	// First and last date of the range as YYYY-MM-DD
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Days []*ExportDay `json:"days"`
+	// Only filled if the correction history is exported
+	Corrections []*ExportCorrection `json:"corrections,omitempty"`
+}
+
+// ExportDay is a workday or a day of absence
+type ExportDay struct {
+	// YYYY-MM-DD
+	Date     string           `json:"date"`
+	Segments []*ExportSegment `json:"segments"`
+
+	WorkedMinutes   int64 `json:"worked_minutes"`
+	BreakMinutes    int64 `json:"break_minutes"`
+	OvertimeMinutes int64 `json:"overtime_minutes"`
+
+	Absences []*ExportAbsence `json:"absences"`
+}
+
+// ExportSegment is a closed Begin/End pair as HH:MM
+type ExportSegment struct {
+	Begin string `json:"begin"`
+	End   string `json:"end"`
+}
+
+type ExportAbsence struct {
+	Type db.AbsenceType `json:"type"`
+	// 1 for a full day, 0.5 for a half day
+	Share float64 `json:"share"`
+}
+
+// ExportCorrection is a manual change of the time entries of a day
+type ExportCorrection struct {
+	// YYYY-MM-DD of the changed day
+	Date string `json:"date"`
+	// When the change was made as RFC 3339
+	ChangedAt string         `json:"changed_at"`
+	Action    db.AuditAction `json:"action"`
+	// Time entries before and after the change, e.g. Begin 08:00, End 16:30
+	Old    string        `json:"old"`
+	New    string        `json:"new"`
+	Reason string        `json:"reason"`
+	State  db.AuditState `json:"state"`
+}
+
+// ExportLocale formats dates and numbers of CSV and XLSX exports
+type ExportLocale struct {
+	DateFormat string
+	// Separates the integer and the fraction of hours
+	Decimal string
+	// Separates the CSV columns
+	Separator rune
+}
+
+// Returns the formatting of a locale like de-DE or en-US, unknown locales are formatted like en
+func ExportLocaleFor(locale string) ExportLocale {
+	language, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	language = strings.ToLower(language)
+
+	l := ExportLocale{DateFormat: "02/01/2006", Decimal: ".", Separator: ','}
+	switch language {
+	case "de", "cs", "pl", "ru", "tr", "uk":
+		l.DateFormat = "02.01.2006"
+	case "ja", "ko", "zh", "sv":
+		l.DateFormat = "2006-01-02"
+	case "nl":
+		l.DateFormat = "02-01-2006"
+	case "en":
+		if region == "" || strings.EqualFold(region, "US") {
+			l.DateFormat = "01/02/2006"
+		}
+	}
+	switch language {
+	case "en", "ja", "ko", "zh", "hi", "":
+	default:
+		// A comma separates the fraction, so spreadsheets expect semicolons
+		l.Decimal = ","
+		l.Separator = ';'
+	}
+	return l
+}
+
+// Formats a duration as decimal hours, e.g. 7,50
+func (l ExportLocale) Hours(d time.Duration) string {
+	return strings.Replace(strconv.FormatFloat(d.Hours(), 'f', 2, 64), ".", l.Decimal, 1)
+}
+
+// ExportService writes the worktime for payroll and other tools
+type ExportService struct {
+	ts *TimeEntryService
+}
+
+func NewExportService(repo *repo.SQLiteRepository) *ExportService {
+	return &ExportService{ts: NewTimeEntryService(repo)}
+}
+
+/*
+Collects the workdays and absences between both dates including. The
+overtime is calculated over all workdays, so it matches the timetable.
+Open entries of today are not exported.
+*/
+func (es *ExportService) Collect(settings *model.Settings, from time.Time, to time.Time) (*Export, error) {
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	export := &Export{Version: ExportSchemaVersion, From: first, To: last, Days: []*ExportDay{}}
+
+	workdays, worktimes, byWorkday, err := es.ts.loadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	calc := es.ts.NewCalculator(settings)
+	result := calc.Calculate(workdays, worktimes)
+
+	days := make(map[string]*ExportDay)
+	for _, r := range result.Days {
+		date := r.Workday.Date.Format(time.DateOnly)
+		if date < first || date > last {
+			continue
+		}
+		day := &ExportDay{
+			Date:            date,
+			Segments:        exportSegments(byWorkday[r.Workday.ID]),
+			WorkedMinutes:   int64(r.Worktime / time.Minute),
+			BreakMinutes:    int64(r.Breaktime / time.Minute),
+			OvertimeMinutes: int64(r.Overtime / time.Minute),
+			Absences:        []*ExportAbsence{},
+		}
+		days[date] = day
+		export.Days = append(export.Days, day)
+	}
+
+	today := dateOnly(time.Now())
+	for _, a := range calc.Absences {
+		for d := dateOnly(a.StartDate); !d.After(dateOnly(a.EndDate)); d = d.AddDate(0, 0, 1) {
+			date := d.Format(time.DateOnly)
+			target := calc.TargetFor(d)
+			if date < first || date > last || target == 0 {
+				continue
+			}
+
+			day, ok := days[date]
+			if !ok {
+				day = &ExportDay{Date: date, Segments: []*ExportSegment{}, Absences: []*ExportAbsence{}}
+				// Compensation days without a workday consume overtime once they are reached
+				if !d.After(today) {
+					share := calc.AbsenceShare(d, AbsenceEffectCompTime)
+					day.OvertimeMinutes = -int64(time.Duration(float64(target)*share) / time.Minute)
+				}
+				days[date] = day
+				export.Days = append(export.Days, day)
+			}
+			day.Absences = append(day.Absences, &ExportAbsence{Type: a.Type, Share: a.DayShare(d)})
+		}
+	}
+
+	sort.SliceStable(export.Days, func(i, j int) bool {
+		return export.Days[i].Date < export.Days[j].Date
+	})
+	return export, nil
+}
+
+// Writes the export in the given format
+func WriteExport(w io.Writer, format ExportFormat, export *Export, locale ExportLocale) error {
+	switch format {
+	case ExportCSV:
+		return WriteExportCSV(w, export, locale)
+	case ExportJSON:
+		return WriteExportJSON(w, export)
+	case ExportXLSX:
+		return WriteExportXLSX(w, export, locale)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedFile, format)
+}
+
+// Writes the export as indented JSON, the schema does not depend on the locale
+func WriteExportJSON(w io.Writer, export *Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
+
+// Converts a date layout like 02.01.2006 to the number format dd.mm.yyyy of spreadsheets
+var excelDateFormat = strings.NewReplacer("2006", "yyyy", "01", "mm", "02", "dd")
+
+// Columns of the CSV and XLSX export
+var exportHeader = []string{"date", "segments", "worked hours", "break hours", "overtime hours", "absences"}
+
+// Columns of the correction history, it follows the days in CSV and has its own XLSX sheet
+var correctionHeader = []string{"date", "changed at", "action", "old", "new", "reason", "state"}
+
+// Writes the export as CSV with one row per day
+func WriteExportCSV(w io.Writer, export *Export, locale ExportLocale) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = locale.Separator
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+	for _, day := range export.Days {
+		date, err := time.Parse(time.DateOnly, day.Date)
+		if err != nil {
+			return err
+		}
+		err = writer.Write([]string{
+			date.Format(locale.DateFormat),
+			formatSegments(day.Segments),
+			locale.Hours(time.Duration(day.WorkedMinutes) * time.Minute),
+			locale.Hours(time.Duration(day.BreakMinutes) * time.Minute),
+			locale.Hours(time.Duration(day.OvertimeMinutes) * time.Minute),
+			formatExportAbsences(day.Absences),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(export.Corrections) > 0 {
+		if err := writer.Write(nil); err != nil {
+			return err
+		}
+		if err := writer.Write(correctionHeader); err != nil {
+			return err
+		}
+		for _, c := range export.Corrections {
+			row, err := correctionRow(c, locale)
+			if err != nil {
+				return err
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Returns the columns of a correction with localized date and time
+func correctionRow(c *ExportCorrection, locale ExportLocale) ([]string, error) {
+	date, err := time.Parse(time.DateOnly, c.Date)
+	if err != nil {
+		return nil, err
+	}
+	changedAt, err := time.Parse(time.RFC3339, c.ChangedAt)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		date.Format(locale.DateFormat),
+		changedAt.Format(locale.DateFormat + " 15:04"),
+		string(c.Action),
+		c.Old,
+		c.New,
+		c.Reason,
+		string(c.State),
+	}, nil
+}
+
+// Writes the export as XLSX, dates and hours are typed cells which the spreadsheet formats
+func WriteExportXLSX(w io.Writer, export *Export, locale ExportLocale) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	if err := f.SetSheetRow(sheet, "A1", &exportHeader); err != nil {
+		return err
+	}
+	dateFormat := excelDateFormat.Replace(locale.DateFormat)
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return err
+	}
+	hoursFormat := "0.00"
+	hoursStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &hoursFormat})
+	if err != nil {
+		return err
+	}
+
+	for i, day := range export.Days {
+		date, err := time.Parse(time.DateOnly, day.Date)
+		if err != nil {
+			return err
+		}
+		row := []any{
+			date,
+			formatSegments(day.Segments),
+			(time.Duration(day.WorkedMinutes) * time.Minute).Hours(),
+			(time.Duration(day.BreakMinutes) * time.Minute).Hours(),
+			(time.Duration(day.OvertimeMinutes) * time.Minute).Hours(),
+			formatExportAbsences(day.Absences),
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	last := len(export.Days) + 1
+	if err := f.SetCellStyle(sheet, "A2", fmt.Sprintf("A%d", last), dateStyle); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "C2", fmt.Sprintf("E%d", last), hoursStyle); err != nil {
+		return err
+	}
+
+	if len(export.Corrections) > 0 {
+		const corrections = "corrections"
+		if _, err := f.NewSheet(corrections); err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(corrections, "A1", &correctionHeader); err != nil {
+			return err
+		}
+		for i, c := range export.Corrections {
+			row, err := correctionRow(c, locale)
+			if err != nil {
+				return err
+			}
+			cell, err := excelize.CoordinatesToCellName(1, i+2)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(corrections, cell, &row); err != nil {
+				return err
+			}
+		}
+	}
+	return f.Write(w)
+}
+
+/*
+Reads a JSON export as rows for the importer: a header followed by one
+row per segment with the date as YYYY-MM-DD and the times as HH:MM and
+one row per day with absences.
+*/
+func ReadExportJSON(r io.Reader) ([][]string, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+	if export.Version != ExportSchemaVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	records := [][]string{{"date", "begin", "end", "pause", "pause start", "absence"}}
+	for _, day := range export.Days {
+		for _, s := range day.Segments {
+			records = append(records, []string{day.Date, s.Begin, s.End, "", "", ""})
+		}
+		if len(day.Absences) > 0 {
+			records = append(records, []string{day.Date, "", "", "", "", formatExportAbsences(day.Absences)})
+		}
+	}
+	return records, nil
+}
+
+// Pairs the sorted worktimes, an open Begin is left out
+func exportSegments(worktimes []*db.Worktime) []*ExportSegment {
+	sorted := closedWorktimes(worktimes)
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	segments := []*ExportSegment{}
+	for i := 0; i+1 < len(sorted); i += 2 {
+		segments = append(segments, &ExportSegment{
+			Begin: sorted[i].Time.In(loc).Format("15:04"),
+			End:   sorted[i+1].Time.In(loc).Format("15:04"),
+		})
+	}
+	return segments
+}
+
+// Formats segments like 08:00-12:00 12:30-16:30
+func formatSegments(segments []*ExportSegment) string {
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = s.Begin + "-" + s.End
+	}
+	return strings.Join(parts, " ")
+}
+
+// Formats absences like vacation or sick (half day)
+func formatExportAbsences(absences []*ExportAbsence) string {
+	parts := make([]string, len(absences))
+	for i, a := range absences {
+		parts[i] = string(a.Type)
+		if a.Share < 1 {
+			parts[i] += " (half day)"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
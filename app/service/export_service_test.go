@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportLocaleFor(t *testing.T) {
+	tests := []struct {
+		locale string
+		date   string
+		hours  string
+		sep    rune
+	}{
+		{"de-DE", "04.08.2025", "7,50", ';'},
+		{"en-US", "08/04/2025", "7.50", ','},
+		{"en-GB", "04/08/2025", "7.50", ','},
+		{"fr_FR", "04/08/2025", "7,50", ';'},
+		{"ja", "2025-08-04", "7.50", ','},
+		{"", "04/08/2025", "7.50", ','},
+	}
+
+	date := time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			l := ExportLocaleFor(tt.locale)
+			if got := date.Format(l.DateFormat); got != tt.date {
+				t.Errorf("date = %s, want %s", got, tt.date)
+			}
+			if got := l.Hours(7*time.Hour + 30*time.Minute); got != tt.hours {
+				t.Errorf("Hours() = %s, want %s", got, tt.hours)
+			}
+			if l.Separator != tt.sep {
+				t.Errorf("Separator = %q, want %q", l.Separator, tt.sep)
+			}
+		})
+	}
+}
+
+// Adds a workday with the given Begin/End times as HH:MM
+func addTestWorkday(t *testing.T, r *repo.SQLiteRepository, date time.Time, clocks ...string) {
+	t.Helper()
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	wd, err := r.AddWorkday(&db.Workday{Date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clocks {
+		at, err := time.ParseInLocation(time.DateTime, date.Format(time.DateOnly)+" "+c+":00", loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wtType := "Begin"
+		if i%2 != 0 {
+			wtType = "End"
+		}
+		if _, err := r.AddWorktime(&db.Worktime{Type: wtType, Time: at, Workday: *wd}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	r := repotest.NewRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	day := func(d int) time.Time { return time.Date(2025, 8, d, 0, 0, 0, 0, loc) }
+
+	addTestWorkday(t, r, day(4), "08:00", "12:00", "12:30", "16:30")
+	addTestWorkday(t, r, day(5), "08:00", "17:00")
+	// Outside of the range
+	addTestWorkday(t, r, day(11), "08:00", "16:00")
+	_, err := r.AddAbsence(&db.Absence{Type: db.AbsenceVacation, StartDate: day(6), EndDate: day(7), HalfDayEnd: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := model.NewSettings("", "")
+	settings.BreakPolicy = model.BreakPolicyNone
+	settings.BreakRules = nil
+	export, err := NewExportService(r).Collect(settings, day(1), day(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(export.Days) != 4 {
+		t.Fatalf("exported %d days, want 4", len(export.Days))
+	}
+	first := export.Days[0]
+	if first.Date != "2025-08-04" || len(first.Segments) != 2 || first.WorkedMinutes != 8*60 || first.BreakMinutes != 30 {
+		t.Errorf("unexpected first day %+v", first)
+	}
+	if first.OvertimeMinutes != 0 {
+		t.Errorf("overtime = %d, want 0", first.OvertimeMinutes)
+	}
+	if vacation := export.Days[3]; vacation.Date != "2025-08-07" || len(vacation.Absences) != 1 || vacation.Absences[0].Share != 0.5 {
+		t.Errorf("unexpected vacation day %+v", vacation)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteExport(&csv, ExportCSV, export, ExportLocaleFor("de-DE")); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 5 || lines[1] != "04.08.2025;08:00-12:00 12:30-16:30;8,00;0,50;0,00;" {
+		t.Errorf("unexpected CSV %q", lines)
+	}
+
+	var xlsx bytes.Buffer
+	if err := WriteExport(&xlsx, ExportXLSX, export, ExportLocaleFor("de-DE")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&xlsx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if v, _ := f.GetCellValue(f.GetSheetName(0), "A2"); v != "04.08.2025" {
+		t.Errorf("XLSX date = %s, want 04.08.2025", v)
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	r := repotest.NewRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	day := func(d int) time.Time { return time.Date(2025, 8, d, 0, 0, 0, 0, loc) }
+	addTestWorkday(t, r, day(4), "08:00", "12:00", "12:30", "16:30")
+	addTestWorkday(t, r, day(5), "07:15", "15:45")
+	// Absences with and without a workday of the same day
+	for _, a := range []*db.Absence{
+		{Type: db.AbsenceSick, StartDate: day(5), EndDate: day(5), HalfDayStart: true},
+		{Type: db.AbsenceVacation, StartDate: day(6), EndDate: day(7), HalfDayEnd: true},
+	} {
+		if _, err := r.AddAbsence(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settings := model.NewSettings("", "")
+	export, err := NewExportService(r).Collect(settings, day(1), day(31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, ExportJSON, export, ExportLocaleFor("de")); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	records, err := ReadImportFile("export.json", strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := repotest.NewRepository(t)
+	is := NewImporterService(target)
+	report, err := is.Import(records, GuessImportMapping(records), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(ImportRowOK) != 6 || len(report.Skipped()) != 0 {
+		t.Fatalf("imported %d rows, skipped %v", report.Count(ImportRowOK), report.Skipped())
+	}
+
+	again, err := NewExportService(target).Collect(settings, day(1), day(31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := WriteExport(&buf, ExportJSON, again, ExportLocaleFor("de")); err != nil {
+		t.Fatal(err)
+	}
+	if absences, _ := target.GetAllAbsence(); len(absences) != 2 {
+		t.Errorf("imported %d absences, want 2", len(absences))
+	}
+	if buf.String() != data {
+		t.Errorf("round trip changed the export:\n%s\nwant:\n%s", buf.String(), data)
+	}
+}
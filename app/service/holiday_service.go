@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/charmbracelet/log"
+)
+
+// Supported holiday regions, an empty region means no built-in holidays
+var HolidayRegions = []string{
+	"",
+	"DE",
+	"DE-BW", "DE-BY", "DE-BE", "DE-BB", "DE-HB", "DE-HH", "DE-HE", "DE-MV",
+	"DE-NI", "DE-NW", "DE-RP", "DE-SL", "DE-SN", "DE-ST", "DE-SH", "DE-TH",
+	"AT",
+	"CH",
+}
+
+var holidayRegionNames = map[string]string{
+	"DE":    "Deutschland",
+	"DE-BW": "Deutschland - Baden-Württemberg",
+	"DE-BY": "Deutschland - Bayern",
+	"DE-BE": "Deutschland - Berlin",
+	"DE-BB": "Deutschland - Brandenburg",
+	"DE-HB": "Deutschland - Bremen",
+	"DE-HH": "Deutschland - Hamburg",
+	"DE-HE": "Deutschland - Hessen",
+	"DE-MV": "Deutschland - Mecklenburg-Vorpommern",
+	"DE-NI": "Deutschland - Niedersachsen",
+	"DE-NW": "Deutschland - Nordrhein-Westfalen",
+	"DE-RP": "Deutschland - Rheinland-Pfalz",
+	"DE-SL": "Deutschland - Saarland",
+	"DE-SN": "Deutschland - Sachsen",
+	"DE-ST": "Deutschland - Sachsen-Anhalt",
+	"DE-SH": "Deutschland - Schleswig-Holstein",
+	"DE-TH": "Deutschland - Thüringen",
+	"AT":    "Österreich",
+	"CH":    "Schweiz",
+}
+
+// Returns the display name of a region, the region itself if it is unknown
+func HolidayRegionName(region string) string {
+	if name, ok := holidayRegionNames[region]; ok {
+		return name
+	}
+	return region
+}
+
+// HolidayCalendar combines the built-in holidays of a region with imported holidays
+type HolidayCalendar struct {
+	Region string
+
+	// Holidays imported from an iCalendar file
+	Imported []*db.Holiday
+}
+
+func NewHolidayCalendar(region string, imported []*db.Holiday) *HolidayCalendar {
+	return &HolidayCalendar{
+		Region:   region,
+		Imported: imported,
+	}
+}
+
+// Returns all holidays of the year sorted by date
+func (hc *HolidayCalendar) Holidays(year int) []*db.Holiday {
+	if hc == nil {
+		return nil
+	}
+
+	holidays := regionHolidays(hc.Region, year)
+	for _, h := range hc.Imported {
+		if h.Date.Year() == year {
+			holidays = append(holidays, h)
+		}
+	}
+
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays
+}
+
+// Returns the holiday on the given date or nil
+func (hc *HolidayCalendar) HolidayOn(date time.Time) *db.Holiday {
+	day := date.Format(time.DateOnly)
+	for _, h := range hc.Holidays(date.Year()) {
+		if h.Date.Format(time.DateOnly) == day {
+			return h
+		}
+	}
+	return nil
+}
+
+// Calculates easter sunday with the anonymous gregorian algorithm
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func regionHolidays(region string, year int) []*db.Holiday {
+	country, state, _ := strings.Cut(region, "-")
+
+	easter := EasterSunday(year)
+	fixed := func(month time.Month, day int, name string) *db.Holiday {
+		return &db.Holiday{Date: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Name: name}
+	}
+	easterRelative := func(days int, name string) *db.Holiday {
+		return &db.Holiday{Date: easter.AddDate(0, 0, days), Name: name}
+	}
+	in := func(states ...string) bool {
+		for _, s := range states {
+			if s == state {
+				return true
+			}
+		}
+		return false
+	}
+
+	var holidays []*db.Holiday
+	switch country {
+	case "DE":
+		holidays = append(holidays,
+			fixed(time.January, 1, "Neujahr"),
+			easterRelative(-2, "Karfreitag"),
+			easterRelative(1, "Ostermontag"),
+			fixed(time.May, 1, "Tag der Arbeit"),
+			easterRelative(39, "Christi Himmelfahrt"),
+			easterRelative(50, "Pfingstmontag"),
+			fixed(time.October, 3, "Tag der Deutschen Einheit"),
+			fixed(time.December, 25, "1. Weihnachtstag"),
+			fixed(time.December, 26, "2. Weihnachtstag"),
+		)
+		if in("BW", "BY", "ST") {
+			holidays = append(holidays, fixed(time.January, 6, "Heilige Drei Könige"))
+		}
+		if (in("BE") && year >= 2019) || (in("MV") && year >= 2023) {
+			holidays = append(holidays, fixed(time.March, 8, "Internationaler Frauentag"))
+		}
+		if in("BB") {
+			holidays = append(holidays,
+				easterRelative(0, "Ostersonntag"),
+				easterRelative(49, "Pfingstsonntag"))
+		}
+		if in("BW", "BY", "HE", "NW", "RP", "SL") {
+			holidays = append(holidays, easterRelative(60, "Fronleichnam"))
+		}
+		if in("SL") {
+			holidays = append(holidays, fixed(time.August, 15, "Mariä Himmelfahrt"))
+		}
+		if in("TH") && year >= 2019 {
+			holidays = append(holidays, fixed(time.September, 20, "Weltkindertag"))
+		}
+		// Nationwide in 2017 for the 500th anniversary of the reformation
+		if year == 2017 || in("BB", "MV", "SN", "ST", "TH") ||
+			(in("HB", "HH", "NI", "SH") && year >= 2018) {
+			holidays = append(holidays, fixed(time.October, 31, "Reformationstag"))
+		}
+		if in("BW", "BY", "NW", "RP", "SL") {
+			holidays = append(holidays, fixed(time.November, 1, "Allerheiligen"))
+		}
+		if in("SN") {
+			// Wednesday before the 23rd of November
+			d := time.Date(year, time.November, 22, 0, 0, 0, 0, time.UTC)
+			for d.Weekday() != time.Wednesday {
+				d = d.AddDate(0, 0, -1)
+			}
+			holidays = append(holidays, &db.Holiday{Date: d, Name: "Buß- und Bettag"})
+		}
+	case "AT":
+		holidays = append(holidays,
+			fixed(time.January, 1, "Neujahr"),
+			fixed(time.January, 6, "Heilige Drei Könige"),
+			easterRelative(1, "Ostermontag"),
+			fixed(time.May, 1, "Staatsfeiertag"),
+			easterRelative(39, "Christi Himmelfahrt"),
+			easterRelative(50, "Pfingstmontag"),
+			easterRelative(60, "Fronleichnam"),
+			fixed(time.August, 15, "Mariä Himmelfahrt"),
+			fixed(time.October, 26, "Nationalfeiertag"),
+			fixed(time.November, 1, "Allerheiligen"),
+			fixed(time.December, 8, "Mariä Empfängnis"),
+			fixed(time.December, 25, "Christtag"),
+			fixed(time.December, 26, "Stefanitag"),
+		)
+	case "CH":
+		holidays = append(holidays,
+			fixed(time.January, 1, "Neujahr"),
+			easterRelative(-2, "Karfreitag"),
+			easterRelative(1, "Ostermontag"),
+			easterRelative(39, "Auffahrt"),
+			easterRelative(50, "Pfingstmontag"),
+			fixed(time.August, 1, "Bundesfeier"),
+			fixed(time.December, 25, "Weihnachtstag"),
+			fixed(time.December, 26, "Stephanstag"),
+		)
+	}
+
+	return holidays
+}
+
+/*
+Reads the holidays from an iCalendar file. Every day covered by a VEVENT
+becomes a holiday named by its SUMMARY. Recurrence rules are not supported.
+*/
+func ParseICalendar(r io.Reader) ([]*db.Holiday, error) {
+	// Unfold lines which are continued with a leading space or tab
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var holidays []*db.Holiday
+	var inEvent bool
+	var start, end time.Time
+	var summary string
+
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// Strip parameters like DTSTART;VALUE=DATE
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, summary = time.Time{}, time.Time{}, ""
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				log.Warn("Skipping event without start date", "summary", summary)
+				continue
+			}
+			// The end date is exclusive, events without end last one day
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				holidays = append(holidays, &db.Holiday{Date: d, Name: summary})
+			}
+		case !inEvent:
+			continue
+		case name == "DTSTART":
+			d, err := parseICalendarDate(value)
+			if err != nil {
+				return nil, err
+			}
+			start = d
+		case name == "DTEND":
+			d, err := parseICalendarDate(value)
+			if err != nil {
+				return nil, err
+			}
+			end = d
+		case name == "SUMMARY":
+			summary = unescapeICalendarText(value)
+		case name == "RRULE":
+			log.Warn("Recurring events are not supported", "rule", value)
+		}
+	}
+
+	if inEvent {
+		return nil, errors.New("unterminated VEVENT in iCalendar file")
+	}
+
+	return holidays, nil
+}
+
+// Parses dates like 20251224 or 20251224T000000Z, the time is dropped
+func parseICalendarDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("invalid iCalendar date " + value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func unescapeICalendarText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+func TestEasterSunday(t *testing.T) {
+	tests := []struct {
+		year int
+		want string
+	}{
+		{2000, "2000-04-23"},
+		{2008, "2008-03-23"},
+		{2019, "2019-04-21"},
+		{2024, "2024-03-31"},
+		{2025, "2025-04-20"},
+		{2026, "2026-04-05"},
+		{2038, "2038-04-25"},
+	}
+	for _, tt := range tests {
+		if got := EasterSunday(tt.year).Format(time.DateOnly); got != tt.want {
+			t.Errorf("EasterSunday(%d) = %s, want %s", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestRegionHolidays(t *testing.T) {
+	tests := []struct {
+		region    string
+		year      int
+		wantCount int
+		wantDates []string
+		notDates  []string
+	}{
+		{"", 2025, 0, nil, nil},
+		{"DE", 2025, 9,
+			[]string{"2025-01-01", "2025-04-18", "2025-04-21", "2025-05-29", "2025-06-09", "2025-10-03"},
+			[]string{"2025-10-31", "2025-01-06"}},
+		{"DE", 2017, 10, []string{"2017-10-31"}, nil},
+		{"DE-BY", 2025, 12, []string{"2025-01-06", "2025-06-19", "2025-11-01"}, []string{"2025-08-15"}},
+		{"DE-BE", 2025, 10, []string{"2025-03-08"}, nil},
+		{"DE-BE", 2018, 9, nil, []string{"2018-03-08"}},
+		{"DE-BB", 2025, 12, []string{"2025-04-20", "2025-06-08", "2025-10-31"}, nil},
+		{"DE-SN", 2025, 11, []string{"2025-10-31", "2025-11-19"}, nil},
+		{"DE-SN", 2026, 11, []string{"2026-11-18"}, nil},
+		{"DE-SL", 2025, 12, []string{"2025-08-15", "2025-11-01"}, nil},
+		{"DE-TH", 2025, 11, []string{"2025-09-20", "2025-10-31"}, nil},
+		{"DE-HH", 2025, 10, []string{"2025-10-31"}, nil},
+		{"AT", 2025, 13, []string{"2025-10-26", "2025-12-08", "2025-06-19"}, []string{"2025-04-18"}},
+		{"CH", 2025, 8, []string{"2025-08-01", "2025-05-29", "2025-04-18"}, []string{"2025-05-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region+"/"+time.Date(tt.year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006"), func(t *testing.T) {
+			hc := NewHolidayCalendar(tt.region, nil)
+			if got := len(hc.Holidays(tt.year)); got != tt.wantCount {
+				t.Errorf("got %d holidays, want %d", got, tt.wantCount)
+			}
+			for _, d := range tt.wantDates {
+				date, _ := time.Parse(time.DateOnly, d)
+				if hc.HolidayOn(date) == nil {
+					t.Errorf("%s should be a holiday", d)
+				}
+			}
+			for _, d := range tt.notDates {
+				date, _ := time.Parse(time.DateOnly, d)
+				if h := hc.HolidayOn(date); h != nil {
+					t.Errorf("%s should not be a holiday, got %s", d, h.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseICalendar(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20251224",
+		"DTEND;VALUE=DATE:20251227",
+		"SUMMARY:Betriebsferien\\, Weihnachten",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250815T000000Z",
+		"SUMMARY:Mariä Himmel",
+		" fahrt",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	holidays, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ date, name string }{
+		{"2025-12-24", "Betriebsferien, Weihnachten"},
+		{"2025-12-25", "Betriebsferien, Weihnachten"},
+		{"2025-12-26", "Betriebsferien, Weihnachten"},
+		{"2025-08-15", "Mariä Himmelfahrt"},
+	}
+	if len(holidays) != len(want) {
+		t.Fatalf("got %d holidays, want %d", len(holidays), len(want))
+	}
+	for i, w := range want {
+		if holidays[i].Date.Format(time.DateOnly) != w.date || holidays[i].Name != w.name {
+			t.Errorf("holiday %d = %s %q, want %s %q", i,
+				holidays[i].Date.Format(time.DateOnly), holidays[i].Name, w.date, w.name)
+		}
+	}
+
+	if _, err := ParseICalendar(strings.NewReader("BEGIN:VEVENT\nDTSTART:2025")); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
+
+func TestCalculateHolidays(t *testing.T) {
+	settings := model.NewSettings("", "")
+	calc := NewCalculator(settings, nil)
+	calc.Holidays = NewHolidayCalendar("DE-BY", []*db.Holiday{
+		{Date: workday(0, "2025-12-24").Date, Name: "Heiligabend"},
+	})
+
+	// Worked on whit monday, every minute is overtime
+	whitMonday := workday(1, "2025-06-09")
+	result := calc.Calculate([]*db.Workday{whitMonday}, worktimes(whitMonday, "08:00:00", "12:00:00"))
+	if d := result.Days[0]; d.Target != 0 || d.Overtime != 4*time.Hour {
+		t.Errorf("target %v overtime %v on a holiday", d.Target, d.Overtime)
+	}
+
+	tests := []struct {
+		name    string
+		absence *db.Absence
+		want    float64
+	}{
+		// Whit monday and the weekend are not counted
+		{"around whit monday", &db.Absence{
+			StartDate: workday(0, "2025-06-06").Date, EndDate: workday(0, "2025-06-13").Date}, 5},
+		// Imported holiday, christmas and the weekend are not counted
+		{"christmas", &db.Absence{
+			StartDate: workday(0, "2025-12-22").Date, EndDate: workday(0, "2026-01-02").Date}, 6},
+		{"half day", &db.Absence{
+			StartDate: workday(0, "2025-08-07").Date, EndDate: workday(0, "2025-08-08").Date, HalfDayEnd: true}, 1.5},
+	}
+	for _, tt := range tests {
+		if got := calc.AbsenceDays(tt.absence); got != tt.want {
+			t.Errorf("%s: got %v vacation days, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/godbus/dbus/v5"
+)
+
+// How often the idle time is checked
+const IdlePollInterval = 30 * time.Second
+
+var ErrIdleUnsupported = errors.New("idle detection is not supported on this system")
+
+// IdleSource reports how long the user has not used the machine
+type IdleSource interface {
+	IdleTime() (time.Duration, error)
+	Close() error
+}
+
+// IdleSpan is a period in which the user was away
+type IdleSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+/*
+IdleMonitor notices idle periods longer than the threshold. The span is
+reported once the user is back, so it can be recorded as a break.
+*/
+type IdleMonitor struct {
+	source    IdleSource
+	threshold time.Duration
+	// Start of the current idle period, zero while the user is active
+	idleSince time.Time
+}
+
+func NewIdleMonitor(source IdleSource, threshold time.Duration) *IdleMonitor {
+	return &IdleMonitor{source: source, threshold: threshold}
+}
+
+// Checks the idle time at the given time and returns the idle span which just ended or nil
+func (m *IdleMonitor) Poll(now time.Time) (*IdleSpan, error) {
+	idle, err := m.source.IdleTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if idle >= m.threshold {
+		if m.idleSince.IsZero() {
+			m.idleSince = now.Add(-idle).Truncate(time.Second)
+		}
+		return nil, nil
+	}
+	if m.idleSince.IsZero() {
+		return nil, nil
+	}
+
+	span := &IdleSpan{Start: m.idleSince, End: now.Add(-idle).Truncate(time.Second)}
+	m.idleSince = time.Time{}
+	return span, nil
+}
+
+func (m *IdleMonitor) Close() error {
+	return m.source.Close()
+}
+
+/*
+Returns the idle source of the desktop. GNOME reports the idle time on X11
+and Wayland via Mutter, other desktops set the idle hint of logind.
+*/
+func NewSystemIdleSource() (IdleSource, error) {
+	if source, err := newMutterIdleSource(); err == nil {
+		return source, nil
+	} else {
+		log.Debug("Mutter idle monitor is not available", "error", err)
+	}
+	if source, err := newLogindIdleSource(); err == nil {
+		return source, nil
+	} else {
+		log.Debug("logind idle hint is not available", "error", err)
+	}
+	return nil, ErrIdleUnsupported
+}
+
+// mutterIdleSource asks the idle monitor of GNOME on the session bus
+type mutterIdleSource struct {
+	conn *dbus.Conn
+	obj  dbus.BusObject
+}
+
+func newMutterIdleSource() (*mutterIdleSource, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	source := &mutterIdleSource{
+		conn: conn,
+		obj:  conn.Object("org.gnome.Mutter.IdleMonitor", "/org/gnome/Mutter/IdleMonitor/Core"),
+	}
+	if _, err := source.IdleTime(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *mutterIdleSource) IdleTime() (time.Duration, error) {
+	var ms uint64
+	if err := s.obj.Call("org.gnome.Mutter.IdleMonitor.GetIdletime", 0).Store(&ms); err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (s *mutterIdleSource) Close() error {
+	return s.conn.Close()
+}
+
+// logindIdleSource reads the idle hint of the session from logind on the system bus
+type logindIdleSource struct {
+	conn *dbus.Conn
+	obj  dbus.BusObject
+}
+
+func newLogindIdleSource() (*logindIdleSource, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	source := &logindIdleSource{
+		conn: conn,
+		obj:  conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto"),
+	}
+	if _, err := source.IdleTime(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *logindIdleSource) IdleTime() (time.Duration, error) {
+	hint, err := s.obj.GetProperty("org.freedesktop.login1.Session.IdleHint")
+	if err != nil {
+		return 0, err
+	}
+	if idle, ok := hint.Value().(bool); !ok || !idle {
+		return 0, nil
+	}
+
+	since, err := s.obj.GetProperty("org.freedesktop.login1.Session.IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+	// Microseconds since the epoch
+	usec, ok := since.Value().(uint64)
+	if !ok || usec == 0 {
+		return 0, nil
+	}
+	return time.Since(time.UnixMicro(int64(usec))), nil
+}
+
+func (s *logindIdleSource) Close() error {
+	return s.conn.Close()
+}
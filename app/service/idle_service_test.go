@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+// fakeIdleSource returns the configured idle time
+type fakeIdleSource struct {
+	idle time.Duration
+	err  error
+}
+
+func (f *fakeIdleSource) IdleTime() (time.Duration, error) {
+	return f.idle, f.err
+}
+
+func (f *fakeIdleSource) Close() error {
+	return nil
+}
+
+func TestIdleMonitor(t *testing.T) {
+	source := &fakeIdleSource{}
+	m := NewIdleMonitor(source, 10*time.Minute)
+	start := time.Date(2025, 8, 4, 10, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		at   time.Duration
+		idle time.Duration
+		want *IdleSpan
+	}{
+		{0, 0, nil},
+		// Short idle periods are ignored
+		{5 * time.Minute, 4 * time.Minute, nil},
+		{6 * time.Minute, 0, nil},
+		{20 * time.Minute, 12 * time.Minute, nil},
+		{40 * time.Minute, 32 * time.Minute, nil},
+		// Back for a minute
+		{45 * time.Minute, time.Minute, &IdleSpan{Start: start.Add(8 * time.Minute), End: start.Add(44 * time.Minute)}},
+		{50 * time.Minute, 0, nil},
+	}
+	for i, s := range steps {
+		source.idle = s.idle
+		span, err := m.Poll(start.Add(s.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (span == nil) != (s.want == nil) ||
+			(span != nil && (!span.Start.Equal(s.want.Start) || !span.End.Equal(s.want.End))) {
+			t.Errorf("step %d: got %+v, want %+v", i, span, s.want)
+		}
+	}
+
+	source.err = errors.New("bus closed")
+	if _, err := m.Poll(start.Add(time.Hour)); err == nil {
+		t.Error("expected the error of the source")
+	}
+}
+
+func TestRecordBreak(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int, min int) time.Time {
+		return time.Date(2025, 8, 4, hour, min, 0, 0, loc)
+	}
+
+	if err := ts.RecordBreak(at(12, 0), at(12, 30)); !errors.Is(err, ErrNotClockedIn) {
+		t.Errorf("break without entry: %v", err)
+	}
+	if _, err := ts.ClockIn(at(8, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.RecordBreak(at(7, 30), at(8, 30)); !errors.Is(err, ErrInvalidBreak) {
+		t.Errorf("break before the begin: %v", err)
+	}
+	if err := ts.RecordBreak(at(12, 0), at(12, 30)); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := ts.Status(model.NewSettings("", ""), at(13, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Worktimes) != 3 || status.Open == nil || !status.Open.Time.Equal(at(12, 30)) {
+		t.Errorf("unexpected entries %+v", status.Worktimes)
+	}
+
+	// The break is undone in one step
+	if _, err := ts.repo.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	status, err = ts.Status(model.NewSettings("", ""), at(13, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Worktimes) != 1 || status.Open == nil || !status.Open.Time.Equal(at(8, 0)) {
+		t.Errorf("undo left entries %+v", status.Worktimes)
+	}
+}
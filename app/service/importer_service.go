@@ -1 +1,525 @@
 package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrUnsupportedFile = errors.New("unsupported file type")
+
+// Date and time formats offered for the import, any Go layout can be used
+var (
+	ImportDateFormats = []string{"02.01.2006", "2006-01-02", "01/02/2006", "02/01/2006", "02.01.06"}
+	ImportTimeFormats = []string{"15:04", "15:04:05", "3:04 PM"}
+)
+
+// ImportMapping describes which columns of a file hold the worktime
+type ImportMapping struct {
+	// Zero based column indices, the pause columns are optional and -1 if missing
+	Date  int
+	Begin int
+	End   int
+	Pause int
+	// Time the pause started, a row with a pause is invalid without it
+	PauseStart int
+	// Absences of the day like vacation or sick (half day), optional
+	Absence int
+
+	// Go layouts of the date and time columns
+	DateFormat string
+	TimeFormat string
+
+	// The first row holds the column names
+	HasHeader bool
+}
+
+type ImportRowStatus string
+
+const (
+	ImportRowOK ImportRowStatus = "ok"
+	// The row could not be parsed
+	ImportRowInvalid ImportRowStatus = "invalid"
+	// A workday of the date already exists
+	ImportRowConflict ImportRowStatus = "conflict"
+)
+
+// ImportRow is a parsed row of an import file
+type ImportRow struct {
+	// Row number in the file, starting at 1
+	Line   int
+	Values []string
+
+	Date       time.Time
+	Begin      time.Time
+	End        time.Time
+	Pause      time.Duration
+	PauseStart time.Time
+	// A row with absences may leave begin and end empty
+	Absences []*ExportAbsence
+
+	Status ImportRowStatus
+	// Why the row is skipped
+	Reason string
+}
+
+// ImportReport lists the rows of an import and whether they were imported
+type ImportReport struct {
+	Rows []*ImportRow
+	// Rows were only checked and nothing was written
+	DryRun bool
+}
+
+// Counts the rows with the given status
+func (ir *ImportReport) Count(status ImportRowStatus) int {
+	var count int
+	for _, r := range ir.Rows {
+		if r.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the rows which are not imported
+func (ir *ImportReport) Skipped() []*ImportRow {
+	var skipped []*ImportRow
+	for _, r := range ir.Rows {
+		if r.Status != ImportRowOK {
+			skipped = append(skipped, r)
+		}
+	}
+	return skipped
+}
+
+// Reads the rows of a CSV, XLSX or JSON export file depending on the file name
+func ReadImportFile(name string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".txt":
+		return ReadCSV(r)
+	case ".xlsx":
+		return ReadXLSX(r)
+	case ".json":
+		return ReadExportJSON(r)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, name)
+}
+
+// Reads a CSV file separated by semicolons, commas or tabs
+func ReadCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	// Spreadsheets with a german locale export semicolons
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	separator := ','
+	for _, s := range []rune{';', '\t'} {
+		if bytes.Count(firstLine, []byte(string(s))) > bytes.Count(firstLine, []byte(string(separator))) {
+			separator = s
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = separator
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
+
+// Reads the first sheet of an XLSX file
+func ReadXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("the file has no sheets")
+	}
+	return f.GetRows(sheets[0])
+}
+
+/*
+Returns a mapping of the first columns as date, begin, end, pause, pause
+start and absence with the date and time formats which parse the first row
+after the header.
+*/
+func GuessImportMapping(records [][]string) ImportMapping {
+	m := ImportMapping{
+		Date: 0, Begin: 1, End: 2, Pause: 3, PauseStart: 4, Absence: 5,
+		DateFormat: ImportDateFormats[0],
+		TimeFormat: ImportTimeFormats[0],
+		HasHeader:  true,
+	}
+	if len(records) < 2 {
+		return m
+	}
+	row := records[1]
+	if len(row) < 6 {
+		m.Absence = -1
+	}
+	if len(row) < 5 {
+		m.PauseStart = -1
+	}
+	if len(row) < 4 {
+		m.Pause = -1
+	}
+	if len(row) < 3 {
+		return m
+	}
+
+	for _, layout := range ImportDateFormats {
+		if _, err := time.Parse(layout, strings.TrimSpace(row[m.Date])); err == nil {
+			m.DateFormat = layout
+			break
+		}
+	}
+	for _, layout := range ImportTimeFormats {
+		if _, err := time.Parse(layout, strings.TrimSpace(row[m.Begin])); err == nil {
+			m.TimeFormat = layout
+			break
+		}
+	}
+	return m
+}
+
+// ImporterService imports worktime tracked in other tools
+type ImporterService struct {
+	repo *repo.SQLiteRepository
+}
+
+func NewImporterService(repo *repo.SQLiteRepository) *ImporterService {
+	return &ImporterService{repo: repo}
+}
+
+/*
+Parses the rows with the mapping and imports the valid rows in a single
+transaction. Rows of dates which already have a workday or an absence are
+skipped as conflicts. With dryRun nothing is written and the report shows
+what would be imported.
+*/
+func (is *ImporterService) Import(records [][]string, m ImportMapping, dryRun bool) (*ImportReport, error) {
+	report := ParseImport(records, m)
+	report.DryRun = dryRun
+
+	existing, err := is.repo.GetAllWorkday(repo.ASC)
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]bool, len(existing))
+	for _, wd := range existing {
+		dates[wd.Date.Format(time.DateOnly)] = true
+	}
+	absences, err := is.repo.GetAllAbsence()
+	if err != nil {
+		return nil, err
+	}
+
+	var workdays []*db.Workday
+	var worktimes [][]*db.Worktime
+	byDate := make(map[string]int)
+	for _, row := range report.Rows {
+		if row.Status != ImportRowOK {
+			continue
+		}
+		date := row.Date.Format(time.DateOnly)
+		if !row.Begin.IsZero() && dates[date] {
+			row.Status = ImportRowConflict
+			row.Reason = "a workday on " + date + " already exists"
+			continue
+		}
+		if len(row.Absences) > 0 && slices.ContainsFunc(absences, func(a *db.Absence) bool {
+			return a.DayShare(row.Date) > 0
+		}) {
+			row.Status = ImportRowConflict
+			row.Reason = "an absence on " + date + " already exists"
+			continue
+		}
+		if row.Begin.IsZero() {
+			continue
+		}
+
+		i, ok := byDate[date]
+		if !ok {
+			i = len(workdays)
+			byDate[date] = i
+			workdays = append(workdays, &db.Workday{Date: row.Begin})
+			worktimes = append(worktimes, nil)
+		}
+		worktimes[i] = append(worktimes[i], rowWorktimes(row)...)
+	}
+
+	added := importAbsences(report.Rows)
+	if dryRun || (len(workdays) == 0 && len(added) == 0) {
+		return report, nil
+	}
+	for _, wts := range worktimes {
+		sort.SliceStable(wts, func(i, j int) bool {
+			return wts[i].Time.Before(wts[j].Time)
+		})
+	}
+	if err := is.repo.AddWorkdaysWithWorktimes(workdays, worktimes, added); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+/*
+Returns the absences of the valid rows, consecutive days of the same type
+become one absence. A half day starts an absence at noon or ends it at noon.
+*/
+func importAbsences(rows []*ImportRow) []*db.Absence {
+	valid := make([]*ImportRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Status == ImportRowOK && len(row.Absences) > 0 {
+			valid = append(valid, row)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].Date.Before(valid[j].Date)
+	})
+
+	var absences []*db.Absence
+	last := make(map[db.AbsenceType]*db.Absence)
+	for _, row := range valid {
+		for _, a := range row.Absences {
+			halfDay := a.Share < 1
+			previous, ok := last[a.Type]
+			if ok && !previous.HalfDayEnd &&
+				previous.EndDate.AddDate(0, 0, 1).Format(time.DateOnly) == row.Date.Format(time.DateOnly) {
+				previous.EndDate = row.Date
+				previous.HalfDayEnd = halfDay
+				continue
+			}
+
+			absence := &db.Absence{Type: a.Type, StartDate: row.Date, EndDate: row.Date, HalfDayStart: halfDay}
+			last[a.Type] = absence
+			absences = append(absences, absence)
+		}
+	}
+	return absences
+}
+
+/*
+Parses the rows with the mapping without touching the database. Every row
+becomes a Begin/End pair of its date, a pause splits the pair at its start.
+The times of a pause are not made up, a row with a pause is invalid unless
+the pause start is mapped. A row with absences may leave begin and end
+empty. Rows of the same date must not overlap.
+*/
+func ParseImport(records [][]string, m ImportMapping) *ImportReport {
+	report := &ImportReport{}
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	for i, values := range records {
+		if i == 0 && m.HasHeader {
+			continue
+		}
+		if isEmptyRecord(values) {
+			continue
+		}
+
+		row := &ImportRow{Line: i + 1, Values: values, Status: ImportRowOK}
+		report.Rows = append(report.Rows, row)
+		if err := parseImportRow(row, m, loc); err != nil {
+			row.Status = ImportRowInvalid
+			row.Reason = err.Error()
+		}
+	}
+
+	// Rows of the same day must not overlap
+	valid := make([]*ImportRow, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		if row.Status == ImportRowOK && !row.Begin.IsZero() {
+			valid = append(valid, row)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].Begin.Before(valid[j].Begin)
+	})
+	for i := 1; i < len(valid); i++ {
+		previous := valid[i-1]
+		if valid[i].Begin.Before(previous.End) {
+			valid[i].Status = ImportRowInvalid
+			valid[i].Reason = fmt.Sprintf("overlaps with row %d", previous.Line)
+			// The following rows are compared with the last valid row
+			valid[i] = previous
+		}
+	}
+
+	return report
+}
+
+func parseImportRow(row *ImportRow, m ImportMapping, loc *time.Location) error {
+	value := func(column int) string {
+		if column < 0 || column >= len(row.Values) {
+			return ""
+		}
+		return strings.TrimSpace(row.Values[column])
+	}
+
+	date, err := parseImportDate(value(m.Date), m.DateFormat, loc)
+	if err != nil {
+		return fmt.Errorf("invalid date %q", value(m.Date))
+	}
+	row.Date = date
+
+	row.Absences, err = parseImportAbsences(value(m.Absence))
+	if err != nil {
+		return err
+	}
+	if len(row.Absences) > 0 && value(m.Begin) == "" && value(m.End) == "" {
+		return nil
+	}
+	row.Begin, err = parseImportTime(date, value(m.Begin), m.TimeFormat, loc)
+	if err != nil {
+		return fmt.Errorf("invalid begin %q", value(m.Begin))
+	}
+	row.End, err = parseImportTime(date, value(m.End), m.TimeFormat, loc)
+	if err != nil {
+		return fmt.Errorf("invalid end %q", value(m.End))
+	}
+	if !row.End.After(row.Begin) {
+		return errors.New("end is not after begin")
+	}
+
+	row.Pause, err = parseImportPause(value(m.Pause))
+	if err != nil {
+		return fmt.Errorf("invalid pause %q", value(m.Pause))
+	}
+	if row.Pause < 0 || row.Pause >= row.End.Sub(row.Begin) {
+		return errors.New("pause is longer than the worktime")
+	}
+	if row.Pause == 0 {
+		return nil
+	}
+
+	if m.PauseStart < 0 {
+		return errors.New("the pause has no start, map the column of the pause start")
+	}
+	row.PauseStart, err = parseImportTime(date, value(m.PauseStart), m.TimeFormat, loc)
+	if err != nil {
+		return fmt.Errorf("invalid pause start %q", value(m.PauseStart))
+	}
+	if !row.PauseStart.After(row.Begin) || !row.PauseStart.Add(row.Pause).Before(row.End) {
+		return errors.New("the pause is not within the worktime")
+	}
+	return nil
+}
+
+// Parses a date with the layout or as a spreadsheet serial number
+func parseImportDate(value string, layout string, loc *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation(layout, value, loc)
+	if err == nil {
+		return date, nil
+	}
+	if serial, serialErr := strconv.ParseFloat(value, 64); serialErr == nil && serial > 0 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+	}
+	return time.Time{}, err
+}
+
+// Parses a time of the given date with the layout or as a fraction of a day
+func parseImportTime(date time.Time, value string, layout string, loc *time.Location) (time.Time, error) {
+	clock, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		fraction, fractionErr := strconv.ParseFloat(value, 64)
+		if fractionErr != nil || fraction < 0 || fraction >= 1 {
+			return time.Time{}, err
+		}
+		seconds := int(fraction*24*60*60 + 0.5)
+		clock = time.Date(0, 1, 1, 0, 0, seconds, 0, loc)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0, loc), nil
+}
+
+// Parses absences like vacation, sick (half day) as written by the export
+func parseImportAbsences(value string) ([]*ExportAbsence, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var absences []*ExportAbsence
+	for _, part := range strings.Split(value, ",") {
+		name, halfDay := strings.CutSuffix(strings.TrimSpace(part), " (half day)")
+		absence := &ExportAbsence{Type: db.AbsenceType(strings.TrimSpace(name)), Share: 1}
+		if !slices.Contains(db.AbsenceTypes, absence.Type) {
+			return nil, fmt.Errorf("invalid absence %q", part)
+		}
+		if halfDay {
+			absence.Share = 0.5
+		}
+		absences = append(absences, absence)
+	}
+	return absences, nil
+}
+
+// Parses a pause like 30m, 0:30 or 30 (minutes), an empty pause is 0
+func parseImportPause(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if minutes, err := strconv.Atoi(value); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	if hours, minutes, ok := strings.Cut(value, ":"); ok {
+		h, err := strconv.Atoi(hours)
+		if err != nil {
+			return 0, err
+		}
+		m, err := strconv.Atoi(minutes)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Creates the worktimes of a row, a pause splits the row at its start
+func rowWorktimes(row *ImportRow) []*db.Worktime {
+	if row.Pause == 0 {
+		return []*db.Worktime{
+			{Type: "Begin", Time: row.Begin},
+			{Type: "End", Time: row.End},
+		}
+	}
+
+	return []*db.Worktime{
+		{Type: "Begin", Time: row.Begin},
+		{Type: "End", Time: row.PauseStart},
+		{Type: "Begin", Time: row.PauseStart.Add(row.Pause)},
+		{Type: "End", Time: row.End},
+	}
+}
+
+func isEmptyRecord(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
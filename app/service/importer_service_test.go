@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+	"github.com/xuri/excelize/v2"
+)
+
+var testMapping = ImportMapping{
+	Date: 0, Begin: 1, End: 2, Pause: 3, PauseStart: 4, Absence: 5,
+	DateFormat: "02.01.2006",
+	TimeFormat: "15:04",
+	HasHeader:  true,
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{"comma", "date,begin\n04.08.2025,08:00\n", [][]string{{"date", "begin"}, {"04.08.2025", "08:00"}}},
+		{"semicolon", "date;begin\n04.08.2025;08:00\n", [][]string{{"date", "begin"}, {"04.08.2025", "08:00"}}},
+		{"tab", "date\tbegin\n04.08.2025\t08:00\n", [][]string{{"date", "begin"}, {"04.08.2025", "08:00"}}},
+		{"byte order mark", "\ufeffdate;begin\n", [][]string{{"date", "begin"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReadCSV() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if strings.Join(got[i], "|") != strings.Join(tt.want[i], "|") {
+					t.Errorf("ReadCSV() row %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]any{"date", "begin", "end"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{"04.08.2025", "08:00", "16:00"})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadImportFile("times.xlsx", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][2] != "16:00" {
+		t.Errorf("ReadImportFile() = %v", records)
+	}
+
+	if _, err := ReadImportFile("times.ods", &buf); err == nil {
+		t.Error("ReadImportFile() of an ods file should fail")
+	}
+}
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    []string
+		status ImportRowStatus
+		pause  time.Duration
+	}{
+		{"valid", []string{"04.08.2025", "08:00", "16:30", "30", "12:00"}, ImportRowOK, 30 * time.Minute},
+		{"without pause", []string{"04.08.2025", "08:00", "12:00", "", ""}, ImportRowOK, 0},
+		{"pause as duration", []string{"04.08.2025", "08:00", "16:30", "45m", "12:00"}, ImportRowOK, 45 * time.Minute},
+		{"pause as clock", []string{"04.08.2025", "08:00", "16:30", "1:00", "12:00"}, ImportRowOK, time.Hour},
+		{"spreadsheet serials", []string{"45873", "0.3333333", "0.6666667", ""}, ImportRowOK, 0},
+		{"invalid date", []string{"2025-08-04", "08:00", "16:30", ""}, ImportRowInvalid, 0},
+		{"invalid begin", []string{"04.08.2025", "8 am", "16:30", ""}, ImportRowInvalid, 0},
+		{"end before begin", []string{"04.08.2025", "16:30", "08:00", ""}, ImportRowInvalid, 0},
+		{"pause too long", []string{"04.08.2025", "08:00", "09:00", "60", "08:30"}, ImportRowInvalid, 0},
+		{"pause without start", []string{"04.08.2025", "08:00", "16:30", "30", ""}, ImportRowInvalid, 0},
+		{"pause after the end", []string{"04.08.2025", "08:00", "16:30", "30", "16:15"}, ImportRowInvalid, 0},
+		{"missing column", []string{"04.08.2025", "08:00"}, ImportRowInvalid, 0},
+		{"absence", []string{"04.08.2025", "", "", "", "", "vacation, sick (half day)"}, ImportRowOK, 0},
+		{"absence and worktime", []string{"04.08.2025", "08:00", "12:00", "", "", "vacation (half day)"}, ImportRowOK, 0},
+		{"invalid absence", []string{"04.08.2025", "", "", "", "", "holiday"}, ImportRowInvalid, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := ParseImport([][]string{{"date", "begin", "end", "pause", "pause start", "absence"}, tt.row}, testMapping)
+			if len(report.Rows) != 1 {
+				t.Fatalf("ParseImport() rows = %d, want 1", len(report.Rows))
+			}
+			row := report.Rows[0]
+			if row.Status != tt.status {
+				t.Errorf("ParseImport() status = %s (%s), want %s", row.Status, row.Reason, tt.status)
+			}
+			if row.Line != 2 {
+				t.Errorf("ParseImport() line = %d, want 2", row.Line)
+			}
+			if tt.status == ImportRowOK && row.Pause != tt.pause {
+				t.Errorf("ParseImport() pause = %s, want %s", row.Pause, tt.pause)
+			}
+		})
+	}
+}
+
+func TestParseImportOverlap(t *testing.T) {
+	records := [][]string{
+		{"04.08.2025", "13:00", "17:00", ""},
+		{"04.08.2025", "08:00", "12:00", ""},
+		{"04.08.2025", "11:00", "14:00", ""},
+		{"05.08.2025", "11:00", "14:00", ""},
+		{},
+	}
+	m := testMapping
+	m.HasHeader = false
+
+	report := ParseImport(records, m)
+	want := []ImportRowStatus{ImportRowOK, ImportRowOK, ImportRowInvalid, ImportRowOK}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("ParseImport() rows = %d, want %d", len(report.Rows), len(want))
+	}
+	for i, status := range want {
+		if report.Rows[i].Status != status {
+			t.Errorf("row %d status = %s, want %s", i+1, report.Rows[i].Status, status)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	r := repotest.NewRepository(t)
+	is := NewImporterService(r)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	if _, err := r.AddWorkday(&db.Workday{Date: time.Date(2025, 8, 5, 0, 0, 0, 0, loc)}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := [][]string{
+		{"date", "begin", "end", "pause", "pause start"},
+		{"04.08.2025", "08:00", "16:30", "30", "12:00"},
+		{"04.08.2025", "17:00", "18:00", ""},
+		{"05.08.2025", "08:00", "16:00", ""},
+		{"06.08.2025", "xx", "16:00", ""},
+	}
+
+	report, err := is.Import(records, testMapping, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(ImportRowOK) != 2 || report.Count(ImportRowConflict) != 1 || report.Count(ImportRowInvalid) != 1 {
+		t.Errorf("Import() dry run counts = %d/%d/%d", report.Count(ImportRowOK),
+			report.Count(ImportRowConflict), report.Count(ImportRowInvalid))
+	}
+	if workdays, _ := r.GetAllWorkday(repo.ASC); len(workdays) != 1 {
+		t.Fatalf("dry run added workdays, got %d", len(workdays))
+	}
+
+	report, err = is.Import(records, testMapping, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Skipped()) != 2 {
+		t.Errorf("Import() skipped = %d, want 2", len(report.Skipped()))
+	}
+
+	workday, err := r.GetWorkday(time.Date(2025, 8, 4, 12, 0, 0, 0, loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktimes, err := r.GetAllWorktime(workday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The pause splits the first row at its start, the second row is its own pair
+	want := []string{"08:00", "12:00", "12:30", "16:30", "17:00", "18:00"}
+	if len(worktimes) != len(want) {
+		t.Fatalf("worktimes = %d, want %d", len(worktimes), len(want))
+	}
+	for i, wt := range worktimes {
+		if got := wt.Time.In(loc).Format("15:04"); got != want[i] {
+			t.Errorf("worktime %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	// A second import only finds conflicts
+	report, err = is.Import(records, testMapping, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(ImportRowOK) != 0 || report.Count(ImportRowConflict) != 3 {
+		t.Errorf("Import() again imported %d rows", report.Count(ImportRowOK))
+	}
+}
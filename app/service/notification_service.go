@@ -0,0 +1,136 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+type NotificationRule string
+
+const (
+	// The target of the day is worked
+	NotifyTargetReached NotificationRule = "targetReached"
+	// Working for too long without a break
+	NotifyNoBreak NotificationRule = "noBreak"
+	// The worked time approaches the maximum of a day
+	NotifyLongDay NotificationRule = "longDay"
+	// Still clocked in late in the evening
+	NotifyClockOut NotificationRule = "clockOut"
+)
+
+var NotificationRules = []NotificationRule{NotifyTargetReached, NotifyNoBreak, NotifyLongDay, NotifyClockOut}
+
+// Notifier sends desktop notifications, it is implemented by fyne.App
+type Notifier interface {
+	SendNotification(n *fyne.Notification)
+}
+
+/*
+NotificationService sends each notification at most once a day, the day on
+which a rule was notified the last time is kept in the preferences so a
+restart does not send it again
+*/
+type NotificationService struct {
+	notifier    Notifier
+	preferences fyne.Preferences
+
+	mu sync.Mutex
+}
+
+func NewNotificationService(notifier Notifier, preferences fyne.Preferences) *NotificationService {
+	return &NotificationService{notifier: notifier, preferences: preferences}
+}
+
+/*
+Checks the time entries of today and sends the due notifications which were
+not sent today yet. The target is the time which should be worked today.
+The sent rules are returned.
+*/
+func (ns *NotificationService) Check(settings *model.Settings, worktimes []*db.Worktime, target time.Duration, now time.Time) []NotificationRule {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	today := now.Format(time.DateOnly)
+	var sent []NotificationRule
+	for _, rule := range DueNotifications(settings, worktimes, target, now) {
+		if ns.preferences.String(notifiedProperty(rule)) == today {
+			continue
+		}
+		ns.preferences.SetString(notifiedProperty(rule), today)
+		ns.notifier.SendNotification(fyne.NewNotification(lang.L(string(rule)+"Notification"),
+			notificationMessage(rule, settings, worktimes, target, now)))
+		sent = append(sent, rule)
+	}
+	return sent
+}
+
+// Preference of the day on which the rule was notified the last time
+func notifiedProperty(rule NotificationRule) string {
+	return string(rule) + "NotifiedOn"
+}
+
+// Returns the enabled rules which apply to the time entries of today
+func DueNotifications(settings *model.Settings, worktimes []*db.Worktime, target time.Duration, now time.Time) []NotificationRule {
+	wts := sortedWorktimes(worktimes)
+	// Only remind while clocked in, a closed day needs no attention
+	if len(wts)%2 == 0 {
+		return nil
+	}
+	worked := workedUntil(wts, settings.BreakRules, now)
+	segment := now.Sub(wts[len(wts)-1].Time)
+
+	var due []NotificationRule
+	if settings.NotifyTargetReached && target > 0 && worked >= target {
+		due = append(due, NotifyTargetReached)
+	}
+	if settings.NotifyNoBreak && segment >= DefaultComplianceLimits.MaxSegment {
+		due = append(due, NotifyNoBreak)
+	}
+	if settings.NotifyLongDay && worked >= model.HoursToDuration(settings.LongDayHours) {
+		due = append(due, NotifyLongDay)
+	}
+	if settings.NotifyClockOut && now.Hour() >= settings.ClockOutHour {
+		due = append(due, NotifyClockOut)
+	}
+	return due
+}
+
+// Returns the worked time of the day if the open entry ends now
+func workedUntil(worktimes []*db.Worktime, rules []model.BreakRule, now time.Time) time.Duration {
+	closed := append(worktimes[:len(worktimes):len(worktimes)], &db.Worktime{Type: "End", Time: now})
+	worked, _ := CalculateWorkday(closed, rules)
+	return worked
+}
+
+func notificationMessage(rule NotificationRule, settings *model.Settings, worktimes []*db.Worktime, target time.Duration, now time.Time) string {
+	wts := sortedWorktimes(worktimes)
+	switch rule {
+	case NotifyTargetReached:
+		return lang.L("targetReachedMessage", map[string]any{"Target": model.FormatDuration(target)})
+	case NotifyNoBreak:
+		return lang.L("noBreakMessage", map[string]any{
+			"Duration": model.FormatDuration(now.Sub(wts[len(wts)-1].Time)),
+		})
+	case NotifyLongDay:
+		return lang.L("longDayMessage", map[string]any{
+			"Worked": model.FormatDuration(workedUntil(wts, settings.BreakRules, now)),
+			"Limit":  model.FormatDuration(DefaultComplianceLimits.MaxDailyWork),
+		})
+	}
+	return lang.L("clockOutMessage", map[string]any{"Time": now.Format("15:04")})
+}
+
+func sortedWorktimes(worktimes []*db.Worktime) []*db.Worktime {
+	sorted := make([]*db.Worktime, len(worktimes))
+	copy(sorted, worktimes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+	return sorted
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+type fakeNotifier struct {
+	sent []*fyne.Notification
+}
+
+func (f *fakeNotifier) SendNotification(n *fyne.Notification) {
+	f.sent = append(f.sent, n)
+}
+
+func TestDueNotifications(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(clock string) time.Time {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2025, 8, 4, c.Hour(), c.Minute(), 0, 0, loc)
+	}
+	entries := func(clocks ...string) []*db.Worktime {
+		var wts []*db.Worktime
+		for i, c := range clocks {
+			typ := "Begin"
+			if i%2 == 1 {
+				typ = "End"
+			}
+			wts = append(wts, &db.Worktime{Type: typ, Time: at(c)})
+		}
+		return wts
+	}
+
+	tests := []struct {
+		name      string
+		worktimes []*db.Worktime
+		now       string
+		want      []NotificationRule
+	}{
+		{"clocked out", entries("08:00", "17:00"), "20:00", nil},
+		{"working", entries("08:00"), "11:00", nil},
+		{"target reached", entries("08:00", "12:00", "12:30"), "16:45", []NotificationRule{NotifyTargetReached}},
+		{"no break", entries("08:00"), "14:10", []NotificationRule{NotifyNoBreak}},
+		{"long day", entries("07:00", "12:00", "12:45"), "17:30", []NotificationRule{NotifyTargetReached, NotifyLongDay}},
+		{"late evening", entries("08:00", "12:00", "18:00"), "19:05", []NotificationRule{NotifyClockOut}},
+	}
+
+	settings := model.NewSettings("", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DueNotifications(settings, tt.worktimes, 8*time.Hour, at(tt.now))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	settings.NotifyNoBreak = false
+	if got := DueNotifications(settings, entries("08:00"), 8*time.Hour, at("14:10")); len(got) != 0 {
+		t.Errorf("disabled rule notified: %v", got)
+	}
+}
+
+func TestNotificationServiceOncePerDay(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	settings := model.NewSettings("", "")
+	notifier := &fakeNotifier{}
+	preferences := test.NewTempApp(t).Preferences()
+	ns := NewNotificationService(notifier, preferences)
+
+	check := func(day int, clock int) []NotificationRule {
+		begin := &db.Worktime{Type: "Begin", Time: time.Date(2025, 8, day, 8, 0, 0, 0, loc)}
+		return ns.Check(settings, []*db.Worktime{begin}, 8*time.Hour, time.Date(2025, 8, day, clock, 30, 0, 0, loc))
+	}
+
+	if sent := check(4, 14); len(sent) != 1 || sent[0] != NotifyNoBreak {
+		t.Fatalf("first check sent %v", sent)
+	}
+	if sent := check(4, 15); len(sent) != 0 {
+		t.Errorf("second check sent %v again", sent)
+	}
+	// A restart remembers the notifications of the day
+	ns = NewNotificationService(notifier, preferences)
+	if sent := check(4, 15); len(sent) != 0 {
+		t.Errorf("check after restart sent %v again", sent)
+	}
+	if sent := check(5, 14); len(sent) != 1 {
+		t.Errorf("next day sent %v", sent)
+	}
+	if len(notifier.sent) != 2 || notifier.sent[0].Title == "" {
+		t.Errorf("unexpected notifications %+v", notifier.sent)
+	}
+}
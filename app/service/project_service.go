@@ -0,0 +1,272 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+)
+
+// ProjectService reports the worked hours per project
+type ProjectService struct {
+	repo *repo.SQLiteRepository
+}
+
+func NewProjectService(repo *repo.SQLiteRepository) *ProjectService {
+	return &ProjectService{repo: repo}
+}
+
+// ProjectReportRow is the recorded time of a project and one of its tasks
+type ProjectReportRow struct {
+	// Nil if the time is not attributed
+	Project *db.Project
+	// Nil if the time is attributed to the project only
+	Task *db.Task
+	Time time.Duration
+}
+
+// ProjectReport breaks down the recorded time of a date range per project
+type ProjectReport struct {
+	From time.Time
+	To   time.Time
+	// Sorted by project and task name, the time without project comes last
+	Rows []*ProjectReportRow
+	// Recorded time per project id, 0 is the time without project
+	Projects map[int64]time.Duration
+	Total    time.Duration
+}
+
+/*
+Sums up the recorded segments between both dates including. The mandatory
+breaks are not deducted, the report shows the time as it was booked.
+*/
+func (ps *ProjectService) Report(from time.Time, to time.Time) (*ProjectReport, error) {
+	hours, err := ps.repo.GetProjectHours(from, to)
+	if err != nil {
+		return nil, err
+	}
+	projects, tasks, err := ps.Lookup()
+	if err != nil {
+		return nil, err
+	}
+	return newProjectReport(from, to, hours, projects, tasks), nil
+}
+
+// Returns all projects and tasks by id
+func (ps *ProjectService) Lookup() (map[int64]*db.Project, map[int64]*db.Task, error) {
+	projects, err := ps.repo.GetAllProject()
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := ps.repo.GetAllTask()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byProject := make(map[int64]*db.Project, len(projects))
+	for _, p := range projects {
+		byProject[p.ID] = p
+	}
+	byTask := make(map[int64]*db.Task, len(tasks))
+	for _, t := range tasks {
+		byTask[t.ID] = t
+	}
+	return byProject, byTask, nil
+}
+
+func newProjectReport(from time.Time, to time.Time, hours []*db.ProjectHours,
+	projects map[int64]*db.Project, tasks map[int64]*db.Task) *ProjectReport {
+	report := &ProjectReport{From: from, To: to, Projects: make(map[int64]time.Duration)}
+	for _, h := range hours {
+		row := &ProjectReportRow{Project: projects[h.ProjectID], Task: tasks[h.TaskID], Time: h.Time}
+		report.Rows = append(report.Rows, row)
+		report.Projects[h.ProjectID] += h.Time
+		report.Total += h.Time
+	}
+
+	name := func(r *ProjectReportRow) (string, string) {
+		project, task := "", ""
+		if r.Project != nil {
+			project = strings.ToLower(r.Project.Name)
+		}
+		if r.Task != nil {
+			task = strings.ToLower(r.Task.Name)
+		}
+		return project, task
+	}
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if (a.Project == nil) != (b.Project == nil) {
+			return b.Project == nil
+		}
+		pa, ta := name(a)
+		pb, tb := name(b)
+		if pa != pb {
+			return pa < pb
+		}
+		return ta < tb
+	})
+	return report
+}
+
+// Percent of the budget at which a warning is sent
+var BudgetThresholds = []int{80, 100}
+
+// ProjectBudget compares the recorded time of a project with its budget
+type ProjectBudget struct {
+	Project *db.Project
+	// Recorded time until the end of the period
+	Used time.Duration
+	// Recorded time within the period
+	Period time.Duration
+}
+
+// Returns the budget which is left, negative if it is exceeded
+func (b *ProjectBudget) Remaining() time.Duration {
+	return b.Project.Budget - b.Used
+}
+
+// Returns the used share of the budget, 1 is the whole budget
+func (b *ProjectBudget) Share() float64 {
+	if b.Project.Budget <= 0 {
+		return 0
+	}
+	return float64(b.Used) / float64(b.Project.Budget)
+}
+
+// BurnDownPoint is the remaining budget at the end of a day
+type BurnDownPoint struct {
+	Date      time.Time
+	Remaining time.Duration
+}
+
+// BudgetWarning is due when a project has used a threshold of its budget
+type BudgetWarning struct {
+	Budget  *ProjectBudget
+	Percent int
+}
+
+/*
+Returns the projects with a budget and their recorded time. The used time
+counts everything recorded until the end of the period.
+*/
+func (ps *ProjectService) Budgets(from time.Time, to time.Time) ([]*ProjectBudget, error) {
+	projects, err := ps.repo.GetAllProject()
+	if err != nil {
+		return nil, err
+	}
+	used, err := ps.projectTotals(time.Time{}, to)
+	if err != nil {
+		return nil, err
+	}
+	period, err := ps.projectTotals(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []*ProjectBudget
+	for _, p := range projects {
+		if p.Budget <= 0 {
+			continue
+		}
+		budgets = append(budgets, &ProjectBudget{Project: p, Used: used[p.ID], Period: period[p.ID]})
+	}
+	return budgets, nil
+}
+
+// Returns the remaining budget of the project at the end of each day between both dates
+func (ps *ProjectService) BurnDown(project *db.Project, from time.Time, to time.Time) ([]BurnDownPoint, error) {
+	before, err := ps.projectTotals(time.Time{}, from.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	daily, err := ps.repo.GetProjectDailyHours(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return burnDown(project, before[project.ID], daily, from, to), nil
+}
+
+func burnDown(project *db.Project, before time.Duration, daily []*db.ProjectDayHours, from time.Time, to time.Time) []BurnDownPoint {
+	byDate := make(map[string]time.Duration)
+	for _, h := range daily {
+		if h.ProjectID == project.ID {
+			byDate[h.Date.Format(time.DateOnly)] += h.Time
+		}
+	}
+
+	var points []BurnDownPoint
+	used := before
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for d := first; !d.After(to); d = d.AddDate(0, 0, 1) {
+		used += byDate[d.Format(time.DateOnly)]
+		points = append(points, BurnDownPoint{Date: d, Remaining: project.Budget - used})
+	}
+	return points
+}
+
+/*
+Returns the warnings which are due for the budgets of the active projects and
+remembers them on the project, so each threshold is warned only once. If the
+budget was raised below a threshold it is warned again when it is reached.
+*/
+func (ps *ProjectService) DueBudgetWarnings(budgets []*ProjectBudget) ([]*BudgetWarning, error) {
+	var warnings []*BudgetWarning
+	for _, b := range budgets {
+		if !b.Project.Active {
+			continue
+		}
+		reached := reachedThreshold(b.Share())
+		if reached == b.Project.BudgetWarned {
+			continue
+		}
+		if reached > b.Project.BudgetWarned {
+			warnings = append(warnings, &BudgetWarning{Budget: b, Percent: reached})
+		}
+		b.Project.BudgetWarned = reached
+		if err := ps.repo.UpdateProjectBudgetWarned(b.Project); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// Sends a desktop notification for the budget warning
+func SendBudgetWarning(notifier Notifier, w *BudgetWarning) {
+	notifier.SendNotification(fyne.NewNotification(lang.L("budgetNotification"),
+		lang.L("budgetMessage", map[string]any{
+			"Project": w.Budget.Project.Name,
+			"Percent": w.Percent,
+			"Used":    model.FormatDuration(w.Budget.Used),
+			"Budget":  model.FormatDuration(w.Budget.Project.Budget),
+		})))
+}
+
+// Returns the highest threshold the share has reached or 0
+func reachedThreshold(share float64) int {
+	reached := 0
+	for _, t := range BudgetThresholds {
+		if share*100 >= float64(t) {
+			reached = t
+		}
+	}
+	return reached
+}
+
+// Returns the recorded time per project id between both dates
+func (ps *ProjectService) projectTotals(from time.Time, to time.Time) (map[int64]time.Duration, error) {
+	hours, err := ps.repo.GetProjectHours(from, to)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[int64]time.Duration)
+	for _, h := range hours {
+		totals[h.ProjectID] += h.Time
+	}
+	return totals, nil
+}
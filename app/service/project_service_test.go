@@ -0,0 +1,164 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestSwitchProjectAndReport(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	ps := NewProjectService(ts.repo)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int, min int) time.Time {
+		return time.Date(2025, 8, 4, hour, min, 0, 0, loc)
+	}
+
+	web, err := ts.repo.AddProject(&db.Project{Name: "Website", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := ts.repo.AddProject(&db.Project{Name: "App", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Start on the website, switch to the app and back to no project
+	if _, err := ts.SwitchProject(at(8, 0), web.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.SwitchProject(at(11, 0), app.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.SwitchProject(at(12, 30), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ClockOut(at(13, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := ts.Status(model.NewSettings("", ""), at(14, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Worktimes) != 6 || status.Open != nil {
+		t.Fatalf("got %d entries, open %v", len(status.Worktimes), status.Open)
+	}
+	// Switching leaves no break
+	if status.Result.Worktime != 5*time.Hour {
+		t.Errorf("worked %s, want 5h", status.Result.Worktime)
+	}
+
+	report, err := ps.Report(at(0, 0), at(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		project string
+		time    time.Duration
+	}{
+		{"App", 90 * time.Minute},
+		{"Website", 3 * time.Hour},
+		{"", 30 * time.Minute},
+	}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(report.Rows), len(want))
+	}
+	for i, w := range want {
+		row := report.Rows[i]
+		name := ""
+		if row.Project != nil {
+			name = row.Project.Name
+		}
+		if name != w.project || row.Time != w.time {
+			t.Errorf("row %d is %s %s, want %s %s", i, name, row.Time, w.project, w.time)
+		}
+	}
+	if report.Total != 5*time.Hour || report.Projects[web.ID] != 3*time.Hour {
+		t.Errorf("total %s, website %s", report.Total, report.Projects[web.ID])
+	}
+}
+
+func TestBudgetsAndBurnDown(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	ps := NewProjectService(ts.repo)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 8, day, hour, 0, 0, 0, loc)
+	}
+	book := func(day int, hours int, projectID int64) {
+		if _, err := ts.SwitchProject(at(day, 8), projectID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts.ClockOut(at(day, 8+hours)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	web, err := ts.repo.AddProject(&db.Project{Name: "Website", Active: true, Budget: 10 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Projects without budget are not listed
+	if _, err := ts.repo.AddProject(&db.Project{Name: "Internal", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	book(4, 4, web.ID)
+	book(5, 5, web.ID)
+
+	warned := func(to time.Time) []int {
+		budgets, err := ps.Budgets(to, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		warnings, err := ps.DueBudgetWarnings(budgets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var percents []int
+		for _, w := range warnings {
+			percents = append(percents, w.Percent)
+		}
+		return percents
+	}
+
+	budgets, err := ps.Budgets(at(5, 0), at(5, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 1 || budgets[0].Used != 9*time.Hour || budgets[0].Period != 5*time.Hour ||
+		budgets[0].Remaining() != time.Hour {
+		t.Fatalf("unexpected budgets %+v", budgets)
+	}
+
+	if got := warned(at(5, 0)); len(got) != 1 || got[0] != 80 {
+		t.Errorf("got warnings %v, want [80]", got)
+	}
+	// The warning is remembered on the project
+	if got := warned(at(5, 0)); len(got) != 0 {
+		t.Errorf("got warnings %v, want none", got)
+	}
+
+	book(6, 2, web.ID)
+	if got := warned(at(6, 0)); len(got) != 1 || got[0] != 100 {
+		t.Errorf("got warnings %v, want [100]", got)
+	}
+
+	points, err := ps.BurnDown(web, at(5, 0), at(7, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{time.Hour, -time.Hour, -time.Hour}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, p := range points {
+		if p.Remaining != want[i] {
+			t.Errorf("%s: remaining %s, want %s", p.Date.Format(time.DateOnly), p.Remaining, want[i])
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/charmbracelet/log"
+)
+
+/*
+Scheduler keeps the stored figures of the workdays up to date. Changed
+workdays are recalculated when the repository reports a change, a tick
+only updates the live figures of today.
+*/
+type Scheduler struct {
+	ts       *TimeEntryService
+	settings func() *model.Settings
+
+	// Called after changed data was recalculated
+	OnChange func(rc Recalculation)
+	// Called on every tick with the figures of today until now
+	OnTick func(settings *model.Settings, today *DayStatus)
+
+	mu sync.Mutex
+	// Workdays which need to be recalculated
+	pending map[int64]bool
+	// All workdays need to be recalculated, e.g. the break rules changed
+	all bool
+	// Other changed data which only the view needs, e.g. absences
+	kinds map[repo.ChangeKind]bool
+	wake  chan struct{}
+}
+
+// Recalculation tells what was changed since the last one
+type Recalculation struct {
+	// All workdays were recalculated, e.g. after the settings were changed
+	All bool
+	// Workdays whose time entries changed, deleted workdays included
+	Workdays []int64
+	// Kinds of the other changed data
+	Kinds map[repo.ChangeKind]bool
+}
+
+// Creates a scheduler which listens to the changes of the repository of the service
+func NewScheduler(ts *TimeEntryService, settings func() *model.Settings) *Scheduler {
+	s := &Scheduler{
+		ts:       ts,
+		settings: settings,
+		pending:  make(map[int64]bool),
+		kinds:    make(map[repo.ChangeKind]bool),
+		wake:     make(chan struct{}, 1),
+	}
+	ts.repo.OnChange(s.onChange)
+	return s
+}
+
+// Recalculates all workdays, e.g. after the settings were changed
+func (s *Scheduler) RecalculateAll() {
+	s.mu.Lock()
+	s.all = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+/*
+Runs until the context is canceled. All workdays are recalculated on
+start, afterwards only the changed ones.
+*/
+func (s *Scheduler) Run(ctx context.Context) {
+	s.RecalculateAll()
+
+	interval := s.interval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Scheduler stopped")
+			return
+		case <-s.wake:
+			s.Process()
+			s.Tick(time.Now())
+			// The refresh time may have been changed with the settings
+			if i := s.interval(); i != interval {
+				interval = i
+				ticker.Reset(interval)
+			}
+		case now := <-ticker.C:
+			s.Tick(now)
+		}
+	}
+}
+
+// Recalculates the pending workdays and stores the figures which differ
+func (s *Scheduler) Process() {
+	s.mu.Lock()
+	pending, all, kinds := s.pending, s.all, s.kinds
+	s.pending, s.all, s.kinds = make(map[int64]bool), false, make(map[repo.ChangeKind]bool)
+	s.mu.Unlock()
+
+	if !all && len(kinds) == 0 && len(pending) == 0 {
+		return
+	}
+
+	settings := s.settings()
+	var workdays []*db.Workday
+	if all {
+		var err error
+		if workdays, err = s.ts.repo.GetAllWorkday(repo.ASC); err != nil {
+			log.Error(err)
+			return
+		}
+	} else {
+		for id := range pending {
+			wd, err := s.ts.repo.GetWorkdayByID(id)
+			if errors.Is(err, sql.ErrNoRows) {
+				// The workday was deleted
+				continue
+			} else if err != nil {
+				log.Error(err)
+				continue
+			}
+			workdays = append(workdays, wd)
+		}
+	}
+	log.Debug("Recalculate workdays", "size", len(workdays), "all", all)
+
+	var updated []*db.Workday
+	for _, wd := range workdays {
+		worktimes, err := s.ts.repo.GetAllWorktime(wd)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		worktime, breaktime := CalculateWorkday(worktimes, settings.BreakRules)
+		if worktime != wd.Time || breaktime != wd.Breaktime {
+			wd.Time, wd.Breaktime = worktime, breaktime
+			updated = append(updated, wd)
+		}
+	}
+	if len(updated) > 0 {
+		if err := s.ts.repo.UpdateWorkdaysBatch(updated); err != nil {
+			log.Error(err)
+		}
+	}
+
+	if s.OnChange != nil {
+		rc := Recalculation{All: all, Kinds: kinds}
+		for id := range pending {
+			rc.Workdays = append(rc.Workdays, id)
+		}
+		s.OnChange(rc)
+	}
+}
+
+// Calculates the figures of today until the given time
+func (s *Scheduler) Tick(now time.Time) {
+	if s.OnTick == nil {
+		return
+	}
+	settings := s.settings()
+	today, err := s.ts.Status(settings, now)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	s.OnTick(settings, today)
+}
+
+func (s *Scheduler) onChange(c repo.Change) {
+	s.mu.Lock()
+	if c.Kind == repo.ChangeWorktime && c.WorkdayID != 0 {
+		s.pending[c.WorkdayID] = true
+	} else {
+		s.kinds[c.Kind] = true
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Wakes up the scheduler, pending changes are coalesced
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) interval() time.Duration {
+	refresh := s.settings().RefreshTimeUi
+	// Same minimum as the settings validation
+	return time.Duration(max(refresh, 15)) * time.Second
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestSchedulerRecalculatesChangedWorkdays(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	settings := model.NewSettings("", "")
+	s := NewScheduler(ts, func() *model.Settings { return settings })
+	changes := 0
+	var last Recalculation
+	s.OnChange = func(rc Recalculation) {
+		changes++
+		last = rc
+	}
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 8, day, hour, 0, 0, 0, loc)
+	}
+	if _, err := ts.ClockIn(at(4, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ClockOut(at(4, 17)); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Process()
+	wd, err := ts.repo.GetWorkday(at(4, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd.Time != 8*time.Hour+15*time.Minute || wd.Breaktime != 45*time.Minute {
+		t.Errorf("stored time %s, break %s", wd.Time, wd.Breaktime)
+	}
+	if changes != 1 || last.All || len(last.Workdays) != 1 || last.Workdays[0] != wd.ID {
+		t.Errorf("got %d change callbacks, last %+v, want 1 for workday %d", changes, last, wd.ID)
+	}
+
+	// Nothing changed
+	s.Process()
+	if changes != 1 {
+		t.Errorf("got %d change callbacks without a change", changes)
+	}
+
+	// Other break rules apply to all workdays
+	settings.BreakRules = nil
+	s.RecalculateAll()
+	s.Process()
+	if wd, _ = ts.repo.GetWorkday(at(4, 8)); wd.Time != 9*time.Hour || wd.Breaktime != 0 {
+		t.Errorf("after recalculation time %s, break %s", wd.Time, wd.Breaktime)
+	}
+	if !last.All {
+		t.Errorf("recalculation of all workdays reported as %+v", last)
+	}
+}
+
+func TestSchedulerStopsWithContext(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	settings := model.NewSettings("", "")
+	s := NewScheduler(ts, func() *model.Settings { return settings })
+
+	ticked := make(chan *DayStatus, 1)
+	s.OnTick = func(_ *model.Settings, today *DayStatus) {
+		select {
+		case ticked <- today:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-ticked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no tick after the start")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
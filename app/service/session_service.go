@@ -0,0 +1,186 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// How often the running app writes the heartbeat file
+const HeartbeatInterval = time.Minute
+
+var ErrAutostartUnsupported = errors.New("autostart on login is only supported on Linux")
+
+/*
+SessionService clocks in when the app starts and out when it is closed.
+While the app runs a heartbeat file holds the last time it was alive, the
+file is removed on a clean shutdown. If it is still there on the next
+start the app was not closed properly and the heartbeat is the missing End.
+The entries of the session are recorded automatically, they are not part of
+the undo history.
+*/
+type SessionService struct {
+	ts            *TimeEntryService
+	heartbeatPath string
+}
+
+func NewSessionService(ts *TimeEntryService, heartbeatPath string) *SessionService {
+	return &SessionService{ts: ts, heartbeatPath: heartbeatPath}
+}
+
+// Adds a Begin entry if nothing was recorded on the day yet, otherwise nil is returned
+func (ss *SessionService) Start(at time.Time) (*db.Worktime, error) {
+	_, worktimes, err := ss.ts.workdayOf(at)
+	if err != nil {
+		return nil, err
+	}
+	if len(worktimes) > 0 {
+		return nil, nil
+	}
+	return ss.ts.clockIn(at, 0, 0, false)
+}
+
+// Closes an open entry of the day and removes the heartbeat, nil is returned if nothing was open
+func (ss *SessionService) Stop(at time.Time) (*db.Worktime, error) {
+	wt, err := ss.ts.clockOut(at, false)
+	if errors.Is(err, ErrNotClockedIn) {
+		return nil, ss.ClearHeartbeat()
+	} else if err != nil {
+		return nil, err
+	}
+	return wt, ss.ClearHeartbeat()
+}
+
+// Writes the given time to the heartbeat file, the file is replaced atomically
+func (ss *SessionService) Beat(at time.Time) error {
+	tmp := ss.heartbeatPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(at.Format(time.RFC3339)), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ss.heartbeatPath)
+}
+
+func (ss *SessionService) ClearHeartbeat() error {
+	err := os.Remove(ss.heartbeatPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+/*
+Returns the last heartbeat if the app was not closed properly and the day
+of the heartbeat still has an open entry. The heartbeat is the proposed End.
+*/
+func (ss *SessionService) MissingEnd() (time.Time, bool, error) {
+	content, err := os.ReadFile(ss.heartbeatPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, false, nil
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+	heartbeat, err := time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if err != nil {
+		// A broken heartbeat cannot be proposed, it is replaced by the next one
+		return time.Time{}, false, nil
+	}
+
+	workday, err := ss.ts.repo.GetWorkday(heartbeat)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+	worktimes, err := ss.ts.repo.GetAllWorktime(workday)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	wts := sortedWorktimes(worktimes)
+	if len(wts)%2 == 0 || !wts[len(wts)-1].Time.Before(heartbeat) {
+		return time.Time{}, false, nil
+	}
+	return heartbeat, true, nil
+}
+
+/*
+Records the End proposed by MissingEnd. The app runs again since now, if
+that is on the day of the End a new Begin is added at now, because Start
+does not clock in while the entry is still open. The heartbeat is left to
+the restarted session, which replaces it.
+*/
+func (ss *SessionService) RecordMissingEnd(at time.Time, now time.Time) (*db.Worktime, error) {
+	wt, err := ss.ts.clockOut(at, false)
+	if err != nil {
+		return nil, err
+	}
+	if dateOnly(at) == dateOnly(now) && now.After(at) {
+		if _, err := ss.ts.clockIn(now, 0, 0, false); err != nil {
+			return nil, err
+		}
+	}
+	return wt, nil
+}
+
+// Adds or removes the XDG autostart entry which starts the app on login
+func SetAutostart(enabled bool) error {
+	if runtime.GOOS != "linux" {
+		if enabled {
+			return ErrAutostartUnsupported
+		}
+		return nil
+	}
+
+	dir, err := autostartDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "fyningtime.desktop")
+	if !enabled {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(autostartEntry(exe)), 0o644)
+}
+
+// Returns $XDG_CONFIG_HOME/autostart, by default ~/.config/autostart
+func autostartDir() (string, error) {
+	if config := os.Getenv("XDG_CONFIG_HOME"); config != "" {
+		return filepath.Join(config, "autostart"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "autostart"), nil
+}
+
+// Returns the desktop entry which starts the given executable
+func autostartEntry(exe string) string {
+	// Quoting rules of the desktop entry specification
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(exe)
+	return fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=FyningTime
+Comment=Track your work time
+Exec="%s"
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, quoted)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestSessionService(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	heartbeat := filepath.Join(t.TempDir(), "heartbeat")
+	ss := NewSessionService(ts, heartbeat)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, hour int) time.Time {
+		return time.Date(2025, 8, day, hour, 0, 0, 0, loc)
+	}
+
+	if wt, err := ss.Start(at(4, 8)); err != nil || wt == nil || wt.Type != "Begin" {
+		t.Fatalf("start = %v, %v", wt, err)
+	}
+	// The day was already started
+	if wt, err := ss.Start(at(4, 9)); err != nil || wt != nil {
+		t.Fatalf("second start = %v, %v", wt, err)
+	}
+
+	// Unclean shutdown, the heartbeat is the missing End
+	if err := ss.Beat(at(4, 15)); err != nil {
+		t.Fatal(err)
+	}
+	end, ok, err := ss.MissingEnd()
+	if err != nil || !ok || !end.Equal(at(4, 15)) {
+		t.Fatalf("missing end = %s, %t, %v", end, ok, err)
+	}
+	// Restarted on the next day, the crashed day stays closed
+	if wt, err := ss.RecordMissingEnd(end, at(5, 7)); err != nil || wt.Type != "End" {
+		t.Fatalf("record missing end = %v, %v", wt, err)
+	}
+	if status, err := ts.Status(model.NewSettings("", ""), at(4, 23)); err != nil || status.Open != nil {
+		t.Errorf("crashed day is open again: %+v, %v", status, err)
+	}
+	// The heartbeat is left to the restarted session, which replaces it
+	if _, ok, _ := ss.MissingEnd(); ok {
+		t.Error("missing end proposed for a closed day")
+	}
+
+	// Clean shutdown
+	if _, err := ss.Start(at(5, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.Beat(at(5, 12)); err != nil {
+		t.Fatal(err)
+	}
+	if wt, err := ss.Stop(at(5, 16)); err != nil || wt == nil || wt.Type != "End" {
+		t.Fatalf("stop = %v, %v", wt, err)
+	}
+	if _, ok, _ := ss.MissingEnd(); ok {
+		t.Error("missing end proposed after clean shutdown")
+	}
+	// Nothing open anymore
+	if wt, err := ss.Stop(at(5, 17)); err != nil || wt != nil {
+		t.Errorf("second stop = %v, %v", wt, err)
+	}
+}
+
+func TestSessionServiceRestartSameDay(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	ss := NewSessionService(ts, filepath.Join(t.TempDir(), "heartbeat"))
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(hour int) time.Time {
+		return time.Date(2025, 8, 4, hour, 0, 0, 0, loc)
+	}
+
+	if _, err := ss.Start(at(8)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.Beat(at(11)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restarted after a crash, the day still has its open entry
+	end, ok, err := ss.MissingEnd()
+	if err != nil || !ok {
+		t.Fatalf("missing end = %s, %t, %v", end, ok, err)
+	}
+	// The End is confirmed a while after the start at 12:00
+	if _, err := ss.RecordMissingEnd(end, at(12)); err != nil {
+		t.Fatal(err)
+	}
+	if wt, err := ss.Start(at(12)); err != nil || wt != nil {
+		t.Fatalf("start after crash = %v, %v", wt, err)
+	}
+
+	// The End closes the crashed session and the restarted one is open
+	status, err := ts.Status(model.NewSettings("", ""), at(13))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Worktimes) != 3 || status.Open == nil || !status.Open.Time.Equal(at(12)) {
+		t.Errorf("got %d worktimes with open entry %+v, want a Begin at 12:00", len(status.Worktimes), status.Open)
+	}
+}
+
+func TestAutostartEntry(t *testing.T) {
+	entry := autostartEntry(`/opt/fyning time/$bin`)
+	if !strings.Contains(entry, `Exec="/opt/fyning time/\$bin"`) {
+		t.Errorf("unexpected entry %q", entry)
+	}
+}
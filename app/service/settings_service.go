@@ -24,6 +24,25 @@ const (
 	importOvertimeProperty  = "importOvertime"
 	refreshTimeUiProperty   = "refreshTimeUi"
 	themeVariantProperty    = "themeVariant"
+	breakPolicyProperty     = "breakPolicy"
+	breakRulesProperty      = "breakRules"
+	targetHoursProperty     = "targetHours"
+	holidayRegionProperty   = "holidayRegion"
+	carryOverCutoffProperty = "carryOverCutoff"
+	employeeNameProperty    = "employeeName"
+	notifyTargetProperty    = "notifyTargetReached"
+	notifyNoBreakProperty   = "notifyNoBreak"
+	notifyLongDayProperty   = "notifyLongDay"
+	notifyClockOutProperty  = "notifyClockOut"
+	notifyBudgetProperty    = "notifyBudget"
+	longDayHoursProperty    = "longDayHours"
+	clockOutHourProperty    = "clockOutHour"
+	autoClockProperty       = "autoClock"
+	autostartProperty       = "autostart"
+	idleDetectionProperty   = "idleDetection"
+	idleMinutesProperty     = "idleMinutes"
+	currentProjectProperty  = "currentProject"
+	currentTaskProperty     = "currentTask"
 
 	// Default settings values
 	weekHoursDefault       = 40
@@ -32,6 +51,11 @@ const (
 	importOvertimeDefault  = 0
 	refreshTimeUiDefault   = 300 // in seconds
 	themeVariantDefault    = 0   // 0=auto, 1=dark, 2=light
+	breakPolicyDefault     = model.BreakPolicyGermany
+	carryOverCutoffDefault = "31.03"
+	longDayHoursDefault    = 9.5
+	clockOutHourDefault    = 19
+	idleMinutesDefault     = 10
 )
 
 func ReadProperties(a fyne.App) *model.Settings {
@@ -49,8 +73,49 @@ func ReadProperties(a fyne.App) *model.Settings {
 	settings.ImportOvertime = a.Preferences().FloatWithFallback(importOvertimeProperty, importOvertimeDefault)
 	settings.RefreshTimeUi = a.Preferences().IntWithFallback(refreshTimeUiProperty, refreshTimeUiDefault)
 	settings.ThemeVariant = a.Preferences().IntWithFallback(themeVariantProperty, themeVariantDefault)
+	settings.AutoClock = a.Preferences().Bool(autoClockProperty)
+	settings.Autostart = a.Preferences().Bool(autostartProperty)
+	settings.IdleDetection = a.Preferences().Bool(idleDetectionProperty)
+	settings.IdleMinutes = a.Preferences().IntWithFallback(idleMinutesProperty, idleMinutesDefault)
 	settings.LockImportOvertime = a.Preferences().BoolWithFallback("lockImportOvertime", false)
 
+	// Target hours are stored in the order of model.Weekdays
+	targetHours := a.Preferences().FloatList(targetHoursProperty)
+	if len(targetHours) == len(model.Weekdays) {
+		for i, wd := range model.Weekdays {
+			settings.TargetHours[wd] = targetHours[i]
+		}
+	} else {
+		settings.TargetHours = model.DefaultTargetHours(float64(settings.WeekHours))
+	}
+
+	settings.HolidayRegion = a.Preferences().String(holidayRegionProperty)
+	settings.CurrentProject = int64(a.Preferences().Int(currentProjectProperty))
+	settings.CurrentTask = int64(a.Preferences().Int(currentTaskProperty))
+	settings.CarryOverCutoff = a.Preferences().StringWithFallback(carryOverCutoffProperty, carryOverCutoffDefault)
+	settings.EmployeeName = a.Preferences().String(employeeNameProperty)
+
+	settings.NotifyTargetReached = a.Preferences().BoolWithFallback(notifyTargetProperty, true)
+	settings.NotifyNoBreak = a.Preferences().BoolWithFallback(notifyNoBreakProperty, true)
+	settings.NotifyLongDay = a.Preferences().BoolWithFallback(notifyLongDayProperty, true)
+	settings.NotifyClockOut = a.Preferences().BoolWithFallback(notifyClockOutProperty, true)
+	settings.NotifyBudget = a.Preferences().BoolWithFallback(notifyBudgetProperty, true)
+	settings.LongDayHours = a.Preferences().FloatWithFallback(longDayHoursProperty, longDayHoursDefault)
+	settings.ClockOutHour = a.Preferences().IntWithFallback(clockOutHourProperty, clockOutHourDefault)
+
+	settings.BreakPolicy = model.BreakPolicy(a.Preferences().StringWithFallback(breakPolicyProperty, string(breakPolicyDefault)))
+	if settings.BreakPolicy == model.BreakPolicyCustom {
+		rules, err := model.ParseBreakRules(a.Preferences().String(breakRulesProperty))
+		if err != nil {
+			log.Error("Invalid custom break rules, using default", "error", err)
+			settings.BreakPolicy = breakPolicyDefault
+			rules = model.BreakRulesForPolicy(breakPolicyDefault)
+		}
+		settings.BreakRules = rules
+	} else {
+		settings.BreakRules = model.BreakRulesForPolicy(settings.BreakPolicy)
+	}
+
 	return settings
 }
 
@@ -61,7 +126,30 @@ func WriteProperties(a fyne.App, s *model.Settings) {
 	a.Preferences().SetFloat("importOvertime", s.ImportOvertime)
 	a.Preferences().SetInt("refreshTimeUi", s.RefreshTimeUi)
 	a.Preferences().SetInt("themeVariant", s.ThemeVariant)
+	a.Preferences().SetBool(autoClockProperty, s.AutoClock)
+	a.Preferences().SetBool(autostartProperty, s.Autostart)
+	a.Preferences().SetBool(idleDetectionProperty, s.IdleDetection)
+	a.Preferences().SetInt(idleMinutesProperty, s.IdleMinutes)
 	a.Preferences().SetBool("lockImportOvertime", s.LockImportOvertime)
+	targetHours := make([]float64, 0, len(model.Weekdays))
+	for _, wd := range model.Weekdays {
+		targetHours = append(targetHours, s.TargetHours[wd])
+	}
+	a.Preferences().SetFloatList(targetHoursProperty, targetHours)
+	a.Preferences().SetString(holidayRegionProperty, s.HolidayRegion)
+	a.Preferences().SetInt(currentProjectProperty, int(s.CurrentProject))
+	a.Preferences().SetInt(currentTaskProperty, int(s.CurrentTask))
+	a.Preferences().SetString(carryOverCutoffProperty, s.CarryOverCutoff)
+	a.Preferences().SetString(employeeNameProperty, s.EmployeeName)
+	a.Preferences().SetBool(notifyTargetProperty, s.NotifyTargetReached)
+	a.Preferences().SetBool(notifyNoBreakProperty, s.NotifyNoBreak)
+	a.Preferences().SetBool(notifyLongDayProperty, s.NotifyLongDay)
+	a.Preferences().SetBool(notifyClockOutProperty, s.NotifyClockOut)
+	a.Preferences().SetBool(notifyBudgetProperty, s.NotifyBudget)
+	a.Preferences().SetFloat(longDayHoursProperty, s.LongDayHours)
+	a.Preferences().SetInt(clockOutHourProperty, s.ClockOutHour)
+	a.Preferences().SetString(breakPolicyProperty, string(s.BreakPolicy))
+	a.Preferences().SetString(breakRulesProperty, model.FormatBreakRules(s.BreakRules))
 }
 
 /**
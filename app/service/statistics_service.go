@@ -0,0 +1,170 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// Days with more worked time are counted as long days
+const longDayThreshold = 10 * time.Hour
+
+type StatisticsPeriod string
+
+const (
+	StatisticsWeek  StatisticsPeriod = "week"
+	StatisticsMonth StatisticsPeriod = "month"
+	StatisticsYear  StatisticsPeriod = "year"
+)
+
+var StatisticsPeriods = []StatisticsPeriod{StatisticsWeek, StatisticsMonth, StatisticsYear}
+
+// StatisticsBucket sums the workdays of a week, month or year
+type StatisticsBucket struct {
+	// First day of the period
+	Start    time.Time
+	Worked   time.Duration
+	Target   time.Duration
+	Overtime time.Duration
+	Days     int
+}
+
+// BalancePoint is the running overtime total after a workday
+type BalancePoint struct {
+	Date    time.Time
+	Balance time.Duration
+}
+
+// Statistics are the aggregates of the workdays in a date range
+type Statistics struct {
+	Buckets []*StatisticsBucket
+	Trend   []*BalancePoint
+
+	// Number of workdays with worked time
+	Days int
+
+	// Average first Begin and last End as time since midnight
+	AverageBegin time.Duration
+	AverageEnd   time.Duration
+	AverageBreak time.Duration
+
+	LongestDay      time.Time
+	LongestWorktime time.Duration
+	// Days with more than 10 hours of worked time
+	LongDays int
+
+	// Average worked time per weekday, the index is the time.Weekday
+	WeekdayAverage [7]time.Duration
+	WeekdayDays    [7]int
+}
+
+// Calculates the statistics of the workdays between both dates including
+func (ts *TimeEntryService) Statistics(settings *model.Settings, from time.Time, to time.Time, period StatisticsPeriod) (*Statistics, error) {
+	workdays, worktimes, byWorkday, err := ts.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	result := ts.NewCalculator(settings).Calculate(workdays, worktimes)
+	return CalculateStatistics(result, byWorkday, from, to, period, settings.FirstDayOfWeek.TimeWeekday()), nil
+}
+
+/*
+Aggregates the calculated days between both dates including. Days without
+worked time, e.g. an open entry of today, only count for the trend.
+*/
+func CalculateStatistics(result *CalculationResult, worktimes map[int64][]*db.Worktime,
+	from time.Time, to time.Time, period StatisticsPeriod, firstDayOfWeek time.Weekday) *Statistics {
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	stats := &Statistics{}
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	buckets := make(map[string]*StatisticsBucket)
+	var begins, ends, breaks time.Duration
+	var weekdayTotals [7]time.Duration
+	for _, day := range result.Days {
+		date := day.Workday.Date.Format(time.DateOnly)
+		if date < first || date > last {
+			continue
+		}
+		stats.Trend = append(stats.Trend, &BalancePoint{Date: dateOnly(day.Workday.Date), Balance: day.Balance})
+
+		wts := closedWorktimes(worktimes[day.Workday.ID])
+		if day.Worktime <= 0 || len(wts) == 0 {
+			continue
+		}
+
+		start := periodStart(dateOnly(day.Workday.Date), period, firstDayOfWeek)
+		bucket, ok := buckets[start.Format(time.DateOnly)]
+		if !ok {
+			bucket = &StatisticsBucket{Start: start}
+			buckets[start.Format(time.DateOnly)] = bucket
+			stats.Buckets = append(stats.Buckets, bucket)
+		}
+		bucket.Worked += day.Worktime
+		bucket.Target += day.Target
+		bucket.Overtime += day.Overtime
+		bucket.Days++
+
+		stats.Days++
+		begins += sinceMidnight(wts[0].Time.In(loc))
+		ends += sinceMidnight(wts[len(wts)-1].Time.In(loc))
+		breaks += day.Breaktime
+		if day.Worktime > stats.LongestWorktime {
+			stats.LongestWorktime = day.Worktime
+			stats.LongestDay = dateOnly(day.Workday.Date)
+		}
+		if day.Worktime > longDayThreshold {
+			stats.LongDays++
+		}
+		weekday := day.Workday.Date.Weekday()
+		weekdayTotals[weekday] += day.Worktime
+		stats.WeekdayDays[weekday]++
+	}
+
+	sort.SliceStable(stats.Buckets, func(i, j int) bool {
+		return stats.Buckets[i].Start.Before(stats.Buckets[j].Start)
+	})
+	if stats.Days > 0 {
+		n := time.Duration(stats.Days)
+		stats.AverageBegin = (begins / n).Round(time.Minute)
+		stats.AverageEnd = (ends / n).Round(time.Minute)
+		stats.AverageBreak = (breaks / n).Round(time.Minute)
+	}
+	for i, days := range stats.WeekdayDays {
+		if days > 0 {
+			stats.WeekdayAverage[i] = (weekdayTotals[i] / time.Duration(days)).Round(time.Minute)
+		}
+	}
+	return stats
+}
+
+// Returns the first day of the period which contains the date
+func periodStart(date time.Time, period StatisticsPeriod, firstDayOfWeek time.Weekday) time.Time {
+	switch period {
+	case StatisticsYear:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	case StatisticsMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	offset := (int(date.Weekday()) - int(firstDayOfWeek) + 7) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+// Returns the time entries sorted by time without an open Begin at the end
+func closedWorktimes(worktimes []*db.Worktime) []*db.Worktime {
+	sorted := make([]*db.Worktime, len(worktimes))
+	copy(sorted, worktimes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+	if len(sorted)%2 != 0 {
+		sorted = sorted[:len(sorted)-1]
+	}
+	return sorted
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
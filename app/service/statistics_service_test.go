@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+func TestCalculateStatistics(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day int, clock string) time.Time {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2025, 8, day, c.Hour(), c.Minute(), 0, 0, loc)
+	}
+
+	result := &CalculationResult{}
+	worktimes := make(map[int64][]*db.Worktime)
+	addDay := func(day int, worked time.Duration, breaktime time.Duration, balance time.Duration, begin string, end string) {
+		wd := &db.Workday{ID: int64(day), Date: time.Date(2025, 8, day, 0, 0, 0, 0, loc)}
+		result.Days = append(result.Days, &DayResult{
+			Workday:   wd,
+			Worktime:  worked,
+			Breaktime: breaktime,
+			Target:    8 * time.Hour,
+			Overtime:  worked - 8*time.Hour,
+			Balance:   balance,
+		})
+		worktimes[wd.ID] = []*db.Worktime{
+			{Type: "End", Time: at(day, end)},
+			{Type: "Begin", Time: at(day, begin)},
+		}
+	}
+	// Monday to Wednesday of one week and the Monday of the next week
+	addDay(4, 8*time.Hour, 30*time.Minute, 0, "08:00", "16:30")
+	addDay(5, 10*time.Hour+30*time.Minute, 45*time.Minute, 2*time.Hour+30*time.Minute, "07:00", "18:15")
+	addDay(6, 7*time.Hour, 30*time.Minute, time.Hour+30*time.Minute, "09:00", "16:30")
+	addDay(11, 9*time.Hour, 60*time.Minute, 2*time.Hour+30*time.Minute, "08:00", "18:00")
+	// Outside of the range
+	addDay(20, 12*time.Hour, 0, 0, "06:00", "18:00")
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	stats := CalculateStatistics(result, worktimes, from, to, StatisticsWeek, time.Monday)
+
+	if stats.Days != 4 || len(stats.Trend) != 4 {
+		t.Fatalf("days = %d, trend = %d, want 4", stats.Days, len(stats.Trend))
+	}
+	if len(stats.Buckets) != 2 || stats.Buckets[0].Days != 3 || stats.Buckets[0].Worked != 25*time.Hour+30*time.Minute {
+		t.Errorf("unexpected buckets %+v", stats.Buckets)
+	}
+	if !stats.Buckets[1].Start.Equal(time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("second week starts %s", stats.Buckets[1].Start)
+	}
+	if stats.AverageBegin != 8*time.Hour || stats.AverageEnd != 17*time.Hour+19*time.Minute {
+		t.Errorf("average begin %s, end %s", stats.AverageBegin, stats.AverageEnd)
+	}
+	if stats.AverageBreak != 41*time.Minute {
+		t.Errorf("average break = %s", stats.AverageBreak)
+	}
+	if stats.LongDays != 1 || stats.LongestWorktime != 10*time.Hour+30*time.Minute || stats.LongestDay.Day() != 5 {
+		t.Errorf("longest %s on %s, %d long days", stats.LongestWorktime, stats.LongestDay, stats.LongDays)
+	}
+	if stats.WeekdayDays[time.Monday] != 2 || stats.WeekdayAverage[time.Monday] != 8*time.Hour+30*time.Minute {
+		t.Errorf("monday average %s of %d days", stats.WeekdayAverage[time.Monday], stats.WeekdayDays[time.Monday])
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	// Thursday
+	date := time.Date(2025, 8, 7, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period   StatisticsPeriod
+		firstDay time.Weekday
+		want     string
+	}{
+		{StatisticsWeek, time.Monday, "2025-08-04"},
+		{StatisticsWeek, time.Sunday, "2025-08-03"},
+		{StatisticsWeek, time.Thursday, "2025-08-07"},
+		{StatisticsMonth, time.Monday, "2025-08-01"},
+		{StatisticsYear, time.Monday, "2025-01-01"},
+	}
+	for _, tt := range tests {
+		if got := periodStart(date, tt.period, tt.firstDay).Format(time.DateOnly); got != tt.want {
+			t.Errorf("periodStart(%s, %s) = %s, want %s", tt.period, tt.firstDay, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/charmbracelet/log"
+)
+
+var (
+	ErrAlreadyClockedIn = errors.New("already clocked in")
+	ErrNotClockedIn     = errors.New("not clocked in")
+	ErrInvalidBreak     = errors.New("the break is not within the open time entry")
+	ErrMissingBegin     = errors.New("the time entry has no begin")
+	ErrEndNotAfterBegin = errors.New("the end is not after the begin")
+	ErrEntriesOverlap   = errors.New("the time entries overlap")
+	ErrOpenEntry        = errors.New("only the last time entry may be open")
+	ErrOtherDay         = errors.New("the time entry is not on the edited day")
+	ErrFutureEntry      = errors.New("the time entry is in the future")
+)
+
+// TimeEntryService records time entries and calculates the overtime for the GUI and the CLI
+type TimeEntryService struct {
+	repo *repo.SQLiteRepository
+}
+
+func NewTimeEntryService(repo *repo.SQLiteRepository) *TimeEntryService {
+	return &TimeEntryService{repo: repo}
+}
+
+// DayStatus holds the time entries of a day and whether the day is still open
+type DayStatus struct {
+	// Nil if nothing was recorded on the day
+	Workday *db.Workday
+	// Time entries sorted by time
+	Worktimes []*db.Worktime
+	// The last Begin entry without End or nil
+	Open *db.Worktime
+	// Figures of the day, an open entry counts until the time of the status
+	Result *DayResult
+}
+
+// DayEntry is a Begin/End pair of a day, the End is nil while the entry is open
+type DayEntry struct {
+	Begin *db.Worktime
+	End   *db.Worktime
+}
+
+// Returns the time entries of the day as Begin/End pairs, an End without Begin gets its own pair
+func (ts *TimeEntryService) DayEntries(date time.Time) ([]*DayEntry, error) {
+	workday, err := ts.repo.GetWorkday(date)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []*DayEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	worktimes, err := ts.repo.GetAllWorktime(workday)
+	if err != nil {
+		return nil, err
+	}
+	return pairWorktimes(worktimes), nil
+}
+
+/*
+Checks that the entries are on the day, each End is after its Begin and the
+entries do not overlap. Only the last entry may be open and nothing may be
+later than now.
+*/
+func ValidateDay(date time.Time, entries []*DayEntry, now time.Time) error {
+	day := dateOnly(date)
+	var last time.Time
+	for i, e := range entries {
+		if e.Begin == nil {
+			return ErrMissingBegin
+		}
+		if e.End == nil && i < len(entries)-1 {
+			return ErrOpenEntry
+		}
+		for _, wt := range []*db.Worktime{e.Begin, e.End} {
+			if wt == nil {
+				continue
+			}
+			if dateOnly(wt.Time) != day {
+				return ErrOtherDay
+			}
+			if wt.Time.After(now) {
+				return ErrFutureEntry
+			}
+		}
+		if e.End != nil && !e.End.Time.After(e.Begin.Time) {
+			return ErrEndNotAfterBegin
+		}
+		if i > 0 && e.Begin.Time.Before(last) {
+			return ErrEntriesOverlap
+		}
+		last = e.Begin.Time
+		if e.End != nil {
+			last = e.End.Time
+		}
+	}
+	return nil
+}
+
+/*
+Validates the entries and stores them as the time entries of the day in a
+single transaction, the workday is created if it does not exist yet. The
+entries must be sorted by time.
+*/
+func (ts *TimeEntryService) SaveDay(date time.Time, entries []*DayEntry, reason string) error {
+	if err := ValidateDay(date, entries, time.Now()); err != nil {
+		return err
+	}
+
+	worktimes := []*db.Worktime{}
+	for _, e := range entries {
+		e.Begin.Type = "Begin"
+		worktimes = append(worktimes, e.Begin)
+		if e.End != nil {
+			e.End.Type = "End"
+			worktimes = append(worktimes, e.End)
+		}
+	}
+	_, err := ts.repo.SaveWorkday(date, worktimes, reason)
+	return err
+}
+
+// Adds a Begin or End entry at the given time, alternating with the entries of the day
+func (ts *TimeEntryService) Toggle(at time.Time) (*db.Worktime, error) {
+	workday, worktimes, err := ts.workdayOf(at)
+	if err != nil {
+		return nil, err
+	}
+
+	wtType := "Begin"
+	if len(worktimes)%2 != 0 {
+		wtType = "End"
+	}
+	return ts.addWorktime(&db.Worktime{Type: wtType, Workday: *workday}, at, true)
+}
+
+// Adds a Begin entry at the given time, the day must not have an open entry
+func (ts *TimeEntryService) ClockIn(at time.Time) (*db.Worktime, error) {
+	return ts.clockIn(at, 0, 0, true)
+}
+
+// Adds a Begin entry of the project and task at the given time, the day must not have an open entry
+func (ts *TimeEntryService) ClockInProject(at time.Time, projectID int64, taskID int64) (*db.Worktime, error) {
+	return ts.clockIn(at, projectID, taskID, true)
+}
+
+// Adds an End entry at the given time, the day must have an open entry
+func (ts *TimeEntryService) ClockOut(at time.Time) (*db.Worktime, error) {
+	return ts.clockOut(at, true)
+}
+
+// Entries which are not undoable were recorded automatically, e.g. by the session
+func (ts *TimeEntryService) clockIn(at time.Time, projectID int64, taskID int64, undoable bool) (*db.Worktime, error) {
+	workday, worktimes, err := ts.workdayOf(at)
+	if err != nil {
+		return nil, err
+	}
+	if len(worktimes)%2 != 0 {
+		return nil, ErrAlreadyClockedIn
+	}
+	return ts.addWorktime(&db.Worktime{Type: "Begin", Workday: *workday, ProjectID: projectID, TaskID: taskID}, at, undoable)
+}
+
+func (ts *TimeEntryService) clockOut(at time.Time, undoable bool) (*db.Worktime, error) {
+	workday, worktimes, err := ts.workdayOf(at)
+	if err != nil {
+		return nil, err
+	}
+	if len(worktimes)%2 == 0 {
+		return nil, ErrNotClockedIn
+	}
+	return ts.addWorktime(&db.Worktime{Type: "End", Workday: *workday}, at, undoable)
+}
+
+/*
+Records a break as End and Begin pair within the open entry of the day,
+e.g. an idle period. The open entry stays open after the break. Both
+entries are written at once and undone together.
+*/
+func (ts *TimeEntryService) RecordBreak(start time.Time, end time.Time) error {
+	workday, worktimes, err := ts.workdayOf(start)
+	if err != nil {
+		return err
+	}
+	if len(worktimes)%2 == 0 {
+		return ErrNotClockedIn
+	}
+	open := sortedWorktimes(worktimes)[len(worktimes)-1]
+	if !start.After(open.Time) || !end.After(start) || dateOnly(start) != dateOnly(end) {
+		return ErrInvalidBreak
+	}
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	return ts.repo.AddWorktimes([]*db.Worktime{
+		{Type: "End", Time: start.In(loc), Workday: *workday},
+		{Type: "Begin", Time: end.In(loc), Workday: *workday},
+	})
+}
+
+/*
+Switches the project of the running segment at the given time. The running
+segment is closed and a new one with the project and task is opened, if
+nothing is running only the new segment is opened. The id 0 means no project.
+The switch is not part of the undo history.
+*/
+func (ts *TimeEntryService) SwitchProject(at time.Time, projectID int64, taskID int64) (*db.Worktime, error) {
+	workday, worktimes, err := ts.workdayOf(at)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	begin := &db.Worktime{Type: "Begin", Time: at.In(loc), Workday: *workday, ProjectID: projectID, TaskID: taskID}
+	entries := []*db.Worktime{begin}
+	if len(worktimes)%2 != 0 {
+		end := &db.Worktime{Type: "End", Time: at.In(loc), Workday: *workday}
+		entries = []*db.Worktime{end, begin}
+	}
+	if err := ts.repo.RecordWorktimes(entries); err != nil {
+		return nil, err
+	}
+	return begin, nil
+}
+
+// Returns the time entries and figures of the day of the given time
+func (ts *TimeEntryService) Status(settings *model.Settings, at time.Time) (*DayStatus, error) {
+	workday, err := ts.repo.GetWorkday(at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &DayStatus{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	worktimes, err := ts.repo.GetAllWorktime(workday)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(worktimes, func(i, j int) bool {
+		return worktimes[i].Time.Before(worktimes[j].Time)
+	})
+
+	status := &DayStatus{Workday: workday, Worktimes: worktimes}
+	calculated := worktimes
+	if len(worktimes)%2 != 0 {
+		status.Open = worktimes[len(worktimes)-1]
+		// The open entry is closed at the time of the status
+		calculated = append(calculated[:len(calculated):len(calculated)],
+			&db.Worktime{Type: "End", Time: at, Workday: *workday})
+	}
+
+	status.Result = ts.NewCalculator(settings).Calculate([]*db.Workday{workday}, calculated).Days[0]
+	return status, nil
+}
+
+// Calculates the figures of all workdays like the overtime of the timetable
+func (ts *TimeEntryService) Calculate(settings *model.Settings) (*CalculationResult, error) {
+	workdays, worktimes, _, err := ts.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	return ts.NewCalculator(settings).Calculate(workdays, worktimes), nil
+}
+
+// Returns all workdays ascending and their time entries, also grouped by workday id
+func (ts *TimeEntryService) loadAll() ([]*db.Workday, []*db.Worktime, map[int64][]*db.Worktime, error) {
+	workdays, err := ts.repo.GetAllWorkday(repo.ASC)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	worktimes, err := ts.repo.GetAllWorktimes()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	byWorkday := make(map[int64][]*db.Worktime, len(workdays))
+	for _, wt := range worktimes {
+		byWorkday[wt.Workday.ID] = append(byWorkday[wt.Workday.ID], wt)
+	}
+	return workdays, worktimes, byWorkday, nil
+}
+
+// Creates a calculator with the contracts, absences and holidays from the database
+func (ts *TimeEntryService) NewCalculator(settings *model.Settings) *Calculator {
+	contracts, err := ts.repo.GetAllContract()
+	if err != nil {
+		log.Error(err)
+	}
+	calc := NewCalculator(settings, contracts)
+
+	calc.Absences, err = ts.repo.GetAllAbsence()
+	if err != nil {
+		log.Error(err)
+	}
+	calc.Holidays = ts.NewHolidayCalendar(settings)
+	return calc
+}
+
+// Creates the holiday calendar of the configured region with the imported holidays
+func (ts *TimeEntryService) NewHolidayCalendar(settings *model.Settings) *HolidayCalendar {
+	imported, err := ts.repo.GetAllHoliday()
+	if err != nil {
+		log.Error(err)
+	}
+	return NewHolidayCalendar(settings.HolidayRegion, imported)
+}
+
+/*
+Returns the workday of the given time with its entries. A new workday has
+no id yet, it is created together with its first entry.
+*/
+func (ts *TimeEntryService) workdayOf(at time.Time) (*db.Workday, []*db.Worktime, error) {
+	workday, err := ts.repo.GetWorkday(at)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Debug("New workday", "date", at)
+		loc, _ := time.LoadLocation("Europe/Berlin")
+		return &db.Workday{Date: at.In(loc)}, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	worktimes, err := ts.repo.GetAllWorktime(workday)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workday, worktimes, nil
+}
+
+// Pairs the sorted worktimes as Begin/End entries
+func pairWorktimes(worktimes []*db.Worktime) []*DayEntry {
+	entries := []*DayEntry{}
+	for _, wt := range sortedWorktimes(worktimes) {
+		if wt.Type == "End" && len(entries) > 0 && entries[len(entries)-1].End == nil {
+			entries[len(entries)-1].End = wt
+		} else if wt.Type == "End" {
+			entries = append(entries, &DayEntry{End: wt})
+		} else {
+			entries = append(entries, &DayEntry{Begin: wt})
+		}
+	}
+	return entries
+}
+
+// Adds the worktime at the given time, only an undoable worktime is in the undo history
+func (ts *TimeEntryService) addWorktime(wt *db.Worktime, at time.Time, undoable bool) (*db.Worktime, error) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	wt.Time = at.In(loc)
+	if undoable {
+		return ts.repo.AddWorktime(wt)
+	}
+	if err := ts.repo.RecordWorktimes([]*db.Worktime{wt}); err != nil {
+		return nil, err
+	}
+	return wt, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestTimeEntryService(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	settings := model.NewSettings("", "")
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	at := func(clock string) time.Time {
+		d, err := time.ParseInLocation(time.DateTime, "2025-08-04 "+clock, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	if _, err := ts.ClockOut(at("07:00:00")); !errors.Is(err, ErrNotClockedIn) {
+		t.Errorf("ClockOut() without entries error = %v", err)
+	}
+	if _, err := ts.ClockIn(at("08:00:00")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ClockIn(at("08:01:00")); !errors.Is(err, ErrAlreadyClockedIn) {
+		t.Errorf("ClockIn() twice error = %v", err)
+	}
+
+	// The open entry counts until the time of the status
+	status, err := ts.Status(settings, at("12:00:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Open == nil || status.Result.Worktime != 4*time.Hour {
+		t.Errorf("unexpected open status %+v %+v", status, status.Result)
+	}
+
+	if wt, err := ts.Toggle(at("16:30:00")); err != nil || wt.Type != "End" {
+		t.Fatalf("Toggle() = %+v, %v", wt, err)
+	}
+	status, err = ts.Status(settings, at("18:00:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Open != nil || len(status.Worktimes) != 2 ||
+		status.Result.Worktime != 8*time.Hour || status.Result.Overtime != 0 {
+		t.Errorf("unexpected closed status %+v %+v", status, status.Result)
+	}
+
+	result, err := ts.Calculate(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Days) != 1 || result.Total != 0 {
+		t.Errorf("unexpected calculation %+v", result)
+	}
+
+	// Nothing recorded on the next day
+	status, err = ts.Status(settings, at("12:00:00").AddDate(0, 0, 1))
+	if err != nil || status.Workday != nil {
+		t.Errorf("Status() of an empty day = %+v, %v", status, err)
+	}
+}
+
+func TestValidateDay(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	day := time.Date(2025, 8, 4, 0, 0, 0, 0, loc)
+	now := day.Add(18 * time.Hour)
+	wt := func(hour int) *db.Worktime {
+		return &db.Worktime{Time: day.Add(time.Duration(hour) * time.Hour)}
+	}
+
+	tests := []struct {
+		name    string
+		entries []*DayEntry
+		want    error
+	}{
+		{"empty", nil, nil},
+		{"pairs", []*DayEntry{{wt(8), wt(12)}, {wt(12), wt(16)}}, nil},
+		{"open last", []*DayEntry{{wt(8), wt(12)}, {wt(13), nil}}, nil},
+		{"open first", []*DayEntry{{wt(8), nil}, {wt(13), wt(14)}}, ErrOpenEntry},
+		{"end before begin", []*DayEntry{{wt(12), wt(8)}}, ErrEndNotAfterBegin},
+		{"no begin", []*DayEntry{{nil, wt(8)}}, ErrMissingBegin},
+		{"overlap", []*DayEntry{{wt(8), wt(12)}, {wt(11), wt(14)}}, ErrEntriesOverlap},
+		{"other day", []*DayEntry{{wt(8), wt(25)}}, ErrOtherDay},
+		{"future", []*DayEntry{{wt(17), wt(19)}}, ErrFutureEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDay(day, tt.entries, now); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateDay() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveDay(t *testing.T) {
+	ts := NewTimeEntryService(repotest.NewRepository(t))
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	day := time.Date(2025, 8, 4, 0, 0, 0, 0, loc)
+	wt := func(hour int) *db.Worktime {
+		return &db.Worktime{Time: day.Add(time.Duration(hour) * time.Hour)}
+	}
+
+	// A past day is added with two entries
+	if err := ts.SaveDay(day, []*DayEntry{{wt(8), wt(12)}, {wt(13), wt(17)}}, "Forgot to clock in"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ts.DayEntries(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Begin.Type != "Begin" || entries[1].End.Time.Hour() != 17 {
+		t.Fatalf("got entries %+v", entries)
+	}
+
+	// The second entry is removed, an invalid change is not saved
+	if err := ts.SaveDay(day, []*DayEntry{entries[0], {wt(11), wt(14)}}, ""); !errors.Is(err, ErrEntriesOverlap) {
+		t.Fatalf("got %v, want ErrEntriesOverlap", err)
+	}
+	if err := ts.SaveDay(day, entries[:1], ""); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = ts.DayEntries(day); err != nil || len(entries) != 1 {
+		t.Errorf("got %d entries, want 1 (%v)", len(entries), err)
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/go-pdf/fpdf"
+)
+
+// Timesheet holds every day of a month for a signed report
+type Timesheet struct {
+	Employee string
+	// First day of the month
+	Month time.Time
+	Days  []*TimesheetDay
+
+	// Sums of the month
+	Worktime  time.Duration
+	Breaktime time.Duration
+	Target    time.Duration
+	Overtime  time.Duration
+	// Running overtime total at the end of the month
+	Balance time.Duration
+
+	// Only filled if the correction history is printed
+	Corrections []*ExportCorrection
+}
+
+// TimesheetDay is a day of the month, with or without a workday
+type TimesheetDay struct {
+	Date     time.Time
+	Segments []*ExportSegment
+
+	Worktime  time.Duration
+	Breaktime time.Duration
+	Target    time.Duration
+	Overtime  time.Duration
+	Balance   time.Duration
+
+	Absences []db.AbsenceType
+	// Name of the public holiday or empty
+	Holiday string
+}
+
+// TimesheetLabels are the translated texts of the PDF
+type TimesheetLabels struct {
+	Title     string
+	Employee  string
+	Date      string
+	Times     string
+	Break     string
+	Worked    string
+	Target    string
+	Overtime  string
+	Balance   string
+	Notes     string
+	Total     string
+	Signature string
+	// Signature of the supervisor
+	Approval string
+	// Short weekday names starting with Sunday
+	Weekdays [7]string
+	Absence  func(db.AbsenceType) string
+
+	// Correction history
+	Corrections string
+	ChangedAt   string
+	Change      string
+	Reason      string
+	// Marks corrections which are not in effect anymore
+	Undone string
+	Action func(db.AuditAction) string
+}
+
+// TimesheetService creates the monthly timesheets
+type TimesheetService struct {
+	ts *TimeEntryService
+}
+
+func NewTimesheetService(repo *repo.SQLiteRepository) *TimesheetService {
+	return &TimesheetService{ts: NewTimeEntryService(repo)}
+}
+
+/*
+Collects the days of the month. The figures are calculated over all
+workdays like the timetable, so the balance continues the previous month.
+Compensation days without a workday reduce the balance when they are reached.
+*/
+func (tss *TimesheetService) Timesheet(settings *model.Settings, year int, month time.Month) (*Timesheet, error) {
+	workdays, worktimes, byWorkday, err := tss.ts.loadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	calc := tss.ts.NewCalculator(settings)
+	result := calc.Calculate(workdays, worktimes)
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	days := make(map[string]*DayResult, len(result.Days))
+	// Balance before the month including the compensation days after the last workday
+	balance := time.Duration(settings.ImportOvertime * float64(time.Hour))
+	var lastWorkday time.Time
+	for _, r := range result.Days {
+		days[r.Workday.Date.Format(time.DateOnly)] = r
+		if dateOnly(r.Workday.Date).Before(first) {
+			balance = r.Balance
+			lastWorkday = dateOnly(r.Workday.Date)
+		}
+	}
+	for _, d := range calc.compTimeDays(workdays, first.AddDate(0, 0, -1)) {
+		if d.After(lastWorkday) {
+			balance -= tss.compTime(calc, d)
+		}
+	}
+
+	sheet := &Timesheet{Employee: settings.EmployeeName, Month: first}
+	today := dateOnly(time.Now())
+	for d := first; d.Month() == month; d = d.AddDate(0, 0, 1) {
+		day := &TimesheetDay{Date: d, Segments: []*ExportSegment{}}
+		if h := calc.Holidays.HolidayOn(d); h != nil {
+			day.Holiday = h.Name
+		}
+		for _, a := range calc.Absences {
+			// Like the absence days, days without target time are skipped
+			if a.DayShare(d) > 0 && calc.TargetFor(d) > 0 && !containsAbsence(day.Absences, a.Type) {
+				day.Absences = append(day.Absences, a.Type)
+			}
+		}
+
+		if r, ok := days[d.Format(time.DateOnly)]; ok {
+			day.Segments = exportSegments(byWorkday[r.Workday.ID])
+			day.Worktime = r.Worktime
+			day.Breaktime = r.Breaktime
+			day.Target = r.Target
+			day.Overtime = r.Overtime
+			balance = r.Balance
+		} else if !d.After(today) && calc.AbsenceShare(d, AbsenceEffectCompTime) > 0 {
+			day.Overtime = -tss.compTime(calc, d)
+			balance += day.Overtime
+		}
+		day.Balance = balance
+
+		sheet.Worktime += day.Worktime
+		sheet.Breaktime += day.Breaktime
+		sheet.Target += day.Target
+		sheet.Overtime += day.Overtime
+		sheet.Days = append(sheet.Days, day)
+	}
+	sheet.Balance = balance
+	return sheet, nil
+}
+
+// Returns the overtime consumed by a compensation day without a workday
+func (tss *TimesheetService) compTime(calc *Calculator, date time.Time) time.Duration {
+	return time.Duration(float64(calc.TargetFor(date)) *
+		calc.AbsenceShare(date, AbsenceEffectCompTime)).Round(time.Second)
+}
+
+func containsAbsence(types []db.AbsenceType, t db.AbsenceType) bool {
+	for _, at := range types {
+		if at == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Widths of the timesheet columns in mm, they fill the width of an A4 page
+var timesheetColumns = []float64{24, 46, 15, 16, 16, 18, 18, 37}
+
+/*
+Renders the timesheet as A4 PDF with a signature line. Only the built-in
+fonts are used, so no font files are needed.
+*/
+func WriteTimesheetPDF(w io.Writer, sheet *Timesheet, labels TimesheetLabels) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 12, 10)
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AddPage()
+	// The built-in fonts use cp1252, which covers umlauts
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, tr(labels.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 7, tr(labels.Employee+": "+sheet.Employee), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	header := []string{labels.Date, labels.Times, labels.Break, labels.Worked,
+		labels.Target, labels.Overtime, labels.Balance, labels.Notes}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(225, 225, 225)
+	for i, h := range header {
+		pdf.CellFormat(timesheetColumns[i], 7, tr(h), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetFillColor(242, 242, 242)
+	for _, day := range sheet.Days {
+		// Weekends and holidays are shaded
+		fill := day.Holiday != "" || day.Date.Weekday() == time.Saturday || day.Date.Weekday() == time.Sunday
+		hasWork := len(day.Segments) > 0
+
+		var notes []string
+		if day.Holiday != "" {
+			notes = append(notes, day.Holiday)
+		}
+		for _, a := range day.Absences {
+			notes = append(notes, labels.Absence(a))
+		}
+
+		row := []string{
+			labels.Weekdays[day.Date.Weekday()] + " " + day.Date.Format("02.01."),
+			formatSegments(day.Segments),
+			formatOptionalClock(day.Breaktime, hasWork),
+			formatOptionalClock(day.Worktime, hasWork),
+			formatOptionalClock(day.Target, hasWork),
+			formatOptionalClock(day.Overtime, hasWork || day.Overtime != 0),
+			formatClock(day.Balance),
+			strings.Join(notes, ", "),
+		}
+		for i, text := range row {
+			align := "R"
+			if i == 0 || i == 1 || i == len(row)-1 {
+				align = "L"
+			}
+			pdf.CellFormat(timesheetColumns[i], 6, fitText(pdf, tr(text), timesheetColumns[i]-2), "1", 0, align, fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetFont("Helvetica", "B", 8)
+	totals := []string{labels.Total, "", formatClock(sheet.Breaktime), formatClock(sheet.Worktime),
+		formatClock(sheet.Target), formatClock(sheet.Overtime), formatClock(sheet.Balance), ""}
+	for i, text := range totals {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(timesheetColumns[i], 7, tr(text), "1", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	if len(sheet.Corrections) > 0 {
+		writeCorrectionsPDF(pdf, tr, sheet.Corrections, labels)
+	}
+	pdf.Ln(15)
+
+	// Signature lines of the employee and the supervisor
+	pdf.SetFont("Helvetica", "", 9)
+	y := pdf.GetY()
+	pdf.Line(10, y, 95, y)
+	pdf.Line(115, y, 200, y)
+	pdf.SetXY(10, y+1)
+	pdf.CellFormat(85, 5, tr(labels.Signature), "", 0, "L", false, 0, "")
+	pdf.SetXY(115, y+1)
+	pdf.CellFormat(85, 5, tr(labels.Approval), "", 0, "L", false, 0, "")
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// Widths of the correction columns in mm
+var correctionColumns = []float64{18, 26, 34, 78, 34}
+
+// Lists the corrections of the month below the days
+func writeCorrectionsPDF(pdf *fpdf.Fpdf, tr func(string) string, corrections []*ExportCorrection, labels TimesheetLabels) {
+	pdf.Ln(5)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, tr(labels.Corrections), "", 1, "L", false, 0, "")
+
+	header := []string{labels.Date, labels.ChangedAt, labels.Change, labels.Times, labels.Reason}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(225, 225, 225)
+	for i, h := range header {
+		pdf.CellFormat(correctionColumns[i], 7, tr(h), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 8)
+	for _, c := range corrections {
+		date, _ := time.Parse(time.DateOnly, c.Date)
+		changedAt, _ := time.Parse(time.RFC3339, c.ChangedAt)
+		change := c.Old + " -> " + c.New
+		if c.Old == "" {
+			change = c.New
+		} else if c.New == "" {
+			change = c.Old
+		}
+		action := labels.Action(c.Action)
+		if c.State == db.AuditUndone || c.State == db.AuditDropped {
+			action += " (" + labels.Undone + ")"
+		}
+
+		row := []string{date.Format("02.01."), changedAt.Format("02.01. 15:04"), action, change, c.Reason}
+		for i, text := range row {
+			pdf.CellFormat(correctionColumns[i], 6, fitText(pdf, tr(text), correctionColumns[i]-2), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// Formats a duration as h:mm, negative durations get a minus sign
+func formatClock(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Truncate(time.Minute)
+	return fmt.Sprintf("%s%d:%02d", sign, int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Formats a duration as h:mm or leaves the cell empty
+func formatOptionalClock(d time.Duration, show bool) string {
+	if !show {
+		return ""
+	}
+	return formatClock(d)
+}
+
+// Shortens a translated single byte text with dots until it fits into the width
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo/repotest"
+)
+
+func TestTimesheet(t *testing.T) {
+	r := repotest.NewRepository(t)
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, loc) }
+
+	// One hour overtime in the previous month
+	addTestWorkday(t, r, day(7, 31), "08:00", "17:00")
+	addTestWorkday(t, r, day(8, 4), "08:00", "12:00", "12:30", "16:30")
+	addTestWorkday(t, r, day(8, 5), "08:00", "18:00")
+	if _, err := r.AddAbsence(&db.Absence{Type: db.AbsenceCompTime, StartDate: day(8, 6), EndDate: day(8, 6)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddAbsence(&db.Absence{Type: db.AbsenceVacation, StartDate: day(8, 8), EndDate: day(8, 11)}); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := model.NewSettings("", "")
+	settings.EmployeeName = "Erika Mustermann"
+	settings.BreakPolicy = model.BreakPolicyNone
+	settings.BreakRules = nil
+	sheet, err := NewTimesheetService(r).Timesheet(settings, 2025, time.August)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sheet.Days) != 31 || sheet.Employee != "Erika Mustermann" {
+		t.Fatalf("unexpected timesheet with %d days for %q", len(sheet.Days), sheet.Employee)
+	}
+	tests := []struct {
+		day      int
+		overtime time.Duration
+		balance  time.Duration
+		absences int
+	}{
+		{1, 0, time.Hour, 0},
+		{4, 0, time.Hour, 0},
+		{5, 2 * time.Hour, 3 * time.Hour, 0},
+		{6, -8 * time.Hour, -5 * time.Hour, 1},
+		// Vacation on the weekend is not listed
+		{9, 0, -5 * time.Hour, 0},
+		{11, 0, -5 * time.Hour, 1},
+	}
+	for _, tt := range tests {
+		d := sheet.Days[tt.day-1]
+		if d.Overtime != tt.overtime || d.Balance != tt.balance || len(d.Absences) != tt.absences {
+			t.Errorf("day %d: overtime %s, balance %s, absences %v", tt.day, d.Overtime, d.Balance, d.Absences)
+		}
+	}
+	if sheet.Worktime != 18*time.Hour || sheet.Overtime != -6*time.Hour || sheet.Balance != -5*time.Hour {
+		t.Errorf("unexpected sums %s, %s, %s", sheet.Worktime, sheet.Overtime, sheet.Balance)
+	}
+
+	var buf bytes.Buffer
+	labels := TimesheetLabels{
+		Title:    "Stundenzettel August 2025",
+		Employee: "Mitarbeiter",
+		Notes:    "Bemerkungen",
+		Weekdays: [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		Absence:  func(t db.AbsenceType) string { return string(t) },
+	}
+	if err := WriteTimesheetPDF(&buf, sheet, labels); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Errorf("WriteTimesheetPDF() did not write a PDF")
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{7*time.Hour + 5*time.Minute, "7:05"},
+		{-45 * time.Minute, "-0:45"},
+		{26*time.Hour + 59*time.Second, "26:00"},
+	}
+	for _, tt := range tests {
+		if got := formatClock(tt.d); got != tt.want {
+			t.Errorf("formatClock(%s) = %s, want %s", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"time"
+)
+
+// VacationBudget holds the vacation days of a year
+type VacationBudget struct {
+	Year int
+
+	// Vacation days of the year according to the contract or settings
+	Entitlement float64
+	// Unused days of the previous year
+	CarryOver float64
+	// Carried over days which were not used until the cut-off date
+	CarryOverExpired float64
+	// Last day to use the carried over days, zero if they never expire
+	CarryOverCutoff time.Time
+
+	// Vacation days before today
+	Taken float64
+	// Vacation days from today on
+	Planned float64
+	// Days which can still be planned
+	Remaining float64
+}
+
+/*
+Calculates the vacation budget of a year at the given day.
+The entitlement is taken from the contract valid on the 1st of January.
+Unused days of the previous year are carried over and expire if they were
+not used until the configured cut-off date. Years before the first
+recorded vacation have no carry over.
+*/
+func (c *Calculator) VacationBudget(year int, today time.Time) *VacationBudget {
+	firstYear := year
+	for _, a := range c.Absences {
+		if ConsumesVacationBudget(a.Type) && a.StartDate.Year() < firstYear {
+			firstYear = a.StartDate.Year()
+		}
+	}
+
+	var carryOver float64
+	if year > firstYear {
+		// At the end of the previous year every day is taken
+		previous := c.VacationBudget(year-1, time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+		carryOver = max(0, previous.Remaining)
+	}
+
+	startOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endOfYear := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	day := dateOnly(today)
+
+	budget := &VacationBudget{
+		Year:        year,
+		Entitlement: float64(c.Settings.MaxVacationDays),
+		CarryOver:   carryOver,
+	}
+	if contract := c.ContractFor(startOfYear); contract != nil {
+		budget.Entitlement = float64(contract.VacationDays)
+	}
+
+	yesterday := day.AddDate(0, 0, -1)
+	budget.Taken = c.vacationDaysBetween(startOfYear, minDate(yesterday, endOfYear))
+	budget.Planned = c.vacationDaysBetween(maxDate(day, startOfYear), endOfYear)
+
+	carryOverUsable := carryOver
+	if cutoff, ok := c.Settings.CarryOverCutoffIn(year); !ok {
+		// Without cut-off date nothing is carried over
+		carryOverUsable = 0
+		budget.CarryOver = 0
+	} else {
+		budget.CarryOverCutoff = cutoff
+		if day.After(cutoff) {
+			// Only days used until the cut-off date consume the carried over days
+			carryOverUsable = min(carryOver, c.vacationDaysBetween(startOfYear, cutoff))
+			budget.CarryOverExpired = carryOver - carryOverUsable
+		}
+	}
+
+	budget.Remaining = budget.Entitlement + carryOverUsable - budget.Taken - budget.Planned
+	return budget
+}
+
+// Counts the days taken from the vacation budget between from and to (both inclusive)
+func (c *Calculator) vacationDaysBetween(from time.Time, to time.Time) float64 {
+	var days float64
+	for d := dateOnly(from); !d.After(dateOnly(to)); d = d.AddDate(0, 0, 1) {
+		if share := c.VacationShare(d); share > 0 && c.TargetFor(d) > 0 {
+			days += share
+		}
+	}
+	return days
+}
+
+func minDate(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxDate(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+func TestVacationBudget(t *testing.T) {
+	vacations := []*db.Absence{
+		// Two weeks in 2024, 20 days are carried over
+		{ID: 1, Type: db.AbsenceVacation, StartDate: workday(0, "2024-07-01").Date, EndDate: workday(0, "2024-07-12").Date},
+		// One week before the cut-off date
+		{ID: 2, Type: db.AbsenceVacation, StartDate: workday(0, "2025-03-24").Date, EndDate: workday(0, "2025-03-28").Date},
+		// Sick days are not taken from the budget
+		{ID: 4, Type: db.AbsenceSick, StartDate: workday(0, "2025-05-05").Date, EndDate: workday(0, "2025-05-09").Date},
+		// Christmas holidays and the weekend are not counted
+		{ID: 3, Type: db.AbsenceVacation, StartDate: workday(0, "2025-12-22").Date, EndDate: workday(0, "2025-12-31").Date},
+	}
+
+	tests := []struct {
+		name          string
+		year          int
+		today         string
+		cutoff        string
+		contract      *db.Contract
+		wantCarryOver float64
+		wantExpired   float64
+		wantTaken     float64
+		wantPlanned   float64
+		wantRemaining float64
+	}{
+		{"first year has no carry over", 2024, "2024-07-05", "31.03", nil, 0, 0, 4, 6, 20},
+		{"before cut-off", 2025, "2025-03-01", "31.03", nil, 20, 0, 0, 11, 39},
+		{"after cut-off", 2025, "2025-06-01", "31.03", nil, 20, 15, 5, 6, 24},
+		{"end of year", 2025, "2026-01-01", "31.03", nil, 20, 15, 11, 0, 24},
+		{"remaining days of the previous year", 2026, "2026-01-01", "31.03", nil, 24, 0, 0, 0, 54},
+		{"without carry over", 2025, "2025-03-01", "", nil, 0, 0, 0, 11, 19},
+		{"contract entitlement", 2025, "2025-06-01", "31.03",
+			&db.Contract{ValidFrom: workday(0, "2025-01-01").Date, WeekHours: 40, VacationDays: 24,
+				Targets: [7]time.Duration{0, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 0}},
+			20, 15, 5, 6, 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := model.NewSettings("", "")
+			settings.CarryOverCutoff = tt.cutoff
+
+			var contracts []*db.Contract
+			if tt.contract != nil {
+				contracts = append(contracts, tt.contract)
+			}
+			calc := NewCalculator(settings, contracts)
+			calc.Absences = vacations
+			calc.Holidays = NewHolidayCalendar("DE", nil)
+
+			got := calc.VacationBudget(tt.year, workday(0, tt.today).Date)
+			if got.CarryOver != tt.wantCarryOver || got.CarryOverExpired != tt.wantExpired ||
+				got.Taken != tt.wantTaken || got.Planned != tt.wantPlanned || got.Remaining != tt.wantRemaining {
+				t.Errorf("VacationBudget(%d, %s) = %+v", tt.year, tt.today, got)
+			}
+		})
+	}
+}
+
+func TestCarryOverCutoffIn(t *testing.T) {
+	settings := model.NewSettings("", "")
+
+	cutoff, ok := settings.CarryOverCutoffIn(2025)
+	if !ok || !cutoff.Equal(workday(0, "2025-03-31").Date) {
+		t.Errorf("CarryOverCutoffIn(2025) = %v, %v", cutoff, ok)
+	}
+
+	settings.CarryOverCutoff = ""
+	if _, ok := settings.CarryOverCutoffIn(2025); ok {
+		t.Error("empty cut-off must disable the carry over")
+	}
+}
@@ -0,0 +1,180 @@
+package view
+
+import (
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
+	"github.com/charmbracelet/log"
+)
+
+// BudgetView compares the recorded time of the projects with their budgets
+type BudgetView struct {
+	av *AppView
+	ps *service.ProjectService
+
+	budgets  []*service.ProjectBudget
+	selected *service.ProjectBudget
+	from     time.Time
+	to       time.Time
+
+	// UI
+	fromEntry *widget.Entry
+	toEntry   *widget.Entry
+	list      *widget.List
+	chart     *fwidget.LineChart
+	chartInfo *widget.Label
+	container *fyne.Container
+}
+
+// Creates the budget overview, the current month is shown by default
+func CreateBudgetView(av *AppView) *BudgetView {
+	bv := &BudgetView{
+		av:        av,
+		ps:        service.NewProjectService(av.repo),
+		chart:     fwidget.NewLineChart(),
+		chartInfo: widget.NewLabel(lang.L("selectProjectForBurnDown")),
+	}
+
+	now := time.Now()
+	bv.fromEntry = newDateEntry(av.window)
+	bv.fromEntry.SetText(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format(model.DATEFORMAT))
+	bv.toEntry = newDateEntry(av.window)
+	bv.toEntry.SetText(now.Format(model.DATEFORMAT))
+
+	bv.list = widget.NewList(
+		func() int {
+			return len(bv.budgets)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(newColorLabel(), widget.NewProgressBar())
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			b := bv.budgets[i]
+			c := o.(*fyne.Container)
+			updateColorLabel(c.Objects[0], b.Project.Color, formatBudget(b))
+
+			bar := c.Objects[1].(*widget.ProgressBar)
+			// An exceeded budget fills the whole bar
+			bar.Max = math.Max(1, b.Share())
+			bar.SetValue(b.Share())
+		},
+	)
+	bv.list.OnSelected = func(id widget.ListItemID) {
+		bv.selected = bv.budgets[id]
+		bv.refreshChart()
+	}
+
+	filter := container.NewGridWithColumns(3,
+		bv.fromEntry,
+		bv.toEntry,
+		widget.NewButtonWithIcon(lang.L("apply"), theme.ViewRefreshIcon(), bv.Refresh),
+	)
+	chart := container.NewBorder(
+		widget.NewLabelWithStyle(lang.L("burnDown"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		bv.chartInfo, nil, nil, bv.chart)
+
+	bv.container = container.NewBorder(filter, nil, nil, nil,
+		container.NewGridWithColumns(2, bv.list, chart))
+	bv.Refresh()
+	return bv
+}
+
+// Shows the budget overview in a dialog
+func (bv *BudgetView) Show() {
+	dia := dialog.NewCustom(lang.L("projectBudgets"), lang.L("close"), bv.container, bv.av.window)
+	dia.Resize(fyne.NewSize(800, 450))
+	dia.Show()
+}
+
+// Sums up the recorded time of the selected range again
+func (bv *BudgetView) Refresh() {
+	from, err := time.Parse(model.DATEFORMAT, bv.fromEntry.Text)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, bv.av.window)
+		return
+	}
+	to, err := time.Parse(model.DATEFORMAT, bv.toEntry.Text)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, bv.av.window)
+		return
+	}
+	if to.Before(from) {
+		dialog.ShowError(repo.ErrInvalidRange, bv.av.window)
+		return
+	}
+	bv.from, bv.to = from, to
+
+	budgets, err := bv.ps.Budgets(from, to)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, bv.av.window)
+		return
+	}
+	bv.budgets = budgets
+	bv.list.Refresh()
+
+	// Keep the burn-down of the selected project, selecting it draws the chart
+	selected := bv.selected
+	bv.selected = nil
+	bv.list.UnselectAll()
+	if selected != nil {
+		for i, b := range budgets {
+			if b.Project.ID == selected.Project.ID {
+				bv.list.Select(i)
+				return
+			}
+		}
+	}
+	bv.refreshChart()
+}
+
+func (bv *BudgetView) refreshChart() {
+	if bv.selected == nil {
+		bv.chart.SetValues(nil)
+		bv.chartInfo.SetText(lang.L("selectProjectForBurnDown"))
+		return
+	}
+
+	points, err := bv.ps.BurnDown(bv.selected.Project, bv.from, bv.to)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, bv.av.window)
+		return
+	}
+
+	values := make([]fwidget.ChartValue, len(points))
+	for i, p := range points {
+		values[i] = fwidget.ChartValue{
+			Label: p.Date.Format(model.DATEFORMAT),
+			Value: p.Remaining.Hours(),
+			Mark:  math.NaN(),
+		}
+	}
+	bv.chart.SetValues(values)
+	bv.chartInfo.SetText(lang.L("remainingBudget", map[string]any{
+		"Remaining": model.FormatDuration(bv.selected.Remaining()),
+	}))
+}
+
+// Returns e.g. WEB Website: 32h of 40h (80%), 6h in the period
+func formatBudget(b *service.ProjectBudget) string {
+	return formatProject(b.Project.Code, b.Project.Name, b.Project.Active, 0) + ": " +
+		lang.L("budgetUsage", map[string]any{
+			"Used":    model.FormatDuration(b.Used),
+			"Budget":  model.FormatDuration(b.Project.Budget),
+			"Percent": int(math.Round(b.Share() * 100)),
+			"Period":  model.FormatDuration(b.Period),
+		})
+}
@@ -5,6 +5,8 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/FyningTime/FyningTime/app/model"
 	"github.com/FyningTime/FyningTime/app/model/db"
@@ -18,35 +20,48 @@ type CalenderView struct {
 	container *fyne.Container
 
 	calender *fwidget.Calendar
+	// The day opened by the edit button
+	selected time.Time
 }
 
 func (c *CalenderView) OnSelected(t time.Time) {
 	loc, _ := time.LoadLocation("Europe/Berlin")
 
+	c.selected = t
 	c.instruction.SetText("Date selected:")
 	c.dateChosen.SetText(t.In(loc).Format(model.DATEFORMAT))
 }
 
-func CreateCalendarView(w fyne.Window, vacations []*db.Vacation, selectedTime time.Time) *CalenderView {
+func CreateCalendarView(w fyne.Window, absences []*db.Absence, selectedTime time.Time, onEdit func(time.Time)) *CalenderView {
 	i := widget.NewLabel("Select a date")
 	i.Alignment = fyne.TextAlignCenter
 	l := widget.NewLabel("")
 	l.Alignment = fyne.TextAlignCenter
-	c := &CalenderView{instruction: i, dateChosen: l}
+	c := &CalenderView{instruction: i, dateChosen: l, selected: selectedTime}
 
-	xcalendar := fwidget.NewCalendar(w, vacations, selectedTime, c.OnSelected)
-	content := container.NewBorder(l, nil, nil, nil, xcalendar)
+	xcalendar := fwidget.NewCalendar(w, absences, selectedTime, c.OnSelected)
+	// Opens the day editor, e.g. to add a forgotten workday
+	edit := widget.NewButtonWithIcon(lang.L("editDay"), theme.DocumentIcon(), func() {
+		onEdit(c.selected)
+	})
+	content := container.NewBorder(l, edit, nil, nil, xcalendar)
 	c.calender = xcalendar
 	c.container = content
 	return c
 }
 
-func (c *CalenderView) UpdateVacations(vacations []*db.Vacation) {
-	if vacations != nil {
-		c.calender.UpdateVacations(vacations)
+func (c *CalenderView) UpdateAbsences(absences []*db.Absence) {
+	if absences != nil {
+		c.calender.UpdateAbsences(absences)
 	} else {
-		c.calender.UpdateVacations([]*db.Vacation{})
+		c.calender.UpdateAbsences([]*db.Absence{})
 	}
 	c.calender.Refresh()
 	c.container.Refresh()
 }
+
+func (c *CalenderView) UpdateHolidays(holidays fwidget.HolidayLookup) {
+	c.calender.UpdateHolidays(holidays)
+	c.calender.Refresh()
+	c.container.Refresh()
+}
@@ -0,0 +1,47 @@
+package view
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/service"
+)
+
+// Shows the violated working-time rules, the newest first
+func ShowComplianceReport(av *AppView) {
+	warnings := service.CheckCompliance(av.workday, av.worktime, service.DefaultComplianceLimits)
+
+	var content fyne.CanvasObject
+	if len(warnings) == 0 {
+		content = widget.NewLabel(lang.L("noComplianceWarnings"))
+	} else {
+		list := widget.NewList(
+			func() int {
+				return len(warnings)
+			},
+			func() fyne.CanvasObject {
+				return widget.NewLabel("Warning")
+			},
+			func(i widget.ListItemID, o fyne.CanvasObject) {
+				w := warnings[len(warnings)-1-i]
+				o.(*widget.Label).SetText(w.Workday.Date.Format(model.DATEFORMAT) + ": " + formatComplianceWarning(w))
+			},
+		)
+		content = container.NewStack(list)
+	}
+
+	dia := dialog.NewCustom(lang.L("complianceReport"), lang.L("close"), content, av.window)
+	dia.Resize(fyne.NewSize(500, 400))
+	dia.Show()
+}
+
+// Describes a violated rule, e.g. 10h30m worked, more than 10h
+func formatComplianceWarning(w *service.ComplianceWarning) string {
+	return lang.L(string(w.Rule)+"Warning", map[string]any{
+		"Value": model.FormatDuration(w.Value),
+		"Limit": model.FormatDuration(w.Limit),
+	})
+}
@@ -0,0 +1,221 @@
+package view
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	"github.com/charmbracelet/log"
+	datepicker "github.com/sdassow/fyne-datepicker"
+)
+
+type ContractView struct {
+	// Business logic
+	contracts []*db.Contract
+	repo      *repo.SQLiteRepository
+
+	// UI
+	av               *AppView
+	list             *widget.List
+	container        *fyne.Container
+	selectedContract *widget.ListItemID
+}
+
+func CreateContractView(av *AppView, repo *repo.SQLiteRepository) *ContractView {
+	cv := &ContractView{
+		repo: repo,
+		av:   av,
+	}
+
+	cv.list = widget.NewList(
+		func() int {
+			return len(cv.contracts)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Contracts")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			c := cv.contracts[i]
+			o.(*widget.Label).SetText(
+				lang.L("validFrom") + " " + c.ValidFrom.Format(model.DATEFORMAT) + ": " +
+					strconv.FormatFloat(c.WeekHours, 'f', -1, 64) + "h, " +
+					strconv.Itoa(c.VacationDays) + " " + lang.L("vacationDays"),
+			)
+		},
+	)
+	cv.list.OnSelected = func(id widget.ListItemID) {
+		cv.selectedContract = &id
+	}
+
+	toolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() { cv.contractForm(nil) }),
+		widget.NewToolbarAction(theme.ContentRemoveIcon(), cv.deleteContractForm),
+		widget.NewToolbarAction(theme.DocumentIcon(), cv.editContractForm),
+	)
+
+	cv.container = container.NewBorder(toolbar, widget.NewLabel(lang.L("contractsHint")), nil, nil, cv.list)
+	cv.refresh()
+	return cv
+}
+
+// Shows the contracts in a dialog
+func (cv *ContractView) Show() {
+	dia := dialog.NewCustom(lang.L("contracts"), lang.L("close"), cv.container, cv.av.window)
+	dia.Resize(fyne.NewSize(500, 400))
+	dia.Show()
+}
+
+func (cv *ContractView) refresh() {
+	contracts, err := cv.repo.GetAllContract()
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, cv.av.window)
+		return
+	}
+	cv.contracts = contracts
+	cv.list.UnselectAll()
+	cv.selectedContract = nil
+	cv.list.Refresh()
+}
+
+func (cv *ContractView) editContractForm() {
+	if cv.selectedContract == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), cv.av.window)
+		return
+	}
+	cv.contractForm(cv.contracts[*cv.selectedContract])
+}
+
+func (cv *ContractView) deleteContractForm() {
+	if cv.selectedContract == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), cv.av.window)
+		return
+	}
+	c := cv.contracts[*cv.selectedContract]
+
+	dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDeleteContract"), func(b bool) {
+		if !b {
+			return
+		}
+		if _, err := cv.repo.DeleteContract(c); err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+		cv.changed()
+	}, cv.av.window)
+}
+
+// Shows the form to add a contract or to edit the given contract
+func (cv *ContractView) contractForm(c *db.Contract) {
+	settings := service.ReadProperties(cv.av.a)
+
+	isAdd := c == nil
+	if isAdd {
+		c = &db.Contract{
+			ValidFrom:    time.Now(),
+			WeekHours:    float64(settings.WeekHours),
+			VacationDays: settings.MaxVacationDays,
+		}
+		for _, wd := range model.Weekdays {
+			c.Targets[wd.TimeWeekday()] = model.HoursToDuration(settings.TargetHours[wd])
+		}
+	}
+
+	validFrom := widget.NewEntry()
+	validFrom.SetText(c.ValidFrom.Format(model.DATEFORMAT))
+	validFrom.ActionItem = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
+		picker := datepicker.NewDatePicker(c.ValidFrom, time.Monday, func(when time.Time, ok bool) {
+			if ok {
+				validFrom.SetText(when.Format(model.DATEFORMAT))
+			}
+		})
+		dialog.ShowCustomConfirm(lang.L("selectDate"), lang.L("confirm"), lang.L("cancel"),
+			picker, picker.OnActioned, cv.av.window)
+	})
+
+	weekHours := widget.NewEntry()
+	weekHours.SetText(strconv.FormatFloat(c.WeekHours, 'f', -1, 64))
+
+	hours := make(map[model.Weekday]float64, len(model.Weekdays))
+	for _, wd := range model.Weekdays {
+		hours[wd] = c.Targets[wd.TimeWeekday()].Hours()
+	}
+	targetHoursForm, targetHours := newTargetHoursForm(cv.av.window, weekHours, hours)
+
+	vacationDays := widget.NewEntry()
+	vacationDays.SetText(strconv.Itoa(c.VacationDays))
+
+	form := []*widget.FormItem{
+		{Text: lang.L("validFrom"), Widget: validFrom},
+		{Text: lang.L("weekHours"), Widget: weekHours},
+		{Text: lang.L("targetHours"), Widget: targetHoursForm},
+		{Text: lang.L("maxVacations"), Widget: vacationDays},
+	}
+
+	title, confirm := lang.L("addContract"), lang.L("save")
+	if !isAdd {
+		title, confirm = lang.L("editContract"), lang.L("edit")
+	}
+
+	dia := dialog.NewForm(title, confirm, lang.L("cancel"), form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		vf, err := time.Parse(model.DATEFORMAT, validFrom.Text)
+		if err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+		wh, err := strconv.ParseFloat(weekHours.Text, 64)
+		if err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+		th, err := parseTargetHours(targetHours)
+		if err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+		vd, err := strconv.Atoi(vacationDays.Text)
+		if err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+
+		c.ValidFrom = vf
+		c.WeekHours = wh
+		c.VacationDays = vd
+		for wd, h := range th {
+			c.Targets[wd.TimeWeekday()] = model.HoursToDuration(h)
+		}
+
+		if isAdd {
+			_, err = cv.repo.AddContract(c)
+		} else {
+			_, err = cv.repo.UpdateContract(c)
+		}
+		if err != nil {
+			dialog.ShowError(err, cv.av.window)
+			return
+		}
+		cv.changed()
+	}, cv.av.window)
+	dia.Resize(fyne.NewSize(600, 400))
+	dia.Show()
+}
+
+// Reloads the contracts, the scheduler recalculates the overtime
+func (cv *ContractView) changed() {
+	cv.refresh()
+}
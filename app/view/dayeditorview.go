@@ -0,0 +1,206 @@
+package view
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/service"
+	"github.com/charmbracelet/log"
+)
+
+// Translation keys of the validation errors of the day editor
+var dayErrorKeys = map[error]string{
+	service.ErrMissingBegin:     "missingBegin",
+	service.ErrEndNotAfterBegin: "endNotAfterBegin",
+	service.ErrEntriesOverlap:   "entriesOverlap",
+	service.ErrOpenEntry:        "onlyLastEntryOpen",
+	service.ErrOtherDay:         "entryNotOnDay",
+	service.ErrFutureEntry:      "timeIsInFuture",
+}
+
+// DayEditorView edits all Begin/End pairs of a day at once
+type DayEditorView struct {
+	av   *AppView
+	date time.Time
+
+	// UI
+	rows   []*dayEditorRow
+	list   *fyne.Container
+	reason *widget.Entry
+	dia    dialog.Dialog
+}
+
+// A Begin/End pair of the editor, the entry is new if it has no Begin yet
+type dayEditorRow struct {
+	entry *service.DayEntry
+	begin *widget.Entry
+	end   *widget.Entry
+	row   fyne.CanvasObject
+}
+
+/*
+Shows the editor for the time entries of the given day, a day without
+entries is added when it is saved
+*/
+func ShowDayEditor(av *AppView, date time.Time) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	y, m, d := date.Date()
+	de := &DayEditorView{av: av, date: time.Date(y, m, d, 0, 0, 0, 0, loc)}
+
+	entries, err := av.ts.DayEntries(de.date)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, av.window)
+		return
+	}
+
+	de.list = container.NewVBox()
+	for _, e := range entries {
+		de.addRow(e)
+	}
+	if len(entries) == 0 {
+		de.addRow(&service.DayEntry{})
+	}
+
+	addButton := widget.NewButtonWithIcon(lang.L("addEntry"), theme.ContentAddIcon(), func() {
+		de.addRow(&service.DayEntry{})
+	})
+	de.reason = widget.NewEntry()
+	de.reason.SetPlaceHolder(lang.L("reasonHint"))
+
+	header := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle(lang.L("begin"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(lang.L("end"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	bottom := container.NewVBox(addButton, widget.NewForm(widget.NewFormItem(lang.L("reason"), de.reason)))
+	content := container.NewBorder(header, bottom, nil, nil, container.NewVScroll(de.list))
+
+	title := lang.L("editDay") + " " + de.date.Format(model.DATEFORMAT) +
+		" / " + model.ShortenWeekday(de.date.Weekday().String())
+	de.dia = dialog.NewCustomConfirm(title, lang.L("save"), lang.L("cancel"), content, de.save, av.window)
+	de.dia.Resize(fyne.NewSize(450, 400))
+	de.dia.Show()
+}
+
+// Adds a row for the entry, a new row begins at the end of the previous one
+func (de *DayEditorView) addRow(e *service.DayEntry) {
+	r := &dayEditorRow{entry: e, begin: widget.NewEntry(), end: widget.NewEntry()}
+	r.begin.SetPlaceHolder("hh:mm")
+	r.end.SetPlaceHolder("hh:mm")
+	if e.Begin != nil {
+		r.begin.SetText(e.Begin.Time.Format(time.TimeOnly))
+	} else if len(de.rows) > 0 {
+		r.begin.SetText(de.rows[len(de.rows)-1].end.Text)
+	}
+	if e.End != nil {
+		r.end.SetText(e.End.Time.Format(time.TimeOnly))
+	}
+
+	remove := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
+		de.removeRow(r)
+	})
+	r.row = container.NewBorder(nil, nil, nil, remove, container.NewGridWithColumns(2, r.begin, r.end))
+	de.rows = append(de.rows, r)
+	de.list.Add(r.row)
+}
+
+func (de *DayEditorView) removeRow(r *dayEditorRow) {
+	for i, row := range de.rows {
+		if row == r {
+			de.rows = append(de.rows[:i], de.rows[i+1:]...)
+			break
+		}
+	}
+	de.list.Remove(r.row)
+}
+
+// Stores the rows as the entries of the day, the editor stays open on invalid input
+func (de *DayEditorView) save(confirmed bool) {
+	if !confirmed {
+		log.Debug("Edit day", "canceled", true)
+		return
+	}
+
+	entries, err := de.entries()
+	if err == nil {
+		err = de.av.ts.SaveDay(de.date, entries, de.reason.Text)
+	}
+	if err != nil {
+		log.Error(err)
+		for target, key := range dayErrorKeys {
+			if errors.Is(err, target) {
+				err = errors.New(lang.L(key))
+				break
+			}
+		}
+		de.dia.Show()
+		dialog.ShowError(err, de.av.window)
+		return
+	}
+	log.Info("Saved day", "date", de.date.Format(time.DateOnly), "entries", len(entries))
+}
+
+// Returns the entries of the rows sorted by their begin, empty rows are skipped
+func (de *DayEditorView) entries() ([]*service.DayEntry, error) {
+	entries := []*service.DayEntry{}
+	for _, r := range de.rows {
+		beginText, endText := strings.TrimSpace(r.begin.Text), strings.TrimSpace(r.end.Text)
+		if beginText == "" && endText == "" {
+			continue
+		}
+
+		e := &service.DayEntry{}
+		if beginText != "" {
+			begin, err := de.parseTime(beginText)
+			if err != nil {
+				return nil, err
+			}
+			e.Begin = r.entry.Begin
+			if e.Begin == nil {
+				e.Begin = &db.Worktime{}
+			}
+			e.Begin.Time = begin
+		}
+		if endText != "" {
+			end, err := de.parseTime(endText)
+			if err != nil {
+				return nil, err
+			}
+			e.End = r.entry.End
+			if e.End == nil {
+				e.End = &db.Worktime{}
+			}
+			e.End.Time = end
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Begin == nil || entries[j].Begin == nil {
+			return entries[i].Begin == nil
+		}
+		return entries[i].Begin.Time.Before(entries[j].Begin.Time)
+	})
+	return entries, nil
+}
+
+// Parses a time like 08:30 or 08:30:00 on the edited day
+func (de *DayEditorView) parseTime(text string) (time.Time, error) {
+	for _, layout := range []string{time.TimeOnly, "15:04"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return time.Date(de.date.Year(), de.date.Month(), de.date.Day(),
+				t.Hour(), t.Minute(), t.Second(), 0, de.date.Location()), nil
+		}
+	}
+	return time.Time{}, errors.New(lang.L("invalidTime", map[string]any{"Time": text}))
+}
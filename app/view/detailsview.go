@@ -0,0 +1,180 @@
+package view
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
+	"github.com/charmbracelet/log"
+)
+
+type DetailsView struct {
+	// UI
+	av        *AppView
+	container *fyne.Container
+
+	from   *widget.Entry
+	to     *widget.Entry
+	period *widget.Select
+
+	summary  *widget.Form
+	worked   *fwidget.BarChart
+	trend    *fwidget.LineChart
+	weekdays *fwidget.BarChart
+}
+
+// Creates the statistics dashboard, the current year is shown by default
+func CreateDetailsView(av *AppView) *DetailsView {
+	dv := &DetailsView{
+		av:       av,
+		worked:   fwidget.NewBarChart(),
+		trend:    fwidget.NewLineChart(),
+		weekdays: fwidget.NewBarChart(),
+		summary:  widget.NewForm(),
+	}
+
+	now := time.Now()
+	dv.from = newDateEntry(av.window)
+	dv.from.SetText(time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location()).Format(model.DATEFORMAT))
+	dv.to = newDateEntry(av.window)
+	dv.to.SetText(now.Format(model.DATEFORMAT))
+
+	periods := make([]string, len(service.StatisticsPeriods))
+	for i, p := range service.StatisticsPeriods {
+		periods[i] = lang.L(string(p))
+	}
+	dv.period = widget.NewSelect(periods, nil)
+	dv.period.SetSelectedIndex(1)
+	dv.period.OnChanged = func(string) { dv.Refresh() }
+
+	filter := container.NewGridWithColumns(4,
+		dv.from,
+		dv.to,
+		dv.period,
+		widget.NewButtonWithIcon(lang.L("apply"), theme.ViewRefreshIcon(), dv.Refresh),
+	)
+	top := container.NewBorder(nil, nil, nil,
+		widget.NewButtonWithIcon(lang.L("timesheet"), theme.DocumentPrintIcon(), av.ExportTimesheet),
+		filter)
+
+	content := container.NewVBox(
+		dv.summary,
+		widget.NewLabelWithStyle(lang.L("workedVsTarget"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		dv.worked,
+		widget.NewLabelWithStyle(lang.L("overtimeTrend"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		dv.trend,
+		widget.NewLabelWithStyle(lang.L("perWeekday"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		dv.weekdays,
+	)
+	dv.container = container.NewBorder(top, nil, nil, nil, container.NewVScroll(content))
+	return dv
+}
+
+// Calculates the statistics of the selected range again
+func (dv *DetailsView) Refresh() {
+	from, err := time.Parse(model.DATEFORMAT, dv.from.Text)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, dv.av.window)
+		return
+	}
+	to, err := time.Parse(model.DATEFORMAT, dv.to.Text)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, dv.av.window)
+		return
+	}
+	if to.Before(from) {
+		dialog.ShowError(repo.ErrInvalidRange, dv.av.window)
+		return
+	}
+
+	settings := service.ReadProperties(dv.av.a)
+	period := service.StatisticsPeriods[max(dv.period.SelectedIndex(), 0)]
+	stats, err := dv.av.ts.Statistics(settings, from, to, period)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, dv.av.window)
+		return
+	}
+
+	dv.showSummary(stats)
+
+	worked := make([]fwidget.ChartValue, len(stats.Buckets))
+	for i, b := range stats.Buckets {
+		worked[i] = fwidget.ChartValue{
+			Label: periodLabel(b.Start, period),
+			Value: b.Worked.Hours(),
+			Mark:  b.Target.Hours(),
+		}
+	}
+	dv.worked.SetValues(worked)
+
+	trend := make([]fwidget.ChartValue, len(stats.Trend))
+	for i, p := range stats.Trend {
+		trend[i] = fwidget.ChartValue{
+			Label: p.Date.Format(model.DATEFORMAT),
+			Value: p.Balance.Hours(),
+			Mark:  math.NaN(),
+		}
+	}
+	dv.trend.SetValues(trend)
+
+	// Weekdays in the order of the week beginning with the configured day
+	weekdays := make([]fwidget.ChartValue, 0, len(model.Weekdays))
+	first := settings.FirstDayOfWeek.TimeWeekday()
+	for i := range 7 {
+		day := time.Weekday((int(first) + i) % 7)
+		weekdays = append(weekdays, fwidget.ChartValue{
+			Label: model.ShortenWeekday(day.String()),
+			Value: stats.WeekdayAverage[day].Hours(),
+			Mark:  math.NaN(),
+		})
+	}
+	dv.weekdays.SetValues(weekdays)
+}
+
+func (dv *DetailsView) showSummary(stats *service.Statistics) {
+	longest := "-"
+	if stats.LongestWorktime > 0 {
+		longest = stats.LongestDay.Format(model.DATEFORMAT) + " (" + model.FormatDuration(stats.LongestWorktime) + ")"
+	}
+	clock := func(d time.Duration) string {
+		if stats.Days == 0 {
+			return "-"
+		}
+		return time.Time{}.Add(d).Format("15:04")
+	}
+
+	dv.summary.Items = []*widget.FormItem{
+		widget.NewFormItem(lang.L("workdays"), widget.NewLabel(strconv.Itoa(stats.Days))),
+		widget.NewFormItem(lang.L("averageBegin"), widget.NewLabel(clock(stats.AverageBegin))),
+		widget.NewFormItem(lang.L("averageEnd"), widget.NewLabel(clock(stats.AverageEnd))),
+		widget.NewFormItem(lang.L("averageBreak"), widget.NewLabel(model.FormatDuration(stats.AverageBreak))),
+		widget.NewFormItem(lang.L("longestDay"), widget.NewLabel(longest)),
+		widget.NewFormItem(lang.L("daysOver10h"), widget.NewLabel(strconv.Itoa(stats.LongDays))),
+	}
+	dv.summary.Refresh()
+}
+
+// Short label of a chart bar, e.g. 32/25 for a week, 08/25 for a month
+func periodLabel(start time.Time, period service.StatisticsPeriod) string {
+	switch period {
+	case service.StatisticsYear:
+		return strconv.Itoa(start.Year())
+	case service.StatisticsMonth:
+		return start.Format("01/06")
+	}
+	_, week := start.ISOWeek()
+	return strconv.Itoa(week) + "/" + start.Format("06")
+}
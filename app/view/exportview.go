@@ -0,0 +1,106 @@
+package view
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	"github.com/charmbracelet/log"
+)
+
+// Asks for the date range and the format and saves the worktime to a file
+func ShowExportView(av *AppView) {
+	now := time.Now()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	from := newDateEntry(av.window)
+	from.SetText(firstOfMonth.Format(model.DATEFORMAT))
+	to := newDateEntry(av.window)
+	to.SetText(firstOfMonth.AddDate(0, 1, -1).Format(model.DATEFORMAT))
+
+	formats := make([]string, len(service.ExportFormats))
+	for i, f := range service.ExportFormats {
+		formats[i] = string(f)
+	}
+	format := widget.NewSelect(formats, nil)
+	format.SetSelectedIndex(0)
+	history := widget.NewCheck(lang.L("includeCorrections"), nil)
+
+	dialog.ShowForm(lang.L("exportWorktime"), lang.L("export"), lang.L("cancel"),
+		[]*widget.FormItem{
+			widget.NewFormItem(lang.L("startDate"), from),
+			widget.NewFormItem(lang.L("endDate"), to),
+			widget.NewFormItem(lang.L("format"), format),
+			widget.NewFormItem("", history),
+		},
+		func(b bool) {
+			if !b {
+				return
+			}
+
+			start, err := time.Parse(model.DATEFORMAT, from.Text)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			end, err := time.Parse(model.DATEFORMAT, to.Text)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			if end.Before(start) {
+				dialog.ShowError(repo.ErrInvalidRange, av.window)
+				return
+			}
+
+			es := service.NewExportService(av.repo)
+			export, err := es.Collect(service.ReadProperties(av.a), start, end)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			if history.Checked {
+				if export.Corrections, err = service.NewAuditService(av.repo).Corrections(start, end); err != nil {
+					log.Error(err)
+					dialog.ShowError(err, av.window)
+					return
+				}
+			}
+			saveExport(av, export, service.ExportFormat(format.Selected))
+		}, av.window)
+}
+
+func saveExport(av *AppView, export *service.Export, format service.ExportFormat) {
+	fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+		if writer == nil {
+			// Canceled
+			return
+		}
+		defer writer.Close()
+
+		locale := service.ExportLocaleFor(string(lang.SystemLocale()))
+		if err := service.WriteExport(writer, format, export, locale); err != nil {
+			log.Error(err)
+			dialog.ShowError(err, av.window)
+			return
+		}
+		dialog.ShowInformation(lang.L("exportWorktime"),
+			lang.L("worktimeExported", map[string]any{"Count": len(export.Days)}), av.window)
+	}, av.window)
+	fd.SetFileName("fyningtime-" + export.From + "-" + export.To + "." + string(format))
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{"." + string(format)}))
+	fd.Show()
+}
@@ -0,0 +1,231 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
+	"github.com/charmbracelet/log"
+)
+
+// Previewed rows are limited to keep the dialog responsive
+const maxPreviewRows = 200
+
+type ImportView struct {
+	// Business logic
+	is      *service.ImporterService
+	records [][]string
+	mapping service.ImportMapping
+	report  *service.ImportReport
+
+	// UI
+	av        *AppView
+	columns   []string
+	list      *widget.List
+	summary   *widget.Label
+	container *fyne.Container
+}
+
+func CreateImportView(av *AppView, is *service.ImporterService, records [][]string) *ImportView {
+	iv := &ImportView{
+		is:      is,
+		records: records,
+		av:      av,
+		mapping: service.GuessImportMapping(records),
+	}
+
+	width := 0
+	for _, r := range records {
+		width = max(width, len(r))
+	}
+	iv.columns = make([]string, width)
+	for i := range iv.columns {
+		iv.columns[i] = iv.columnName(i)
+	}
+
+	dateSelect := iv.columnSelect(&iv.mapping.Date, false)
+	beginSelect := iv.columnSelect(&iv.mapping.Begin, false)
+	endSelect := iv.columnSelect(&iv.mapping.End, false)
+	pauseSelect := iv.columnSelect(&iv.mapping.Pause, true)
+	pauseStartSelect := iv.columnSelect(&iv.mapping.PauseStart, true)
+	absenceSelect := iv.columnSelect(&iv.mapping.Absence, true)
+
+	dateFormat := widget.NewSelectEntry(service.ImportDateFormats)
+	dateFormat.SetText(iv.mapping.DateFormat)
+	dateFormat.OnChanged = func(s string) {
+		iv.mapping.DateFormat = s
+		iv.preview()
+	}
+	timeFormat := widget.NewSelectEntry(service.ImportTimeFormats)
+	timeFormat.SetText(iv.mapping.TimeFormat)
+	timeFormat.OnChanged = func(s string) {
+		iv.mapping.TimeFormat = s
+		iv.preview()
+	}
+	header := widget.NewCheck(lang.L("firstRowIsHeader"), nil)
+	header.SetChecked(iv.mapping.HasHeader)
+	header.OnChanged = func(b bool) {
+		iv.mapping.HasHeader = b
+		iv.preview()
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem(lang.L("date"), dateSelect),
+		widget.NewFormItem(lang.L("begin"), beginSelect),
+		widget.NewFormItem(lang.L("end"), endSelect),
+		widget.NewFormItem(lang.L("break"), pauseSelect),
+		widget.NewFormItem(lang.L("breakStart"), pauseStartSelect),
+		widget.NewFormItem(lang.L("absence"), absenceSelect),
+		widget.NewFormItem(lang.L("dateFormat"), dateFormat),
+		widget.NewFormItem(lang.L("timeFormat"), timeFormat),
+		widget.NewFormItem("", header),
+	)
+
+	iv.list = widget.NewList(
+		func() int {
+			if iv.report == nil {
+				return 0
+			}
+			return min(len(iv.report.Rows), maxPreviewRows)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Row")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(formatImportRow(iv.report.Rows[i]))
+		},
+	)
+	iv.summary = widget.NewLabel("")
+
+	iv.container = container.NewBorder(form, iv.summary, nil, nil, iv.list)
+	iv.preview()
+	return iv
+}
+
+// Shows the mapping and the preview, the valid rows are imported on confirm
+func (iv *ImportView) Show() {
+	dia := dialog.NewCustomConfirm(lang.L("importWorktime"), lang.L("import"), lang.L("cancel"),
+		iv.container, func(b bool) {
+			if !b {
+				return
+			}
+			iv.importRows()
+		}, iv.av.window)
+	dia.Resize(fyne.NewSize(600, 600))
+	dia.Show()
+}
+
+func (iv *ImportView) columnSelect(column *int, optional bool) *widget.Select {
+	options := iv.columns
+	if optional {
+		options = append([]string{lang.L("none")}, options...)
+	}
+
+	s := widget.NewSelect(options, nil)
+	if *column >= 0 && *column < len(iv.columns) {
+		s.SetSelected(iv.columns[*column])
+	} else if optional {
+		s.SetSelectedIndex(0)
+	}
+	s.OnChanged = func(string) {
+		*column = s.SelectedIndex()
+		if optional {
+			// The first option is no column
+			*column--
+		}
+		iv.preview()
+	}
+	return s
+}
+
+// Names a column by its letter and the value of the first row
+func (iv *ImportView) columnName(i int) string {
+	name := string(rune('A' + i%26))
+	if i >= 26 {
+		name = string(rune('A'+i/26-1)) + name
+	}
+	if len(iv.records) > 0 && i < len(iv.records[0]) && strings.TrimSpace(iv.records[0][i]) != "" {
+		name += " (" + strings.TrimSpace(iv.records[0][i]) + ")"
+	}
+	return name
+}
+
+// Checks the rows against the database without importing them
+func (iv *ImportView) preview() {
+	report, err := iv.is.Import(iv.records, iv.mapping, true)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, iv.av.window)
+		return
+	}
+	iv.report = report
+	iv.summary.SetText(formatImportSummary(report))
+	iv.list.Refresh()
+}
+
+func (iv *ImportView) importRows() {
+	report, err := iv.is.Import(iv.records, iv.mapping, false)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, iv.av.window)
+		return
+	}
+
+	// Report the skipped rows so they can be fixed in the file
+	var b strings.Builder
+	b.WriteString(formatImportSummary(report))
+	for _, r := range report.Skipped() {
+		fmt.Fprintf(&b, "\n%s %d: %s", lang.L("row"), r.Line, r.Reason)
+	}
+	content := widget.NewLabel(b.String())
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(400, 200))
+	dialog.ShowCustom(lang.L("importWorktime"), lang.L("close"), scroll, iv.av.window)
+}
+
+// Pauses are never made up, the summary tells which column a row with a pause needs
+func formatImportSummary(report *service.ImportReport) string {
+	key := "importSummary"
+	if !report.DryRun {
+		key = "importDone"
+	}
+	return lang.L(key, map[string]any{
+		"Valid":     report.Count(service.ImportRowOK),
+		"Invalid":   report.Count(service.ImportRowInvalid),
+		"Conflicts": report.Count(service.ImportRowConflict),
+	}) + "\n" + lang.L("importBreakHint")
+}
+
+func formatImportRow(r *service.ImportRow) string {
+	prefix := lang.L("row") + " " + strconv.Itoa(r.Line) + ": "
+	switch r.Status {
+	case service.ImportRowInvalid:
+		return prefix + "❌ " + r.Reason
+	case service.ImportRowConflict:
+		return prefix + "⚠️ " + r.Reason
+	}
+
+	text := prefix + r.Date.Format(model.DATEFORMAT)
+	if !r.Begin.IsZero() {
+		text += " " + r.Begin.Format("15:04") + " - " + r.End.Format("15:04")
+	}
+	if r.Pause > 0 {
+		text += " (" + lang.L("break") + " " + r.PauseStart.Format("15:04") + " " +
+			model.FormatDuration(r.Pause) + ")"
+	}
+	for _, a := range r.Absences {
+		text += " " + fwidget.AbsenceLabel(a.Type)
+		if a.Share < 1 {
+			text += " (" + lang.L("halfDay") + ")"
+		}
+	}
+	return text
+}
@@ -1,16 +1,19 @@
 package view
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"sort"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/FyningTime/FyningTime/app/model"
 	"github.com/FyningTime/FyningTime/app/model/db"
 	"github.com/FyningTime/FyningTime/app/repo"
 	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
 
 	"github.com/charmbracelet/log"
 
@@ -19,6 +22,7 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -39,29 +43,66 @@ type AppView struct {
 
 	// Dynamic data binding
 	allOvertime binding.String
+	// Worked time of today including the open entry
+	today binding.String
 
 	// Actual db abstraction
-	worktime  []*db.Worktime
-	workday   []*db.Workday
-	vacations []*db.Vacation
+	worktime []*db.Worktime
+	workday  []*db.Workday
+	absences []*db.Absence
+	// Violated working-time rules by workday id
+	warnings map[int64][]*service.ComplianceWarning
+	// Projects and tasks sorted by name
+	projects    []*db.Project
+	tasks       []*db.Task
+	projectByID map[int64]*db.Project
+	taskByID    map[int64]*db.Task
+
+	// Called after the projects are loaded, e.g. to rebuild the tray menu
+	OnProjectsLoaded func()
 
 	cv  *CalenderView
 	vpv *VacationPlannerView
+	dv  *DetailsView
 
 	// Selected item
 	selectedItem *widget.TableCellID
+	// Project the time is recorded for
+	projectSelect *widget.Select
 
 	// Holds the database connection
 	repo *repo.SQLiteRepository
+	// Records time entries and calculates the overtime
+	ts *service.TimeEntryService
+	// Sends desktop notifications while clocked in
+	ns *service.NotificationService
+	// Clocks in on start and out on shutdown
+	ss *service.SessionService
+	// Stops the heartbeat, closed when the session ends
+	stopSession chan struct{}
+	endSession  sync.Once
+	// Serializes the refreshes of the data
+	refreshMu sync.Mutex
+	// Last loaded data, the changes are applied to it
+	data *viewData
+	// Proposes idle periods as breaks
+	im *service.IdleMonitor
+	// Recalculates changed workdays in the background
+	scheduler *service.Scheduler
+	// Canceled when the app is closed
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (av *AppView) CreateUI(w fyne.Window, a fyne.App) *fyne.Container {
 	// Assign the window to the main app struct
 	av.window = w
 	av.a = a
+	av.ns = service.NewNotificationService(a, a.Preferences())
 
 	av.allOvertime = binding.NewString()
 	av.allOvertime.Set(lang.L("calculateOvertime"))
+	av.today = binding.NewString()
 
 	extraColumns := len(av.baseHeaders)
 
@@ -93,30 +134,39 @@ func (av *AppView) CreateUI(w fyne.Window, a fyne.App) *fyne.Container {
 
 			switch i.Col {
 			case 0:
-				label.SetText(wd.Date.Format(model.DATEFORMAT) +
-					" / " + model.ShortenWeekday(wd.Date.Weekday().String()))
+				text := wd.Date.Format(model.DATEFORMAT) +
+					" / " + model.ShortenWeekday(wd.Date.Weekday().String())
 				label.TextStyle = fyne.TextStyle{Bold: true}
+				// Violated working-time rules are listed in the compliance report
+				if len(av.warnings[wd.ID]) > 0 {
+					text += " ⚠"
+					label.Importance = widget.WarningImportance
+				}
+				label.SetText(text)
 			case 1:
-				label.SetText(wd.Time)
+				label.SetText(wd.Time.String())
 			case 2:
-				label.SetText(wd.Breaktime)
+				label.SetText(model.FormatDuration(wd.Breaktime))
 			case 3:
-				overtimeAsFloat, err := time.ParseDuration(wd.Overtime)
-				if err != nil {
-					log.Error("Overtime table field", "overtime", wd.Overtime, "error", err)
-					label.SetText("")
-				} else {
-					if overtimeAsFloat < 0 {
-						label.TextStyle = fyne.TextStyle{Bold: true}
-						label.Importance = widget.HighImportance
-					}
-					label.SetText(wd.Overtime)
+				if wd.Overtime < 0 {
+					label.TextStyle = fyne.TextStyle{Bold: true}
+					label.Importance = widget.HighImportance
 				}
+				label.SetText(wd.Overtime.String())
 
 			default:
 				if i.Col-extraColumns < len(wtday) && i.Col > 1 {
 					currentWt := wtday[i.Col-extraColumns]
-					label.SetText(currentWt.Time.Format(time.TimeOnly))
+					text := currentWt.Time.Format(time.TimeOnly)
+					// The segment is attributed on its Begin entry
+					if code := av.projectCode(currentWt.ProjectID); code != "" {
+						text += " " + code
+					}
+					// Corrections are listed in the exports
+					if currentWt.Corrected {
+						text += " ✎"
+					}
+					label.SetText(text)
 				} else {
 					label.SetText("")
 				}
@@ -163,24 +213,30 @@ func (av *AppView) CreateUI(w fyne.Window, a fyne.App) *fyne.Container {
 	btnAddTimeToolbarItem := widget.NewToolbarAction(theme.ContentAddIcon(), av.AddTimeEntry)
 	btnDeleteTimeToolbarItem := widget.NewToolbarAction(theme.ContentRemoveIcon(), av.deleteButtonFunc)
 	btnEditTimeToolbarItem := widget.NewToolbarAction(theme.DocumentIcon(), av.editButtonFunc)
-	btnRefreshDataToolbarItem := widget.NewToolbarAction(theme.ViewRefreshIcon(), func() {
-		fyne.Do(func() {
-			av.RefreshData()
-		})
-	})
+	btnUndoToolbarItem := widget.NewToolbarAction(theme.ContentUndoIcon(), av.Undo)
+	btnRedoToolbarItem := widget.NewToolbarAction(theme.ContentRedoIcon(), av.Redo)
+	btnAssignProjectToolbarItem := widget.NewToolbarAction(theme.ListIcon(), av.AssignSelectedSegment)
+	btnRefreshDataToolbarItem := widget.NewToolbarAction(theme.ViewRefreshIcon(), av.RefreshData)
 
 	timeToolbar := widget.NewToolbar(
 		btnAddTimeToolbarItem,
 		btnDeleteTimeToolbarItem,
 		btnEditTimeToolbarItem,
+		btnAssignProjectToolbarItem,
+		btnUndoToolbarItem,
+		btnRedoToolbarItem,
 		btnRefreshDataToolbarItem,
 	)
+	av.projectSelect = av.newProjectSelect()
 
 	topBar := container.NewHBox(
 		timeToolbar,
 		widget.NewSeparator(),
 		widget.NewLabelWithData(av.allOvertime),
 		widget.NewSeparator(),
+		widget.NewLabelWithData(av.today),
+		widget.NewSeparator(),
+		av.projectSelect,
 		/*widget.NewButtonWithIcon("Scroll up", theme.MoveUpIcon(), func() {
 			av.timetable.ScrollToTop()
 		}),*/
@@ -188,20 +244,37 @@ func (av *AppView) CreateUI(w fyne.Window, a fyne.App) *fyne.Container {
 
 	timerContainer := container.NewBorder(topBar, nil, nil, nil, tt)
 
-	av.cv = CreateCalendarView(av.window, av.vacations, time.Now())
-	av.vpv = CreateVacationPlannerView(av, av.repo, av.vacations)
+	av.cv = CreateCalendarView(av.window, av.absences, time.Now(), func(date time.Time) {
+		ShowDayEditor(av, date)
+	})
+	av.vpv = CreateVacationPlannerView(av, av.repo, av.absences)
+	av.dv = CreateDetailsView(av)
 
 	// Add appbar
+	detailsTab := container.NewTabItem(lang.L("details"), av.dv.container)
 	appTabs := container.NewAppTabs(
 		container.NewTabItem(lang.L("timer"), timerContainer),
 		container.NewTabItem(lang.L("calendar"), av.cv.container),
 		container.NewTabItem(lang.L("vacationPlanner"), av.vpv.container),
-		container.NewTabItem(lang.L("details"), widget.NewLabel(lang.L("commingSoon"))),
+		detailsTab,
 	)
+	appTabs.OnSelected = func(tab *container.TabItem) {
+		// The statistics are only calculated when they are shown
+		if tab == detailsTab {
+			av.dv.Refresh()
+		}
+	}
 
 	appContainer := container.NewBorder(nil, nil, nil, nil, appTabs)
 
-	go av.calculateBreakLoop()
+	av.ctx, av.cancel = context.WithCancel(context.Background())
+	av.scheduler = service.NewScheduler(av.ts, func() *model.Settings {
+		return service.ReadProperties(a)
+	})
+	av.scheduler.OnChange = av.applyChanges
+	av.scheduler.OnTick = av.onTick
+	go av.scheduler.Run(av.ctx)
+
 	return appContainer
 }
 
@@ -234,61 +307,59 @@ func (av *AppView) buildHeaders() {
 }
 
 func (av *AppView) AddTimeEntry() {
-	// Add a time entry to current date
-	loc, _ := time.LoadLocation("Europe/Berlin")
-	today, err := av.repo.GetWorkday(time.Now())
-	log.Debug("Weekday", "weekday", time.Now().Weekday())
-	if today == nil && err != nil {
-		log.Debug("Create new workday")
-		w := &db.Workday{
-			Date: time.Now(),
-		}
-		newWd, wdErr := av.repo.AddWorkday(w)
-		if wdErr != nil {
-			log.Error(wdErr)
-		} else {
-			av.workday = append(av.workday, newWd)
-			wt := &db.Worktime{
-				Type:    "Begin", // As it is a new workday, it is always a begin
-				Time:    time.Now().In(loc),
-				Workday: *newWd,
-			}
+	now := time.Now()
+	settings := service.ReadProperties(av.a)
+	status, err := av.ts.Status(settings, now)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, av.window)
+		return
+	}
 
-			_, wtErr := av.repo.AddWorktime(wt)
-			if wtErr != nil {
-				log.Error(wtErr)
-			}
-		}
+	// Clocking in starts a segment of the current project
+	if status.Open == nil && settings.CurrentProject != 0 {
+		_, err = av.ts.ClockInProject(now, settings.CurrentProject, settings.CurrentTask)
 	} else {
-		log.Info("Workday already exists")
-		allWt, err := av.repo.GetAllWorktime(today)
-
-		if err != nil {
-			log.Fatal(err)
-		} else {
-			log.Debug("Size of worktimes", "size", len(av.worktime))
-			wtType := "Begin"
-			if len(allWt)%2 != 0 {
-				wtType = "End"
-			}
-
-			wt := &db.Worktime{
-				Type:    wtType,
-				Time:    time.Now().In(loc),
-				Workday: *today,
-			}
-			av.repo.AddWorktime(wt)
-		}
+		// Add a time entry to current date
+		_, err = av.ts.Toggle(now)
+	}
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, av.window)
+		return
 	}
-
-	// Refresh *all data*
-	go av.calculateBreak(true)
 }
 
 func (av *AppView) EditSelectedTimeEntry() {
 	av.editButtonFunc()
 }
 
+// Reverts the last change of the time entries or absences
+func (av *AppView) Undo() {
+	entry, err := av.repo.Undo()
+	if errors.Is(err, repo.ErrNothingToUndo) {
+		log.Info("Nothing to undo")
+		return
+	} else if err != nil {
+		dialog.ShowError(err, av.window)
+		return
+	}
+	log.Info("Undone", "action", entry.Action)
+}
+
+// Applies the last undone change again
+func (av *AppView) Redo() {
+	entry, err := av.repo.Redo()
+	if errors.Is(err, repo.ErrNothingToRedo) {
+		log.Info("Nothing to redo")
+		return
+	} else if err != nil {
+		dialog.ShowError(err, av.window)
+		return
+	}
+	log.Info("Redone", "action", entry.Action)
+}
+
 func (av *AppView) UnselectTableItem() {
 	if av.selectedItem != nil {
 		log.Debug("Unselect item", "item", av.selectedItem)
@@ -310,7 +381,7 @@ func (av *AppView) DeleteSelectedTimeEntry() {
 			return
 		}
 		log.Info("Delete selected time entry", "worktime", wt)
-		defer av.deleteTimeEntry(wt)
+		defer av.deleteTimeEntry(wt, "")
 	}
 }
 
@@ -320,6 +391,7 @@ func (av *AppView) AddHeaders(headers []string) {
 
 func (av *AppView) CreateRepository(db *sql.DB) {
 	av.repo = repo.NewSQLiteRepository(db)
+	av.ts = service.NewTimeEntryService(av.repo)
 
 	// On start we also try to migrate the database
 	log.Info("Migrating database")
@@ -327,49 +399,594 @@ func (av *AppView) CreateRepository(db *sql.DB) {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// Undo covers the changes of the current day, also after a restart
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if err := service.NewAuditService(av.repo).Prune(today); err != nil {
+		log.Error(err)
+	}
 }
 
-// TODO maybe limit this for a specific date range like month
-func (av *AppView) RefreshData() {
-	wd, wdErr := av.repo.GetAllWorkday(repo.DESC)
-	if wdErr != nil {
-		log.Error(wdErr)
-	} else {
-		av.workday = nil
-		av.workday = wd
+/*
+Clocks in if automatic clock-in is enabled. If the app was not closed
+properly the last heartbeat is proposed as the missing End, the session
+begins once the proposal is answered.
+*/
+func (av *AppView) StartSession() {
+	settings := service.ReadProperties(av.a)
+	if !settings.AutoClock {
+		return
 	}
+	heartbeatPath, err := service.GetFyningTimePath(model.HEARTBEATFILE)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	av.ss = service.NewSessionService(av.ts, heartbeatPath)
+	av.stopSession = make(chan struct{})
 
-	av.worktime = nil
-	for i := range wd {
-		wt, wtErr := av.repo.GetAllWorktime(wd[i])
-		if wtErr != nil {
-			log.Error(wtErr)
-		} else {
-			av.worktime = append(av.worktime, wt[0:]...)
+	// The app runs since now, not since the proposal is answered
+	start := time.Now()
+	missingEnd, ok, err := av.ss.MissingEnd()
+	if err != nil {
+		log.Error(err)
+	} else if ok {
+		av.proposeMissingEnd(missingEnd, start)
+		return
+	}
+	av.beginSession(start)
+}
+
+// Clocks in at the start of the app and writes the heartbeat until the session stops
+func (av *AppView) beginSession(start time.Time) {
+	select {
+	case <-av.stopSession:
+		return
+	default:
+	}
+
+	if _, err := av.ss.Start(start); err != nil {
+		log.Error(err)
+		dialog.ShowError(err, av.window)
+	}
+
+	go func() {
+		ticker := time.NewTicker(service.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			if err := av.ss.Beat(time.Now()); err != nil {
+				log.Error(err)
+			}
+			select {
+			case <-ticker.C:
+			case <-av.stopSession:
+				return
+			}
 		}
+	}()
+}
+
+// Recalculates all workdays after the settings were saved
+func (av *AppView) SettingsChanged() {
+	av.scheduler.RecalculateAll()
+}
+
+/*
+Stops the background work and clocks out if automatic clock-in is
+enabled. It is called when the window is closed and when the session
+of the user ends.
+*/
+func (av *AppView) Close() {
+	av.cancel()
+	av.StopSession()
+}
+
+// Clocks out if automatic clock-in is enabled
+func (av *AppView) StopSession() {
+	if av.ss == nil {
+		return
 	}
+	av.endSession.Do(func() {
+		close(av.stopSession)
+		if _, err := av.ss.Stop(time.Now()); err != nil {
+			log.Error(err)
+		}
+	})
+}
+
+/*
+Watches the idle time if idle detection is enabled. When the user returns
+after an idle period it is proposed as break of the open entry.
+*/
+func (av *AppView) StartIdleMonitor() {
+	settings := service.ReadProperties(av.a)
+	if !settings.IdleDetection {
+		return
+	}
+	source, err := service.NewSystemIdleSource()
+	if err != nil {
+		log.Warn("Idle detection is disabled", "error", err)
+		return
+	}
+	av.im = service.NewIdleMonitor(source, time.Duration(settings.IdleMinutes)*time.Minute)
+
+	go func() {
+		ticker := time.NewTicker(service.IdlePollInterval)
+		defer ticker.Stop()
+		defer av.im.Close()
+		for {
+			select {
+			case <-av.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			span, err := av.im.Poll(time.Now())
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			if span != nil {
+				fyne.Do(func() { av.proposeBreak(span) })
+			}
+		}
+	}()
+}
 
-	// Get vacations
-	v, err := av.repo.GetAllVacation()
+// Asks whether the idle span should be recorded as break
+func (av *AppView) proposeBreak(span *service.IdleSpan) {
+	status, err := av.ts.Status(service.ReadProperties(av.a), span.End)
 	if err != nil {
 		log.Error(err)
-	} else {
-		av.vacations = v
-		if av.vpv != nil {
-			av.vpv.UpdateVacations(v)
+		return
+	}
+	// Only a running day can get a break
+	if status.Open == nil || !span.Start.After(status.Open.Time) {
+		return
+	}
+
+	message := lang.L("idleBreakMessage", map[string]any{
+		"Begin": span.Start.Format("15:04"),
+		"End":   span.End.Format("15:04"),
+	})
+	dialog.ShowConfirm(lang.L("idleBreak"), message, func(b bool) {
+		if !b {
+			return
+		}
+		if err := av.ts.RecordBreak(span.Start, span.End); err != nil {
+			log.Error(err)
+			dialog.ShowError(err, av.window)
 		}
-		if av.cv != nil {
-			av.cv.UpdateVacations(v)
+	}, av.window)
+}
+
+// Asks whether the heartbeat should be recorded as End, the session begins afterwards
+func (av *AppView) proposeMissingEnd(at time.Time, start time.Time) {
+	message := lang.L("missingEndMessage", map[string]any{
+		"Date": at.Format(model.DATEFORMAT),
+		"Time": at.Format("15:04"),
+	})
+	dialog.ShowConfirm(lang.L("missingEnd"), message, func(b bool) {
+		if b {
+			if _, err := av.ss.RecordMissingEnd(at, start); err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+			}
 		}
+		av.beginSession(start)
+	}, av.window)
+}
+
+// Data of the views, it is loaded in the background
+type viewData struct {
+	workdays  []*db.Workday
+	worktimes []*db.Worktime
+	warnings  map[int64][]*service.ComplianceWarning
+	absences  []*db.Absence
+	holidays  fwidget.HolidayLookup
+	projects  map[int64]*db.Project
+	tasks     map[int64]*db.Task
+	// Figures of the workdays, the total is the overtime
+	result *service.CalculationResult
+}
+
+/*
+Loads all data in the background and shows it on the UI thread afterwards,
+the overtime of all workdays is recalculated.
+*/
+func (av *AppView) RefreshData() {
+	av.refresh(func(*viewData) (*viewData, error) {
+		return av.loadData()
+	})
+}
+
+/*
+Updates the loaded data after the scheduler recalculated changed data.
+Only the changed workdays are reloaded, the days after them get a new
+balance. Everything is loaded again when all workdays were recalculated.
+*/
+func (av *AppView) applyChanges(rc service.Recalculation) {
+	if rc.All || rc.Kinds[repo.ChangeWorktime] {
+		av.RefreshData()
+		return
 	}
+	av.refresh(func(loaded *viewData) (*viewData, error) {
+		if loaded == nil {
+			return av.loadData()
+		}
+		return av.updateData(loaded, rc)
+	})
+}
 
-	av.allOvertime.Set(lang.L("calculateOvertime"))
-	av.calculateOvertime()
+/*
+Runs the given load in the background and shows its data on the UI thread.
+Refreshes run one after another, so the latest data is shown last. The
+project budgets only change with the data, so they are checked here.
+*/
+func (av *AppView) refresh(load func(loaded *viewData) (*viewData, error)) {
+	go func() {
+		av.refreshMu.Lock()
+		defer av.refreshMu.Unlock()
+
+		data, err := load(av.data)
+		if err != nil {
+			log.Error(err)
+			fyne.Do(func() { dialog.ShowError(err, av.window) })
+			return
+		}
+		av.data = data
+		fyne.DoAndWait(func() { av.showData(data) })
+
+		if service.ReadProperties(av.a).NotifyBudget {
+			av.checkBudgets()
+		}
+	}()
+}
+
+// Reads the data of the views and stores the overtimes which changed
+func (av *AppView) loadData() (*viewData, error) {
+	settings := service.ReadProperties(av.a)
+	data := &viewData{}
+
+	var err error
+	if data.workdays, err = av.repo.GetAllWorkday(repo.DESC); err != nil {
+		return nil, err
+	}
+	if data.worktimes, err = av.repo.GetAllWorktimes(); err != nil {
+		return nil, err
+	}
+	if data.projects, data.tasks, err = service.NewProjectService(av.repo).Lookup(); err != nil {
+		return nil, err
+	}
+	if data.absences, err = av.repo.GetAllAbsence(); err != nil {
+		return nil, err
+	}
+	data.holidays = av.newHolidayCalendar(settings).HolidayOn
+	data.warnings = service.WarningsByWorkday(
+		service.CheckCompliance(data.workdays, data.worktimes, service.DefaultComplianceLimits))
+
+	result := av.newCalculator(settings).Calculate(data.workdays, data.worktimes)
+	if err := av.storeOvertimes(settings, result); err != nil {
+		return nil, err
+	}
+	data.result = result
+	return data, nil
+}
+
+/*
+Returns the loaded data with the given changes. The workdays are copied,
+so the shown data is not modified in the background.
+*/
+func (av *AppView) updateData(loaded *viewData, rc service.Recalculation) (*viewData, error) {
+	settings := service.ReadProperties(av.a)
+	data := *loaded
+	data.workdays = make([]*db.Workday, len(loaded.workdays))
+	for i, wd := range loaded.workdays {
+		copied := *wd
+		data.workdays[i] = &copied
+	}
+
+	// First day whose figures changed
+	var from time.Time
+	recalculate := false
+	changedFrom := func(date time.Time) {
+		if !recalculate || date.Before(from) {
+			from = date
+		}
+		recalculate = true
+	}
+
+	var err error
+	if rc.Kinds[repo.ChangeProject] {
+		if data.projects, data.tasks, err = service.NewProjectService(av.repo).Lookup(); err != nil {
+			return nil, err
+		}
+	}
+	if rc.Kinds[repo.ChangeAbsence] {
+		if data.absences, err = av.repo.GetAllAbsence(); err != nil {
+			return nil, err
+		}
+		if date, ok := firstChangedAbsence(loaded.absences, data.absences); ok {
+			changedFrom(date)
+		}
+	}
+	if rc.Kinds[repo.ChangeHoliday] || rc.Kinds[repo.ChangeContract] {
+		// The targets of any day may have changed
+		data.holidays = av.newHolidayCalendar(settings).HolidayOn
+		changedFrom(time.Time{})
+	}
+	if len(rc.Workdays) > 0 {
+		date, err := av.reloadWorkdays(&data, rc.Workdays)
+		if err != nil {
+			return nil, err
+		}
+		changedFrom(date)
+		data.warnings = updateWarnings(loaded.warnings, &data, date)
+	}
+
+	if recalculate {
+		result := av.newCalculator(settings).Recalculate(loaded.result, from, data.workdays, data.worktimes)
+		if err := av.storeOvertimes(settings, result); err != nil {
+			return nil, err
+		}
+		data.result = result
+	}
+	return &data, nil
+}
+
+/*
+Replaces the given workdays and their worktimes with the stored ones,
+deleted workdays are removed. Returns the first date of the workdays
+before and after the change.
+*/
+func (av *AppView) reloadWorkdays(data *viewData, ids []int64) (time.Time, error) {
+	changed := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		changed[id] = true
+	}
+
+	var first time.Time
+	firstDate := func(date time.Time) {
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+	}
+
+	var workdays []*db.Workday
+	for _, wd := range data.workdays {
+		if changed[wd.ID] {
+			firstDate(wd.Date)
+		} else {
+			workdays = append(workdays, wd)
+		}
+	}
+	var worktimes []*db.Worktime
+	for _, wt := range data.worktimes {
+		if !changed[wt.Workday.ID] {
+			worktimes = append(worktimes, wt)
+		}
+	}
+
+	for _, id := range ids {
+		wd, err := av.repo.GetWorkdayByID(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The workday was deleted
+			continue
+		} else if err != nil {
+			return first, err
+		}
+		wts, err := av.repo.GetAllWorktime(wd)
+		if err != nil {
+			return first, err
+		}
+		firstDate(wd.Date)
+		workdays = append(workdays, wd)
+		worktimes = append(worktimes, wts...)
+	}
+
+	// Same order as loaded from the repository
+	sort.SliceStable(workdays, func(i, j int) bool {
+		return workdays[i].Date.After(workdays[j].Date)
+	})
+	sort.Slice(worktimes, func(i, j int) bool {
+		return worktimes[i].ID < worktimes[j].ID
+	})
+	data.workdays, data.worktimes = workdays, worktimes
+	return first, nil
+}
+
+/*
+Checks the compliance of the workdays from the given date on again, the
+warnings of the days before are kept. The day before the date is checked
+along, because the rest time depends on its End.
+*/
+func updateWarnings(loaded map[int64][]*service.ComplianceWarning, data *viewData, from time.Time) map[int64][]*service.ComplianceWarning {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	warnings := make(map[int64][]*service.ComplianceWarning)
+	var days []*db.Workday
+	// The workdays are sorted descending
+	for _, wd := range data.workdays {
+		if !wd.Date.Before(from) {
+			days = append(days, wd)
+			continue
+		}
+		if len(days) == 0 || !days[len(days)-1].Date.Before(from) {
+			days = append(days, wd)
+		}
+		if ws, ok := loaded[wd.ID]; ok {
+			warnings[wd.ID] = ws
+		}
+	}
+
+	for _, w := range service.CheckCompliance(days, data.worktimes, service.DefaultComplianceLimits) {
+		if !w.Workday.Date.Before(from) {
+			warnings[w.Workday.ID] = append(warnings[w.Workday.ID], w)
+		}
+	}
+	return warnings
+}
+
+// Returns the first date of the absences which were added, changed or deleted
+func firstChangedAbsence(loaded []*db.Absence, absences []*db.Absence) (time.Time, bool) {
+	byID := make(map[int64]*db.Absence, len(loaded))
+	for _, a := range loaded {
+		byID[a.ID] = a
+	}
+
+	var first time.Time
+	found := false
+	firstDate := func(date time.Time) {
+		if !found || date.Before(first) {
+			first = date
+		}
+		found = true
+	}
+	for _, a := range absences {
+		old, ok := byID[a.ID]
+		delete(byID, a.ID)
+		if ok && old.Type == a.Type && old.StartDate.Equal(a.StartDate) && old.EndDate.Equal(a.EndDate) &&
+			old.HalfDayStart == a.HalfDayStart && old.HalfDayEnd == a.HalfDayEnd {
+			continue
+		}
+		firstDate(a.StartDate)
+		if ok {
+			firstDate(old.StartDate)
+		}
+	}
+	// Deleted absences
+	for _, a := range byID {
+		firstDate(a.StartDate)
+	}
+	return first, found
+}
+
+// Shows the loaded data, it has to run on the UI thread
+func (av *AppView) showData(data *viewData) {
+	av.workday, av.worktime = data.workdays, data.worktimes
+	av.warnings = data.warnings
+	av.showProjects(data.projects, data.tasks)
+
+	av.absences = data.absences
+	if av.vpv != nil {
+		av.vpv.UpdateAbsences(data.absences)
+	}
+	if av.cv != nil {
+		av.cv.UpdateAbsences(data.absences)
+		av.cv.UpdateHolidays(data.holidays)
+	}
+
+	av.allOvertime.Set(lang.L("totalOvertime") + ": " + data.result.Total.String())
 
 	// Re-build headers
 	av.refreshTimetable()
 }
 
+/*
+Imports holidays from an iCalendar file, they are added to
+the built-in holidays of the configured region
+*/
+func (av *AppView) ImportHolidays() {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+		if reader == nil {
+			// Canceled
+			return
+		}
+		defer reader.Close()
+
+		holidays, err := service.ParseICalendar(reader)
+		if err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+		if err := av.repo.AddHolidays(holidays); err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+
+		dialog.ShowInformation(lang.L("importHolidays"),
+			lang.L("holidaysImported", map[string]any{"Count": len(holidays)}), av.window)
+	}, av.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".ics"}))
+	fd.Show()
+}
+
+// Imports worktime from a CSV, XLSX or JSON export file after the columns are mapped
+func (av *AppView) ImportWorktime() {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+		if reader == nil {
+			// Canceled
+			return
+		}
+		defer reader.Close()
+
+		records, err := service.ReadImportFile(reader.URI().Name(), reader)
+		if err != nil {
+			log.Error(err)
+			dialog.ShowError(err, av.window)
+			return
+		}
+		CreateImportView(av, service.NewImporterService(av.repo), records).Show()
+	}, av.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".xlsx", ".json"}))
+	fd.Show()
+}
+
+// Exports the worktime of a date range for payroll
+func (av *AppView) ExportWorktime() {
+	ShowExportView(av)
+}
+
+// Saves the timesheet of a month as PDF
+func (av *AppView) ExportTimesheet() {
+	ShowTimesheetView(av)
+}
+
+// Lists the violated working-time rules of all workdays
+func (av *AppView) ShowComplianceReport() {
+	ShowComplianceReport(av)
+}
+
+// Removes all imported holidays, the built-in holidays stay
+func (av *AppView) DeleteImportedHolidays() {
+	dialog.ShowConfirm(lang.L("deleteImportedHolidays"), lang.L("areYouSureDeleteHolidays"), func(b bool) {
+		if !b {
+			return
+		}
+		if _, err := av.repo.DeleteAllHoliday(); err != nil {
+			dialog.ShowError(err, av.window)
+		}
+	}, av.window)
+}
+
+// Shows the dialog to manage the contracts
+func (av *AppView) ShowContracts() {
+	CreateContractView(av, av.repo).Show()
+}
+
+// Shows the dialog to manage the projects and tasks
+func (av *AppView) ShowProjects() {
+	CreateProjectView(av, av.repo).Show()
+}
+
+// Shows the recorded time per project of a date range
+func (av *AppView) ShowProjectReport() {
+	ShowProjectReport(av)
+}
+
+// Shows the used budgets of the projects with their burn-down
+func (av *AppView) ShowProjectBudgets() {
+	CreateBudgetView(av).Show()
+}
+
 func (av *AppView) GetOvertime() string {
 	totalOvertime, err := av.allOvertime.Get()
 	if err != nil {
@@ -388,21 +1005,16 @@ func (av *AppView) deleteButtonFunc() {
 		dialog.ShowError(errors.New(lang.L("noItemSelected")), av.window)
 		return
 	} else if av.selectedItem.Col == 0 {
-		dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDelete"), func(b bool) {
-			if b {
-				// Delete the whole day
-				wd := av.workday[av.selectedItem.Row]
-				log.Info("Delete workday", "workday", wd)
-				// Deletion here
-				rows, err := av.repo.DeleteWorkday(wd)
-				if rows != 0 || err == nil {
-					// Refresh *all data*
-					av.refreshAll()
-				} else {
-					dialog.ShowError(errors.New(lang.L("couldNotDeleteDataset")), av.window)
-				}
+		av.confirmWithReason(lang.L("deleteEntry"), lang.L("areYouSureDelete"), func(reason string) {
+			// Delete the whole day
+			wd := av.workday[av.selectedItem.Row]
+			log.Info("Delete workday", "workday", wd)
+			// Deletion here
+			rows, err := av.repo.DeleteWorkday(wd, reason)
+			if rows == 0 || err != nil {
+				dialog.ShowError(errors.New(lang.L("couldNotDeleteDataset")), av.window)
 			}
-		}, av.window)
+		})
 	} else if av.selectedItem.Col == 1 {
 		dialog.ShowError(errors.New(lang.L("noItemSelected")), av.window)
 	} else {
@@ -412,19 +1024,13 @@ func (av *AppView) deleteButtonFunc() {
 		// Delete only if there is an existing item and no error
 		// Else show an error dialog
 		if wt != nil && err == nil {
-			dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDelete"), func(b bool) {
-				if b {
-					defer av.deleteTimeEntry(wt)
-				} else {
-					log.Debug("Delete time entry", "canceled", b)
-				}
-			}, av.window)
+			av.confirmWithReason(lang.L("deleteEntry"), lang.L("areYouSureDelete"), func(reason string) {
+				av.deleteTimeEntry(wt, reason)
+			})
 		} else {
 			dialog.ShowError(errors.New(lang.L("noItemSelected")), av.window)
 		}
 	}
-
-	go av.calculateBreak(true)
 }
 
 func (av *AppView) getTimeEntry(item *widget.TableCellID) (*db.Workday, *db.Worktime, error) {
@@ -457,288 +1063,89 @@ func (av *AppView) getTimeEntry(item *widget.TableCellID) (*db.Workday, *db.Work
 	}
 }
 
-// TODO Important! simplify this function
+// Opens the day editor for the day of the selected row or for today
 func (av *AppView) editButtonFunc() {
-	loc, _ := time.LoadLocation("Europe/Berlin")
-
-	// Declare if we have to add or update a time entry
-	isAdd := false
-
-	log.Info("Edit time entry", "item", av.selectedItem)
-	// Check if is a day or a time entry selected
-	// Assure that there is an item selected
-	if av.selectedItem == nil || av.selectedItem.Col == 0 || av.selectedItem.Col == 1 {
-		dialog.ShowError(errors.New(lang.L("noWorktimeSelected")), av.window)
-		return
+	date := time.Now()
+	if av.selectedItem != nil && av.selectedItem.Row < len(av.workday) {
+		date = av.workday[av.selectedItem.Row].Date
 	}
+	ShowDayEditor(av, date)
+}
 
-	wd, wt, err := av.getTimeEntry(av.selectedItem)
-	if err != nil && wt == nil {
-		// If no worktime is found, we try to use the previous worktime. Maybe it was forgot to end the worktime
-		av.selectedItem.Col--
-
-		log.Debug("Get fallback time entry", "item", av.selectedItem)
-		wd, wt, err = av.getTimeEntry(av.selectedItem)
-		if err != nil {
-			dialog.ShowError(err, av.window)
-			return
-		}
-		isAdd = true
-		if wt.Type == "End" {
-			wt.Type = "Begin"
-		} else {
-			wt.Type = "End"
-		}
-	} else if wt.Workday.Date.Before(time.Now().In(loc)) {
-		isAdd = false
+/*
+Shows the worked time of today and sends the due notifications,
+it is called by the scheduler on every tick
+*/
+func (av *AppView) onTick(settings *model.Settings, today *service.DayStatus) {
+	text := ""
+	if today.Result != nil {
+		text = lang.L("workedToday", map[string]any{"Worked": model.FormatDuration(today.Result.Worktime)})
 	}
+	fyne.Do(func() { av.today.Set(text) })
 
-	log.Debug("Is add worktime", "isAdd", isAdd)
-	if wt != nil && err == nil {
-		timeEntry := widget.NewEntry()
-		timeEntry.SetText(time.Now().In(loc).Format(time.TimeOnly))
-
-		typeEntry := widget.NewSelectEntry([]string{lang.L("begin"), lang.L("end")})
-		var textType string
-		if wt.Type == "Begin" {
-			textType = lang.L("begin")
-		} else {
-			textType = lang.L("end")
-		}
-
-		typeEntry.SetText(textType)
-		typeEntry.Disable()
-
-		form := []*widget.FormItem{
-			{Text: lang.L("timeWithFormat"),
-				Widget:   timeEntry,
-				HintText: lang.L("hintTextOldEntry") + ": " + wt.Time.Format(time.TimeOnly)},
-			{Text: lang.L("type"), Widget: typeEntry},
-		}
-		// Edit only if there is an existing item and no error
-		log.Debug("Edit time entry", "worktime", wt)
-		dia := dialog.NewForm(lang.L("editTimeEntry"), lang.L("edit"), lang.L("cancel"), form, func(b bool) {
-			if b {
-				log.Debug("Edit time entry", "confirmed", b, "worktime", wt)
-				// Parse the time
-				timeEntry := form[0].Widget.(*widget.Entry).Text
-
-				if timeEntry == "" {
-					dialog.ShowError(errors.New(lang.L("noTimeEntry")), av.window)
-					return
-				}
-
-				// Add the current date to the updated time entry
-				tempTime := wd.Date.In(loc).Format(time.DateOnly) + " " + timeEntry
-				nt, err := time.ParseInLocation(time.DateTime, tempTime, loc)
-				if err != nil {
-					dialog.ShowError(err, av.window)
-					return
-				}
-				log.Info("New time", "time", nt)
-
-				prevCol := widget.TableCellID{
-					Row: av.selectedItem.Row,
-					Col: av.selectedItem.Col - 1,
-				}
-				if prevCol.Col >= 1 { // If there is a previous entry
-					wd, prevTime, err := av.getTimeEntry(&prevCol)
-					log.Debug("Previous time", "time", "workday", prevTime.Time, wd)
-					if err != nil {
-						dialog.ShowError(err, av.window)
-						return
-					}
-				}
-
-				// The edit shouldn't be in future, it would be faking and does not make sense
-				ct := time.Now().In(loc)
-				log.Debug("Compare new-time with current-time", "new-time", nt, "current-time", ct)
-				if ct.Before(nt) {
-					dialog.ShowError(errors.New(lang.L("timeIsInFuture")), av.window)
-					return
-				}
-
-				// Update the time entry
-				wt.Time = nt
-				timeType := form[1].Widget.(*widget.SelectEntry).Text
-
-				if timeType == lang.L("begin") {
-					wt.Type = "Begin"
-				} else {
-					wt.Type = "End"
-				}
-
-				if isAdd {
-					// If we are adding a time entry in the past, we have to add a new workday
-					_, errAdd := av.repo.AddWorktime(wt)
-					if errAdd != nil {
-						dialog.ShowError(err, av.window)
-					}
-				} else {
-					_, errUpdate := av.repo.UpdateWorktime(wt)
-					if errUpdate != nil {
-						dialog.ShowError(err, av.window)
-					}
-				}
-			}
-			// Refresh *all data*
-			go av.calculateBreak(true)
-		}, av.window)
-
-		dia.Resize(fyne.NewSize(400, 200))
-		dia.Show()
-	} else {
-		dialog.ShowError(errors.New(lang.L("noItemSelected")), av.window)
+	if today.Workday == nil {
+		return
 	}
-}
-
-func (av *AppView) calculateBreakLoop() {
-	for {
-		av.calculateBreak(false)
+	target := today.Result.Target - today.Result.Credit
+	for _, rule := range av.ns.Check(settings, today.Worktimes, target, time.Now()) {
+		log.Info("Sent notification", "rule", rule)
 	}
 }
 
-/*
-Calculates the breaktime for a workday
-and refreshes the data
-*/
-func (av *AppView) calculateBreak(skipWait ...bool) {
-	// Run this all time in the background
-	settings := service.ReadProperties(av.a)
-
-	if len(skipWait) > 0 && !skipWait[0] {
-		// Wait until the time to sleep
-		tts := time.Duration(settings.RefreshTimeUi) * time.Second
-		log.Debug("Wait to calculate breaktime", "time-to-sleep", tts)
-		time.Sleep(tts)
-	}
-	log.Info("Calculate breaktime")
-
-	wd, errWd := av.repo.GetAllWorkday(repo.DESC)
-	if errWd != nil {
-		log.Error(errWd)
-	}
-
-	for _, w := range wd {
-		log.Debug("Workday", "workday", w)
-		wts, errWt := av.repo.GetAllWorktime(w)
-		if errWt != nil {
-			log.Error(errWt)
-		}
-
-		/*
-			breaktime := 0min when time <= 6:00
-			breaktime := 30min when time > 6:00
-			breaktime := 45min when time > 9:00
-		*/
-		var breaktime time.Duration = 0 * time.Minute
-		var worktime time.Duration = 0 * time.Minute
-		var workTimeDiff time.Duration = 0 * time.Minute
-
-		wtsLength := len(wts)
-		tempWtsLength := wtsLength
-		tempWts := wts
-
-		if tempWtsLength%2 == 0 && tempWtsLength >= 4 {
-			for tempWtsLength > 2 && tempWtsLength%2 == 0 {
-				// Calculate the difference between begin and the previous end time
-				lastBegin := tempWts[tempWtsLength-2]
-				lastEnd := tempWts[tempWtsLength-3]
-				workTimeDiff = workTimeDiff + lastBegin.Time.Sub(lastEnd.Time)
-				log.Debug("Worktime difference for even entries", "worktime-diff", workTimeDiff)
-				tempWtsLength -= 2
-				tempWts = tempWts[:tempWtsLength]
-			}
-		}
-
-		for c, wt := range wts {
-			if c+1 < wtsLength {
-				if wt.Type == "Begin" {
-					end := wts[c+1]
-					// Entfernen der Nanosekunden von der Zeit
-					startTime := wt.Time.Truncate(time.Second)
-					endTime := end.Time.Truncate(time.Second)
-					worktime += endTime.Sub(startTime)
-				}
-			}
-		}
-
-		log.Debug("All day worktime", "worktime", worktime)
-
-		if workTimeDiff == 0 {
-			switch {
-			case worktime < 6*time.Hour:
-				breaktime = 0 * time.Minute
-			case worktime >= 6*time.Hour && worktime < 9*time.Hour:
-				breaktime = 30 * time.Minute
-			case worktime >= 9*time.Hour:
-				breaktime = 45 * time.Minute
-			default: // This should never happen
-				breaktime = 30 * time.Minute
-			}
-		} else {
-			breaktime = workTimeDiff
-		}
-
-		// FIXME currently it's saved as string in the database as workaround
-		// Update the workday with the calculated breaktime
-		w.Breaktime = breaktime.Truncate(time.Minute).String()
-		if strings.Contains(w.Breaktime, "m0s") {
-			w.Breaktime = w.Breaktime[:len(w.Breaktime)-2]
-		}
-		if workTimeDiff == 0 {
-			w.Time = (worktime - breaktime).String()
-		} else {
-			w.Time = worktime.String()
-		}
-		log.Debug("Update workday",
-			"worktime", worktime, "breaktime", breaktime)
-
-		_, errUpdate := av.repo.UpdateWorkday(w)
-		if errUpdate != nil {
-			log.Error(errUpdate)
-		}
+// Warns once when a project has used 80% and 100% of its budget
+func (av *AppView) checkBudgets() {
+	ps := service.NewProjectService(av.repo)
+	now := time.Now()
+	budgets, err := ps.Budgets(now, now)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	warnings, err := ps.DueBudgetWarnings(budgets)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	for _, w := range warnings {
+		service.SendBudgetWarning(av.a, w)
+		log.Info("Sent budget warning", "project", w.Budget.Project.Name, "percent", w.Percent)
 	}
-
-	// Refresh *all data*
-	av.refreshAll()
 }
 
 /*
 Deletes a time entry from the database
 */
-func (av *AppView) deleteTimeEntry(wt *db.Worktime) {
+func (av *AppView) deleteTimeEntry(wt *db.Worktime, reason string) {
 	// Deletion here
-	rows, err := av.repo.DeleteWorktime(wt)
-	if rows != 0 || err != nil {
-		// Refresh *all data*
-		av.refreshAll()
+	if _, err := av.repo.DeleteWorktime(wt, reason); err != nil {
+		dialog.ShowError(err, av.window)
 	}
 }
 
+// Asks for confirmation and an optional reason which is kept in the audit log
+func (av *AppView) confirmWithReason(title string, message string, confirmed func(reason string)) {
+	reason := widget.NewEntry()
+	dia := dialog.NewForm(title, lang.L("confirm"), lang.L("cancel"),
+		[]*widget.FormItem{
+			{Widget: widget.NewLabel(message)},
+			{Text: lang.L("reason"), Widget: reason, HintText: lang.L("reasonHint")},
+		},
+		func(b bool) {
+			if !b {
+				log.Debug(title, "canceled", true)
+				return
+			}
+			confirmed(reason.Text)
+		}, av.window)
+	dia.Resize(fyne.NewSize(400, 200))
+	dia.Show()
+}
+
 func (av *AppView) refreshTimetable() {
 	av.buildHeaders()
 	av.timetable.Refresh()
 }
 
-/*
-Combines the refresh of the data and the timetable
-*/
-func (av *AppView) refreshAll() {
-	v, err := av.repo.GetAllVacation()
-	if err != nil {
-		log.Error(err)
-	} else {
-		av.vacations = v
-	}
-	fyne.Do(func() {
-		// Workaround
-		av.RefreshData()
-		av.calculateOvertime()
-		av.RefreshData()
-	})
-}
-
 func (av *AppView) getLongestWorkday() int {
 	var longestDay int = 0
 	var tempDay int = 0
@@ -759,78 +1166,38 @@ func (av *AppView) getLongestWorkday() int {
 	return longestDay
 }
 
-// Slice is a workaround as there is no optional parameters in Go
-func (av *AppView) calculateOvertime(previousOvertime ...time.Duration) {
-	log.Debug("Calculate overtime")
+// Stores the overtimes of the calculated workdays which differ from the stored ones
+func (av *AppView) storeOvertimes(settings *model.Settings, result *service.CalculationResult) error {
+	log.Debug("Store overtimes")
 
-	settings := service.ReadProperties(av.a)
-
-	wd, err := av.repo.GetAllWorkday(repo.ASC)
-	if err != nil {
-		log.Error(err)
-		dialog.ShowError(err, av.window)
-	} else {
-		var totalOvertime time.Duration = 0 * time.Hour
-		var previousOvertimeTransfered time.Duration = 0 * time.Hour
-		var workHoursPerDayDuration time.Duration = getHoursPerDay(settings.WeekHours)
-		var importTotalOvertime time.Duration = time.Duration(settings.ImportOvertime * float64(time.Hour))
-
-		// Fake it till you make it
-		if len(previousOvertime) > 0 {
-			previousOvertimeTransfered = previousOvertime[0]
+	// The calculated days share the loaded workdays
+	var changed []*db.Workday
+	for _, d := range result.Days {
+		if d.Workday.Overtime != d.Overtime {
+			d.Workday.Overtime = d.Overtime
+			changed = append(changed, d.Workday)
 		}
-		log.Debug("Work hour per day", "duration", workHoursPerDayDuration.String())
-
-		for _, w := range wd {
-			currentWorktimeDb, err := time.ParseDuration(w.Time)
-
-			midSumOvertime := (currentWorktimeDb - workHoursPerDayDuration)
-			if err != nil {
-				log.Error(err)
-				dialog.ShowError(err, av.window)
-			} else {
-				w.Overtime = midSumOvertime.String()
-				// Defer DB update to batch after loop
-				totalOvertime += midSumOvertime
-			}
-		}
-		// Batch update all overtimes in one DB call
-		if err := av.repo.UpdateOvertimesBatch(wd); err != nil {
+	}
+	if len(changed) > 0 {
+		if err := av.repo.UpdateOvertimesBatch(changed); err != nil {
 			log.Error("Batch update of overtimes failed", "error", err)
-			dialog.ShowError(err, av.window)
-		}
-
-		// Add previous overtime transfered from last calculation
-		totalOvertime += previousOvertimeTransfered
-
-		log.Debug("Previous overtime transfered", "overtime", previousOvertimeTransfered)
-
-		// Add imported overtime from settings
-		if importTotalOvertime > 0 {
-			totalOvertime += importTotalOvertime
-			log.Info("Import overtime", "overtime", importTotalOvertime)
-		}
-
-		if av.allOvertime == nil {
-			av.allOvertime = binding.NewString()
+			return err
 		}
-
-		av.allOvertime.Set(lang.L("totalOvertime") + ": " + (totalOvertime + previousOvertimeTransfered).String())
-		log.Info("Total overtime", "overtime", totalOvertime+previousOvertimeTransfered)
 	}
-}
-
-// TODO Move logic from editButtonFunc here
-func (av *AppView) editSingleTimeEntry() {
 
+	if settings.ImportOvertime > 0 {
+		log.Info("Import overtime", "overtime", settings.ImportOvertime)
+	}
+	log.Info("Total overtime", "overtime", result.Total, "changed", len(changed))
+	return nil
 }
 
-func (av *AppView) editDateEntry() {
-
+// Creates a calculator with the contracts and absences from the database
+func (av *AppView) newCalculator(settings *model.Settings) *service.Calculator {
+	return av.ts.NewCalculator(settings)
 }
 
-// Calculate hours per day, assuming 5 working days per week
-func getHoursPerDay(weekHours int) time.Duration {
-	weekHoursPerDay := float64(weekHours) / float64(5)
-	return time.Duration(weekHoursPerDay * float64(time.Hour))
+// Creates the holiday calendar of the configured region with the imported holidays
+func (av *AppView) newHolidayCalendar(settings *model.Settings) *service.HolidayCalendar {
+	return av.ts.NewHolidayCalendar(settings)
 }
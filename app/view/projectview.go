@@ -0,0 +1,710 @@
+package view
+
+import (
+	"errors"
+	"image/color"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	"github.com/charmbracelet/log"
+)
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type ProjectView struct {
+	// Business logic
+	projects []*db.Project
+	// Tasks of the selected project
+	tasks []*db.Task
+	repo  *repo.SQLiteRepository
+
+	// UI
+	av              *AppView
+	projectList     *widget.List
+	taskList        *widget.List
+	container       *fyne.Container
+	selectedProject *widget.ListItemID
+	selectedTask    *widget.ListItemID
+}
+
+func CreateProjectView(av *AppView, repo *repo.SQLiteRepository) *ProjectView {
+	pv := &ProjectView{
+		repo: repo,
+		av:   av,
+	}
+
+	pv.projectList = widget.NewList(
+		func() int {
+			return len(pv.projects)
+		},
+		newColorLabel,
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			p := pv.projects[i]
+			updateColorLabel(o, p.Color, formatProject(p.Code, p.Name, p.Active, p.Budget))
+		},
+	)
+	pv.projectList.OnSelected = func(id widget.ListItemID) {
+		pv.selectedProject = &id
+		pv.refreshTasks()
+	}
+
+	pv.taskList = widget.NewList(
+		func() int {
+			return len(pv.tasks)
+		},
+		newColorLabel,
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			t := pv.tasks[i]
+			updateColorLabel(o, t.Color, formatProject(t.Code, t.Name, t.Active, t.Budget))
+		},
+	)
+	pv.taskList.OnSelected = func(id widget.ListItemID) {
+		pv.selectedTask = &id
+	}
+
+	projectToolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() { pv.projectForm(nil) }),
+		widget.NewToolbarAction(theme.ContentRemoveIcon(), pv.deleteProjectForm),
+		widget.NewToolbarAction(theme.DocumentIcon(), pv.editProjectForm),
+	)
+	taskToolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() { pv.taskForm(nil) }),
+		widget.NewToolbarAction(theme.ContentRemoveIcon(), pv.deleteTaskForm),
+		widget.NewToolbarAction(theme.DocumentIcon(), pv.editTaskForm),
+	)
+
+	pv.container = container.NewGridWithColumns(2,
+		container.NewBorder(
+			container.NewVBox(widget.NewLabelWithStyle(lang.L("projects"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), projectToolbar),
+			nil, nil, nil, pv.projectList),
+		container.NewBorder(
+			container.NewVBox(widget.NewLabelWithStyle(lang.L("tasks"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), taskToolbar),
+			nil, nil, nil, pv.taskList),
+	)
+	pv.refresh()
+	return pv
+}
+
+// Shows the projects and their tasks in a dialog
+func (pv *ProjectView) Show() {
+	dia := dialog.NewCustom(lang.L("projects"), lang.L("close"), pv.container, pv.av.window)
+	dia.Resize(fyne.NewSize(700, 400))
+	dia.Show()
+}
+
+func (pv *ProjectView) refresh() {
+	projects, err := pv.repo.GetAllProject()
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, pv.av.window)
+		return
+	}
+	pv.projects = projects
+	pv.projectList.UnselectAll()
+	pv.selectedProject = nil
+	pv.projectList.Refresh()
+	pv.refreshTasks()
+}
+
+func (pv *ProjectView) refreshTasks() {
+	pv.tasks = nil
+	if pv.selectedProject != nil {
+		tasks, err := pv.repo.GetAllTask()
+		if err != nil {
+			log.Error(err)
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		for _, t := range tasks {
+			if t.ProjectID == pv.projects[*pv.selectedProject].ID {
+				pv.tasks = append(pv.tasks, t)
+			}
+		}
+	}
+	pv.taskList.UnselectAll()
+	pv.selectedTask = nil
+	pv.taskList.Refresh()
+}
+
+func (pv *ProjectView) editProjectForm() {
+	if pv.selectedProject == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), pv.av.window)
+		return
+	}
+	pv.projectForm(pv.projects[*pv.selectedProject])
+}
+
+func (pv *ProjectView) deleteProjectForm() {
+	if pv.selectedProject == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), pv.av.window)
+		return
+	}
+	p := pv.projects[*pv.selectedProject]
+
+	dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDeleteProject"), func(b bool) {
+		if !b {
+			return
+		}
+		if _, err := pv.repo.DeleteProject(p); err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		pv.refresh()
+	}, pv.av.window)
+}
+
+// Shows the form to add a project or to edit the given project
+func (pv *ProjectView) projectForm(p *db.Project) {
+	isAdd := p == nil
+	if isAdd {
+		p = &db.Project{Active: true}
+	}
+
+	fields := newProjectFields(p.Name, p.Code, p.Color, p.Active, p.Budget)
+	title, confirm := lang.L("addProject"), lang.L("save")
+	if !isAdd {
+		title, confirm = lang.L("editProject"), lang.L("edit")
+	}
+
+	dia := dialog.NewForm(title, confirm, lang.L("cancel"), fields.formItems(), func(ok bool) {
+		if !ok {
+			return
+		}
+		budget, err := fields.parse()
+		if err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		p.Name, p.Code, p.Color, p.Active, p.Budget = fields.values(budget)
+
+		if isAdd {
+			_, err = pv.repo.AddProject(p)
+		} else {
+			_, err = pv.repo.UpdateProject(p)
+		}
+		if err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		pv.refresh()
+	}, pv.av.window)
+	dia.Resize(fyne.NewSize(400, 300))
+	dia.Show()
+}
+
+func (pv *ProjectView) editTaskForm() {
+	if pv.selectedTask == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), pv.av.window)
+		return
+	}
+	pv.taskForm(pv.tasks[*pv.selectedTask])
+}
+
+func (pv *ProjectView) deleteTaskForm() {
+	if pv.selectedTask == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), pv.av.window)
+		return
+	}
+	t := pv.tasks[*pv.selectedTask]
+
+	dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDeleteTask"), func(b bool) {
+		if !b {
+			return
+		}
+		if _, err := pv.repo.DeleteTask(t); err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		pv.refreshTasks()
+	}, pv.av.window)
+}
+
+// Shows the form to add a task to the selected project or to edit the given task
+func (pv *ProjectView) taskForm(t *db.Task) {
+	isAdd := t == nil
+	if isAdd {
+		if pv.selectedProject == nil {
+			dialog.ShowError(errors.New(lang.L("noProjectSelected")), pv.av.window)
+			return
+		}
+		t = &db.Task{ProjectID: pv.projects[*pv.selectedProject].ID, Active: true}
+	}
+
+	fields := newProjectFields(t.Name, t.Code, t.Color, t.Active, t.Budget)
+	title, confirm := lang.L("addTask"), lang.L("save")
+	if !isAdd {
+		title, confirm = lang.L("editTask"), lang.L("edit")
+	}
+
+	dia := dialog.NewForm(title, confirm, lang.L("cancel"), fields.formItems(), func(ok bool) {
+		if !ok {
+			return
+		}
+		budget, err := fields.parse()
+		if err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		t.Name, t.Code, t.Color, t.Active, t.Budget = fields.values(budget)
+
+		if isAdd {
+			_, err = pv.repo.AddTask(t)
+		} else {
+			_, err = pv.repo.UpdateTask(t)
+		}
+		if err != nil {
+			dialog.ShowError(err, pv.av.window)
+			return
+		}
+		pv.refreshTasks()
+	}, pv.av.window)
+	dia.Resize(fyne.NewSize(400, 300))
+	dia.Show()
+}
+
+// projectFields are the entries of a project or task form
+type projectFields struct {
+	name   *widget.Entry
+	code   *widget.Entry
+	color  *widget.Entry
+	active *widget.Check
+	budget *widget.Entry
+}
+
+func newProjectFields(name string, code string, color string, active bool, budget time.Duration) *projectFields {
+	f := &projectFields{
+		name:   widget.NewEntry(),
+		code:   widget.NewEntry(),
+		color:  widget.NewEntry(),
+		active: widget.NewCheck(lang.L("active"), nil),
+		budget: widget.NewEntry(),
+	}
+	f.name.SetText(name)
+	f.code.SetText(code)
+	f.color.SetPlaceHolder("#4e79a7")
+	f.color.SetText(color)
+	f.active.SetChecked(active)
+	if budget > 0 {
+		f.budget.SetText(strconv.FormatFloat(budget.Hours(), 'f', -1, 64))
+	}
+	return f
+}
+
+func (f *projectFields) formItems() []*widget.FormItem {
+	return []*widget.FormItem{
+		{Text: lang.L("name"), Widget: f.name},
+		{Text: lang.L("code"), Widget: f.code},
+		{Text: lang.L("color"), Widget: f.color},
+		{Text: lang.L("budgetHours"), Widget: f.budget, HintText: lang.L("budgetHoursHint")},
+		{Text: "", Widget: f.active},
+	}
+}
+
+// Validates the entries and returns the budget
+func (f *projectFields) parse() (time.Duration, error) {
+	if strings.TrimSpace(f.name.Text) == "" {
+		return 0, errors.New(lang.L("nameRequired"))
+	}
+	if f.color.Text != "" && !hexColor.MatchString(f.color.Text) {
+		return 0, errors.New(lang.L("invalidColor"))
+	}
+	if strings.TrimSpace(f.budget.Text) == "" {
+		return 0, nil
+	}
+	hours, err := strconv.ParseFloat(strings.ReplaceAll(f.budget.Text, ",", "."), 64)
+	if err != nil {
+		return 0, err
+	}
+	if hours < 0 {
+		return 0, errors.New(lang.L("invalidBudget"))
+	}
+	return model.HoursToDuration(hours), nil
+}
+
+func (f *projectFields) values(budget time.Duration) (string, string, string, bool, time.Duration) {
+	return strings.TrimSpace(f.name.Text), strings.TrimSpace(f.code.Text), f.color.Text, f.active.Checked, budget
+}
+
+// Returns e.g. WEB Website (40h), inactive projects are marked
+func formatProject(code string, name string, active bool, budget time.Duration) string {
+	text := name
+	if code != "" {
+		text = code + " " + name
+	}
+	if budget > 0 {
+		text += " (" + model.FormatDuration(budget) + ")"
+	}
+	if !active {
+		text += " – " + lang.L("inactive")
+	}
+	return text
+}
+
+// A label with a color swatch in front
+func newColorLabel() fyne.CanvasObject {
+	swatch := canvas.NewRectangle(color.Transparent)
+	swatch.SetMinSize(fyne.NewSize(theme.IconInlineSize(), theme.IconInlineSize()))
+	swatch.CornerRadius = theme.InputRadiusSize()
+	return container.NewBorder(nil, nil, container.NewCenter(swatch), nil, widget.NewLabel("Project"))
+}
+
+func updateColorLabel(o fyne.CanvasObject, hex string, text string) {
+	c := o.(*fyne.Container)
+	for _, obj := range c.Objects {
+		switch v := obj.(type) {
+		case *widget.Label:
+			v.SetText(text)
+		case *fyne.Container:
+			swatch := v.Objects[0].(*canvas.Rectangle)
+			swatch.FillColor = parseHexColor(hex)
+			swatch.Refresh()
+		}
+	}
+}
+
+// Parses a color like #4e79a7, invalid colors are transparent
+func parseHexColor(hex string) color.Color {
+	if !hexColor.MatchString(hex) {
+		return color.Transparent
+	}
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// projectChoice is an entry of the current project selection
+type projectChoice struct {
+	label     string
+	projectID int64
+	taskID    int64
+}
+
+// Returns no project followed by the active projects and their active tasks
+func (av *AppView) projectChoices() []projectChoice {
+	choices := []projectChoice{{label: lang.L("noProject")}}
+	for _, p := range av.projects {
+		if !p.Active {
+			continue
+		}
+		choices = append(choices, projectChoice{label: av.projectLabel(p.ID, 0), projectID: p.ID})
+		for _, t := range av.tasks {
+			if t.ProjectID == p.ID && t.Active {
+				choices = append(choices, projectChoice{label: av.projectLabel(p.ID, t.ID), projectID: p.ID, taskID: t.ID})
+			}
+		}
+	}
+	return choices
+}
+
+// Returns e.g. WEB Website / Design or an empty string without project
+func (av *AppView) projectLabel(projectID int64, taskID int64) string {
+	p, ok := av.projectByID[projectID]
+	if !ok {
+		return ""
+	}
+	label := p.Name
+	if p.Code != "" {
+		label = p.Code + " " + p.Name
+	}
+	if t, ok := av.taskByID[taskID]; ok {
+		label += " / " + t.Name
+	}
+	return label
+}
+
+// Returns the code of the project or the name if it has no code
+func (av *AppView) projectCode(projectID int64) string {
+	p, ok := av.projectByID[projectID]
+	if !ok {
+		return ""
+	}
+	if p.Code != "" {
+		return p.Code
+	}
+	return p.Name
+}
+
+// Loads the projects and tasks and updates the current project selection
+func (av *AppView) refreshProjects() {
+	projects, tasks, err := service.NewProjectService(av.repo).Lookup()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	av.showProjects(projects, tasks)
+}
+
+// Shows the projects and tasks in the current project selection and the tray
+func (av *AppView) showProjects(projects map[int64]*db.Project, tasks map[int64]*db.Task) {
+	av.projectByID, av.taskByID = projects, tasks
+
+	av.projects = av.projects[:0]
+	for _, p := range projects {
+		av.projects = append(av.projects, p)
+	}
+	sort.Slice(av.projects, func(i, j int) bool {
+		return strings.ToLower(av.projects[i].Name) < strings.ToLower(av.projects[j].Name)
+	})
+	av.tasks = av.tasks[:0]
+	for _, t := range tasks {
+		av.tasks = append(av.tasks, t)
+	}
+	sort.Slice(av.tasks, func(i, j int) bool {
+		return strings.ToLower(av.tasks[i].Name) < strings.ToLower(av.tasks[j].Name)
+	})
+
+	if av.projectSelect != nil {
+		settings := service.ReadProperties(av.a)
+		choices := av.projectChoices()
+		options := make([]string, len(choices))
+		for i, c := range choices {
+			options[i] = c.label
+		}
+
+		// The selection is only changed by the user
+		onChanged := av.projectSelect.OnChanged
+		av.projectSelect.OnChanged = nil
+		av.projectSelect.SetOptions(options)
+		av.projectSelect.SetSelectedIndex(0)
+		for i, c := range choices {
+			if c.projectID == settings.CurrentProject && c.taskID == settings.CurrentTask {
+				av.projectSelect.SetSelectedIndex(i)
+			}
+		}
+		av.projectSelect.OnChanged = onChanged
+	}
+
+	if av.OnProjectsLoaded != nil {
+		av.OnProjectsLoaded()
+	}
+}
+
+// Creates the selection of the project the time is recorded for
+func (av *AppView) newProjectSelect() *widget.Select {
+	sel := widget.NewSelect(nil, nil)
+	sel.PlaceHolder = lang.L("noProject")
+	sel.OnChanged = func(string) {
+		choices := av.projectChoices()
+		i := sel.SelectedIndex()
+		if i < 0 || i >= len(choices) {
+			return
+		}
+		choice := choices[i]
+		av.SwitchProject(choice.projectID, choice.taskID)
+	}
+	return sel
+}
+
+/*
+Sets the project the time is recorded for. While clocked in the
+running segment is closed and a new one is started for the project.
+*/
+func (av *AppView) SwitchProject(projectID int64, taskID int64) {
+	settings := service.ReadProperties(av.a)
+	if settings.CurrentProject == projectID && settings.CurrentTask == taskID {
+		return
+	}
+	settings.CurrentProject, settings.CurrentTask = projectID, taskID
+	service.WriteProperties(av.a, settings)
+	log.Info("Switch project", "project", projectID, "task", taskID)
+
+	now := time.Now()
+	status, err := av.ts.Status(settings, now)
+	if err != nil {
+		log.Error(err)
+		dialog.ShowError(err, av.window)
+		return
+	}
+	if status.Open != nil {
+		if _, err := av.ts.SwitchProject(now, projectID, taskID); err != nil {
+			log.Error(err)
+			dialog.ShowError(err, av.window)
+			return
+		}
+	}
+	// Keeps the selection and the tray menu in sync
+	av.refreshProjects()
+}
+
+// Returns the menu item to switch the current project, e.g. for the tray
+func (av *AppView) ProjectMenuItem() *fyne.MenuItem {
+	settings := service.ReadProperties(av.a)
+
+	var items []*fyne.MenuItem
+	for _, c := range av.projectChoices() {
+		item := fyne.NewMenuItem(c.label, func() {
+			av.SwitchProject(c.projectID, c.taskID)
+		})
+		item.Checked = c.projectID == settings.CurrentProject && c.taskID == settings.CurrentTask
+		items = append(items, item)
+	}
+
+	item := fyne.NewMenuItem(lang.L("currentProject"), nil)
+	item.ChildMenu = fyne.NewMenu("", items...)
+	return item
+}
+
+/*
+Attributes the segment of the selected time entry to a project and task.
+The project, task and note are stored on the Begin entry of the segment.
+*/
+func (av *AppView) AssignSelectedSegment() {
+	wd, wt, err := av.getTimeEntry(av.selectedItem)
+	if err != nil {
+		dialog.ShowError(err, av.window)
+		return
+	}
+
+	var wtday []*db.Worktime
+	for _, w := range av.worktime {
+		if w.Workday.ID == wd.ID {
+			wtday = append(wtday, w)
+		}
+	}
+	begin := wt
+	if wt.Type != "Begin" {
+		begin = nil
+		for i, w := range wtday {
+			if w.ID == wt.ID && i > 0 {
+				begin = wtday[i-1]
+			}
+		}
+	}
+	if begin == nil || begin.Type != "Begin" {
+		dialog.ShowError(errors.New(lang.L("noSegmentFound")), av.window)
+		return
+	}
+
+	choices := av.projectChoices()
+	options := make([]string, len(choices))
+	for i, c := range choices {
+		options[i] = c.label
+	}
+	project := widget.NewSelect(options, nil)
+	project.SetSelectedIndex(0)
+	for i, c := range choices {
+		if c.projectID == begin.ProjectID && c.taskID == begin.TaskID {
+			project.SetSelectedIndex(i)
+		}
+	}
+	// Inactive projects are kept as they are
+	if project.SelectedIndex() == 0 && begin.ProjectID != 0 {
+		project.PlaceHolder = av.projectLabel(begin.ProjectID, begin.TaskID)
+		project.ClearSelected()
+	}
+	note := widget.NewMultiLineEntry()
+	note.SetText(begin.Note)
+
+	segment := begin.Time.Format(time.TimeOnly)
+	dia := dialog.NewForm(lang.L("assignProject"), lang.L("save"), lang.L("cancel"),
+		[]*widget.FormItem{
+			widget.NewFormItem(lang.L("segment"), widget.NewLabel(wd.Date.Format(model.DATEFORMAT)+" "+segment)),
+			widget.NewFormItem(lang.L("project"), project),
+			widget.NewFormItem(lang.L("note"), note),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if i := project.SelectedIndex(); i >= 0 {
+				begin.ProjectID, begin.TaskID = choices[i].projectID, choices[i].taskID
+			}
+			begin.Note = strings.TrimSpace(note.Text)
+			if _, err := av.repo.UpdateWorktime(begin, ""); err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+			}
+		}, av.window)
+	dia.Resize(fyne.NewSize(400, 300))
+	dia.Show()
+}
+
+// Asks for the date range and shows the recorded time per project and task
+func ShowProjectReport(av *AppView) {
+	now := time.Now()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	from := newDateEntry(av.window)
+	from.SetText(firstOfMonth.Format(model.DATEFORMAT))
+	to := newDateEntry(av.window)
+	to.SetText(firstOfMonth.AddDate(0, 1, -1).Format(model.DATEFORMAT))
+
+	dialog.ShowForm(lang.L("projectReport"), lang.L("apply"), lang.L("cancel"),
+		[]*widget.FormItem{
+			widget.NewFormItem(lang.L("startDate"), from),
+			widget.NewFormItem(lang.L("endDate"), to),
+		},
+		func(b bool) {
+			if !b {
+				return
+			}
+
+			start, err := time.Parse(model.DATEFORMAT, from.Text)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			end, err := time.Parse(model.DATEFORMAT, to.Text)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			if end.Before(start) {
+				dialog.ShowError(repo.ErrInvalidRange, av.window)
+				return
+			}
+
+			report, err := service.NewProjectService(av.repo).Report(start, end)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			showProjectReport(av, report)
+		}, av.window)
+}
+
+func showProjectReport(av *AppView, report *service.ProjectReport) {
+	bold := fyne.TextStyle{Bold: true}
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle(lang.L("project"), fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle(lang.L("task"), fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle(lang.L("hours"), fyne.TextAlignTrailing, bold),
+	)
+	for _, row := range report.Rows {
+		project, task := lang.L("noProject"), ""
+		if row.Project != nil {
+			project = formatProject(row.Project.Code, row.Project.Name, true, 0)
+		}
+		if row.Task != nil {
+			task = row.Task.Name
+		}
+		grid.Add(widget.NewLabel(project))
+		grid.Add(widget.NewLabel(task))
+		grid.Add(widget.NewLabelWithStyle(model.FormatDuration(row.Time), fyne.TextAlignTrailing, fyne.TextStyle{}))
+	}
+	grid.Add(widget.NewLabelWithStyle(lang.L("total"), fyne.TextAlignLeading, bold))
+	grid.Add(widget.NewLabel(""))
+	grid.Add(widget.NewLabelWithStyle(model.FormatDuration(report.Total), fyne.TextAlignTrailing, bold))
+
+	title := lang.L("projectReport") + " " + report.From.Format(model.DATEFORMAT) +
+		" – " + report.To.Format(model.DATEFORMAT)
+	dia := dialog.NewCustom(title, lang.L("close"), container.NewVScroll(grid), av.window)
+	dia.Resize(fyne.NewSize(600, 400))
+	dia.Show()
+}
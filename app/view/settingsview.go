@@ -4,8 +4,11 @@ import (
 	"errors"
 
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/lang"
@@ -18,7 +21,8 @@ import (
 	apptheme "github.com/FyningTime/FyningTime/app/theme"
 )
 
-func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
+// Shows the settings, onSave is called after the settings were saved
+func GetSettingsView(w fyne.Window, a fyne.App, onSave func()) *dialog.FormDialog {
 	settings := service.ReadProperties(a)
 
 	firstDayOfWeekEntry := widget.NewSelectEntry(
@@ -37,6 +41,8 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 	weekHours := widget.NewEntry()
 	weekHours.SetText(strconv.Itoa(settings.WeekHours))
 
+	targetHoursForm, targetHours := newTargetHoursForm(w, weekHours, settings.TargetHours)
+
 	lockImportOvertime := widget.NewCheck(lang.L("lockImportOvertime"), nil)
 	lockImportOvertime.SetChecked(settings.LockImportOvertime)
 
@@ -49,10 +55,84 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 	maxVacations := widget.NewEntry()
 	maxVacations.SetText(strconv.Itoa(settings.MaxVacationDays))
 
+	carryOverCutoff := widget.NewEntry()
+	carryOverCutoff.SetPlaceHolder("31.03")
+	carryOverCutoff.SetText(settings.CarryOverCutoff)
+
+	employeeName := widget.NewEntry()
+	employeeName.SetText(settings.EmployeeName)
+
 	refreshTimeUi := widget.NewEntry()
 
 	refreshTimeUi.SetText(strconv.Itoa(settings.RefreshTimeUi))
 
+	breakRules := widget.NewMultiLineEntry()
+	breakRules.SetMinRowsVisible(3)
+	breakRules.SetText(model.FormatBreakRules(settings.BreakRules))
+
+	breakPolicyOptions := []string{}
+	for _, p := range model.BreakPolicies {
+		breakPolicyOptions = append(breakPolicyOptions, model.BreakPolicyToString(p))
+	}
+	breakPolicy := widget.NewSelect(breakPolicyOptions, nil)
+	breakPolicy.SetSelected(model.BreakPolicyToString(settings.BreakPolicy))
+	breakPolicy.OnChanged = func(selected string) {
+		// Fill in the preset, custom rules are kept for editing
+		if p := model.StringToBreakPolicy(selected); p != model.BreakPolicyCustom {
+			breakRules.SetText(model.FormatBreakRules(model.BreakRulesForPolicy(p)))
+		}
+	}
+	breakRules.OnChanged = func(text string) {
+		// Editing a preset turns it into custom rules
+		p := model.StringToBreakPolicy(breakPolicy.Selected)
+		if p != model.BreakPolicyCustom && text != model.FormatBreakRules(model.BreakRulesForPolicy(p)) {
+			breakPolicy.SetSelected(model.BreakPolicyToString(model.BreakPolicyCustom))
+		}
+	}
+
+	holidayRegionOptions := []string{}
+	for _, r := range service.HolidayRegions {
+		if r == "" {
+			holidayRegionOptions = append(holidayRegionOptions, lang.L("noHolidays"))
+		} else {
+			holidayRegionOptions = append(holidayRegionOptions, service.HolidayRegionName(r))
+		}
+	}
+	holidayRegion := widget.NewSelect(holidayRegionOptions, nil)
+	holidayRegion.SetSelectedIndex(0)
+	for i, r := range service.HolidayRegions {
+		if r == settings.HolidayRegion {
+			holidayRegion.SetSelectedIndex(i)
+		}
+	}
+
+	notifyTargetReached := widget.NewCheck(lang.L("targetReachedNotification"), nil)
+	notifyTargetReached.SetChecked(settings.NotifyTargetReached)
+	notifyNoBreak := widget.NewCheck(lang.L("noBreakNotification"), nil)
+	notifyNoBreak.SetChecked(settings.NotifyNoBreak)
+	notifyLongDay := widget.NewCheck(lang.L("longDayNotification"), nil)
+	notifyLongDay.SetChecked(settings.NotifyLongDay)
+	notifyClockOut := widget.NewCheck(lang.L("clockOutNotification"), nil)
+	notifyClockOut.SetChecked(settings.NotifyClockOut)
+	notifyBudget := widget.NewCheck(lang.L("budgetNotification"), nil)
+	notifyBudget.SetChecked(settings.NotifyBudget)
+	notifications := container.NewVBox(notifyTargetReached, notifyNoBreak, notifyLongDay, notifyClockOut, notifyBudget)
+
+	longDayHours := widget.NewEntry()
+	longDayHours.SetText(strconv.FormatFloat(settings.LongDayHours, 'f', -1, 64))
+	clockOutHour := widget.NewEntry()
+	clockOutHour.SetText(strconv.Itoa(settings.ClockOutHour))
+
+	autoClock := widget.NewCheck(lang.L("autoClock"), nil)
+	autoClock.SetChecked(settings.AutoClock)
+	autostart := widget.NewCheck(lang.L("autostart"), nil)
+	autostart.SetChecked(settings.Autostart)
+
+	idleDetection := widget.NewCheck(lang.L("idleDetection"), nil)
+	idleDetection.SetChecked(settings.IdleDetection)
+	idleMinutes := widget.NewEntry()
+	idleMinutes.SetText(strconv.Itoa(settings.IdleMinutes))
+
 	themeOptions := []string{lang.L("auto"), lang.L("light"), lang.L("dark")}
 	themeSelection := widget.NewRadioGroup(themeOptions, nil)
 	switch settings.ThemeVariant {
@@ -66,10 +146,21 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 
 	form := []*widget.FormItem{
 		{Text: lang.L("dbPath"), Widget: widget.NewLabel(settings.SavedDbPath)},
+		{Text: lang.L("employeeName"), Widget: employeeName, HintText: lang.L("employeeNameHint")},
 		{Text: lang.L("refreshTimesInSeconds"), Widget: refreshTimeUi},
+		{Text: lang.L("session"), Widget: container.NewVBox(autoClock, autostart), HintText: lang.L("autoClockHint")},
+		{Text: lang.L("idleMinutes"), Widget: container.NewVBox(idleDetection, idleMinutes), HintText: lang.L("idleMinutesHint")},
 		{Text: lang.L("firstDayOfWeek"), Widget: firstDayOfWeekEntry},
 		{Text: lang.L("weekHours"), Widget: weekHours},
+		{Text: lang.L("targetHours"), Widget: targetHoursForm, HintText: lang.L("targetHoursHint")},
+		{Text: lang.L("breakPolicy"), Widget: breakPolicy},
+		{Text: lang.L("breakRules"), Widget: breakRules, HintText: lang.L("breakRulesHint")},
 		{Text: lang.L("maxVacations"), Widget: maxVacations},
+		{Text: lang.L("carryOverCutoff"), Widget: carryOverCutoff, HintText: lang.L("carryOverCutoffHint")},
+		{Text: lang.L("holidayRegion"), Widget: holidayRegion, HintText: lang.L("holidayRegionHint")},
+		{Text: lang.L("notifications"), Widget: notifications},
+		{Text: lang.L("longDayHours"), Widget: longDayHours, HintText: lang.L("longDayHoursHint")},
+		{Text: lang.L("clockOutHour"), Widget: clockOutHour, HintText: lang.L("clockOutHourHint")},
 		{Text: lang.L("importTotalOvertime"), Widget: importTotalOvertime},
 		{Text: lang.L("theme"), Widget: themeSelection},
 		{Text: lang.L("lockImportOvertime"), Widget: lockImportOvertime},
@@ -86,6 +177,15 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 			}
 			settings.MaxVacationDays = intMaxVacations
 
+			if carryOverCutoff.Text != "" {
+				if _, err := time.Parse(model.CARRYOVERFORMAT, carryOverCutoff.Text); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+			}
+			settings.CarryOverCutoff = carryOverCutoff.Text
+			settings.EmployeeName = strings.TrimSpace(employeeName.Text)
+
 			intWeekHours, err := strconv.Atoi(weekHours.Text)
 			if err != nil {
 				dialog.ShowError(err, w)
@@ -97,6 +197,47 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 			}
 			settings.WeekHours = intWeekHours
 
+			settings.TargetHours, err = parseTargetHours(targetHours)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+
+			rules, err := model.ParseBreakRules(breakRules.Text)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			settings.HolidayRegion = service.HolidayRegions[holidayRegion.SelectedIndex()]
+
+			settings.BreakPolicy = model.StringToBreakPolicy(breakPolicy.Selected)
+			settings.BreakRules = rules
+
+			settings.NotifyTargetReached = notifyTargetReached.Checked
+			settings.NotifyNoBreak = notifyNoBreak.Checked
+			settings.NotifyLongDay = notifyLongDay.Checked
+			settings.NotifyClockOut = notifyClockOut.Checked
+			settings.NotifyBudget = notifyBudget.Checked
+
+			settings.LongDayHours, err = strconv.ParseFloat(longDayHours.Text, 64)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if settings.LongDayHours <= 0 || settings.LongDayHours > 24 {
+				dialog.ShowError(errors.New("Long day hours must be between 0 and 24"), w)
+				return
+			}
+			settings.ClockOutHour, err = strconv.Atoi(clockOutHour.Text)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if settings.ClockOutHour < 0 || settings.ClockOutHour > 23 {
+				dialog.ShowError(errors.New("Clock out hour must be between 0 and 23"), w)
+				return
+			}
+
 			intImportOvertime, err := strconv.ParseFloat(importTotalOvertime.Text, 64)
 			if err != nil {
 				dialog.ShowError(err, w)
@@ -117,6 +258,26 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 
 			}
 
+			settings.AutoClock = autoClock.Checked
+
+			settings.IdleDetection = idleDetection.Checked
+			settings.IdleMinutes, err = strconv.Atoi(idleMinutes.Text)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if settings.IdleMinutes < 1 {
+				dialog.ShowError(errors.New("Idle minutes must be at least 1"), w)
+				return
+			}
+			if autostart.Checked != settings.Autostart {
+				if err := service.SetAutostart(autostart.Checked); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				settings.Autostart = autostart.Checked
+			}
+
 			switch themeSelection.Selected {
 			case lang.L("dark"):
 				settings.ThemeVariant = 1
@@ -129,6 +290,7 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 				a.Settings().SetTheme(apptheme.NewPastelleTheme())
 			}
 			service.WriteProperties(a, settings)
+			onSave()
 
 		} else {
 			// Canceled
@@ -136,3 +298,50 @@ func GetSettingsView(w fyne.Window, a fyne.App) *dialog.FormDialog {
 	}, w)
 	return dia
 }
+
+/*
+Creates one entry per weekday to edit the target hours and a button
+which distributes the hours of the week hours entry over Monday to Friday
+*/
+func newTargetHoursForm(w fyne.Window, weekHours *widget.Entry, hours map[model.Weekday]float64) (
+	*fyne.Container, map[model.Weekday]*widget.Entry) {
+	entries := make(map[model.Weekday]*widget.Entry, len(model.Weekdays))
+	grid := container.NewGridWithColumns(len(model.Weekdays))
+	for _, wd := range model.Weekdays {
+		entry := widget.NewEntry()
+		entry.SetText(strconv.FormatFloat(hours[wd], 'f', -1, 64))
+		entries[wd] = entry
+
+		label := widget.NewLabel(model.ShortenWeekday(string(wd)))
+		label.Alignment = fyne.TextAlignCenter
+		grid.Add(container.NewVBox(label, entry))
+	}
+
+	distribute := widget.NewButton(lang.L("distributeWeekHours"), func() {
+		floatWeekHours, err := strconv.ParseFloat(weekHours.Text, 64)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		for wd, h := range model.DefaultTargetHours(floatWeekHours) {
+			entries[wd].SetText(strconv.FormatFloat(h, 'f', -1, 64))
+		}
+	})
+
+	return container.NewVBox(grid, distribute), entries
+}
+
+func parseTargetHours(entries map[model.Weekday]*widget.Entry) (map[model.Weekday]float64, error) {
+	hours := make(map[model.Weekday]float64, len(entries))
+	for _, wd := range model.Weekdays {
+		h, err := strconv.ParseFloat(entries[wd].Text, 64)
+		if err != nil {
+			return nil, err
+		}
+		if h < 0 || h > 24 {
+			return nil, errors.New("Target hours must be between 0 and 24")
+		}
+		hours[wd] = h
+	}
+	return hours, nil
+}
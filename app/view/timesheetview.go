@@ -0,0 +1,131 @@
+package view
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FyningTime/FyningTime/app/model/db"
+	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
+	"github.com/charmbracelet/log"
+)
+
+// Asks for the month and saves its timesheet as PDF
+func ShowTimesheetView(av *AppView) {
+	now := time.Now()
+
+	months := make([]string, 12)
+	for i := range months {
+		months[i] = monthName(time.Month(i + 1))
+	}
+	month := widget.NewSelect(months, nil)
+	month.SetSelectedIndex(int(now.Month()) - 1)
+
+	years := []string{}
+	for y := now.Year(); y >= now.Year()-5; y-- {
+		years = append(years, strconv.Itoa(y))
+	}
+	year := widget.NewSelect(years, nil)
+	year.SetSelectedIndex(0)
+	history := widget.NewCheck(lang.L("includeCorrections"), nil)
+
+	dialog.ShowForm(lang.L("timesheet"), lang.L("export"), lang.L("cancel"),
+		[]*widget.FormItem{
+			widget.NewFormItem(lang.L("month"), month),
+			widget.NewFormItem(lang.L("year"), year),
+			widget.NewFormItem("", history),
+		},
+		func(b bool) {
+			if !b {
+				return
+			}
+
+			y, _ := strconv.Atoi(year.Selected)
+			m := time.Month(month.SelectedIndex() + 1)
+			tss := service.NewTimesheetService(av.repo)
+			sheet, err := tss.Timesheet(service.ReadProperties(av.a), y, m)
+			if err != nil {
+				log.Error(err)
+				dialog.ShowError(err, av.window)
+				return
+			}
+			if history.Checked {
+				last := sheet.Month.AddDate(0, 1, -1)
+				if sheet.Corrections, err = service.NewAuditService(av.repo).Corrections(sheet.Month, last); err != nil {
+					log.Error(err)
+					dialog.ShowError(err, av.window)
+					return
+				}
+			}
+			saveTimesheet(av, sheet)
+		}, av.window)
+}
+
+func saveTimesheet(av *AppView, sheet *service.Timesheet) {
+	fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, av.window)
+			return
+		}
+		if writer == nil {
+			// Canceled
+			return
+		}
+		defer writer.Close()
+
+		if err := service.WriteTimesheetPDF(writer, sheet, timesheetLabels(sheet)); err != nil {
+			log.Error(err)
+			dialog.ShowError(err, av.window)
+		}
+	}, av.window)
+	fd.SetFileName("timesheet-" + sheet.Month.Format("2006-01") + ".pdf")
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".pdf"}))
+	fd.Show()
+}
+
+func timesheetLabels(sheet *service.Timesheet) service.TimesheetLabels {
+	labels := service.TimesheetLabels{
+		Title: lang.L("timesheetTitle", map[string]any{
+			"Month": monthName(sheet.Month.Month()),
+			"Year":  sheet.Month.Year(),
+		}),
+		Employee:  lang.L("employeeName"),
+		Date:      lang.L("date"),
+		Times:     lang.L("time"),
+		Break:     lang.L("break"),
+		Worked:    lang.L("worked"),
+		Target:    lang.L("target"),
+		Overtime:  lang.L("overtime"),
+		Balance:   lang.L("balance"),
+		Notes:     lang.L("notes"),
+		Total:     lang.L("total"),
+		Signature: lang.L("signatureEmployee"),
+		Approval:  lang.L("signatureSupervisor"),
+		Absence:   fwidget.AbsenceName,
+
+		Corrections: lang.L("corrections"),
+		ChangedAt:   lang.L("changedAt"),
+		Change:      lang.L("change"),
+		Reason:      lang.L("reason"),
+		Undone:      lang.L("undone"),
+		Action: func(a db.AuditAction) string {
+			return lang.L(string(a) + "Action")
+		},
+	}
+	for i, day := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+		name := []rune(lang.L(day))
+		labels.Weekdays[i] = string(name[:min(2, len(name))])
+	}
+	return labels
+}
+
+// Returns the translated name of a month, the keys are the lower case english names
+func monthName(m time.Month) string {
+	return lang.L(strings.ToLower(m.String()))
+}
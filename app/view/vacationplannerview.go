@@ -1,172 +1,313 @@
 package view
 
 import (
+	"errors"
+	"slices"
+	"strconv"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/FyningTime/FyningTime/app/model"
 	"github.com/FyningTime/FyningTime/app/model/db"
 	"github.com/FyningTime/FyningTime/app/repo"
+	"github.com/FyningTime/FyningTime/app/service"
+	fwidget "github.com/FyningTime/FyningTime/app/widget"
 	"github.com/charmbracelet/log"
 	datepicker "github.com/sdassow/fyne-datepicker"
 )
 
 type VacationPlannerView struct {
 	// Business logic
-	vacations []*db.Vacation
-	repo      *repo.SQLiteRepository
+	absences []*db.Absence
+	repo     *repo.SQLiteRepository
+	// Days per absence id, without weekends and holidays
+	days map[int64]float64
 
 	// UI
-	av               *AppView
-	container        *fyne.Container
-	selectedVacation widget.ListItemID
+	av              *AppView
+	container       *fyne.Container
+	list            *widget.List
+	budget          *widget.Label
+	selectedAbsence *widget.ListItemID
 }
 
 func NewVacationPlannerView(
 	av *AppView,
 	repo *repo.SQLiteRepository,
-	vacations []*db.Vacation,
+	absences []*db.Absence,
 ) *VacationPlannerView {
 	return &VacationPlannerView{
-		vacations: vacations,
-		repo:      repo,
-		av:        av,
+		absences: absences,
+		repo:     repo,
+		av:       av,
 	}
 }
 
-func CreateVacationPlannerView(av *AppView, repo *repo.SQLiteRepository, vacations []*db.Vacation) *VacationPlannerView {
-	vpv := NewVacationPlannerView(av, repo, vacations)
+func CreateVacationPlannerView(av *AppView, repo *repo.SQLiteRepository, absences []*db.Absence) *VacationPlannerView {
+	vpv := NewVacationPlannerView(av, repo, absences)
 	log.Debug("CreateVacationPlannerView", "VacationPlannerView", vpv)
 
 	vl := widget.NewList(
 		func() int {
-			return len(vpv.vacations)
+			return len(vpv.absences)
 		},
 		func() fyne.CanvasObject {
 			return widget.NewLabel("Vacations")
 		},
 		func(i widget.ListItemID, o fyne.CanvasObject) {
-			o.(*widget.Label).SetText(
-				vpv.vacations[i].StartDate.Format(model.DATEFORMAT) + " - " + vpv.vacations[i].EndDate.Format(model.DATEFORMAT),
-			)
+			a := vpv.absences[i]
+			text := fwidget.AbsenceLabel(a.Type) + " " +
+				a.StartDate.Format(model.DATEFORMAT) + " - " + a.EndDate.Format(model.DATEFORMAT)
+			if a.HalfDayStart || a.HalfDayEnd {
+				text += " (" + lang.L("halfDay") + ")"
+			}
+			if days, ok := vpv.days[a.ID]; ok {
+				text += ": " + formatDays(days) + " " + lang.L("days")
+			}
+			o.(*widget.Label).SetText(text)
 		},
 	)
 
 	vl.OnSelected = func(id widget.ListItemID) {
-		vpv.selectedVacation = id
+		vpv.selectedAbsence = &id
 	}
+	vpv.list = vl
 
 	header := widget.NewLabel("Vacations Planner")
 	header.Alignment = fyne.TextAlignCenter
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
-	btnAddVacationToolbarItem := widget.NewToolbarAction(theme.ContentAddIcon(), vpv.addVacationForm)
-	btnDeleteTimeToolbarItem := widget.NewToolbarAction(theme.ContentRemoveIcon(), vpv.deleteVacationForm)
-	btnEditTimeToolbarItem := widget.NewToolbarAction(theme.DocumentIcon(), vpv.editVacationForm)
+	btnAddAbsenceToolbarItem := widget.NewToolbarAction(theme.ContentAddIcon(), vpv.addAbsenceForm)
+	btnDeleteTimeToolbarItem := widget.NewToolbarAction(theme.ContentRemoveIcon(), vpv.deleteAbsenceForm)
+	btnEditTimeToolbarItem := widget.NewToolbarAction(theme.DocumentIcon(), vpv.editAbsenceForm)
 
 	toolbar := widget.NewToolbar(
-		btnAddVacationToolbarItem,
+		btnAddAbsenceToolbarItem,
 		btnDeleteTimeToolbarItem,
 		btnEditTimeToolbarItem,
 	)
 
-	c := container.NewBorder(toolbar, nil, nil, nil, vl)
+	vpv.budget = widget.NewLabel("")
+	vpv.budget.Wrapping = fyne.TextWrapWord
+
+	c := container.NewBorder(toolbar, vpv.budget, nil, nil, vl)
 	vpv.container = c
 	return vpv
 }
 
-func (vpv *VacationPlannerView) addVacationForm() {
-	startDate := widget.NewEntry()
-	startDate.SetPlaceHolder("01.01.1970")
-	startDate.ActionItem = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
-		when := time.Now()
+func (vpv *VacationPlannerView) addAbsenceForm() {
+	vpv.absenceForm(nil)
+}
 
-		picker := datepicker.NewDatePicker(when, time.Monday, func(when time.Time, ok bool) {
-			if ok {
-				startDate.SetText(when.Format(model.DATEFORMAT))
-			}
-		})
+func (vpv *VacationPlannerView) deleteAbsenceForm() {
+	if vpv.selectedAbsence == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), vpv.av.window)
+		return
+	}
+	v := vpv.absences[*vpv.selectedAbsence]
 
-		dialog.ShowCustomConfirm(
-			"Select a date",
-			"OK",
-			"Cancel",
-			picker,
-			picker.OnActioned,
-			vpv.av.window,
-		)
-	})
+	dialog.ShowConfirm(lang.L("deleteEntry"), lang.L("areYouSureDeleteAbsence"), func(b bool) {
+		if !b {
+			return
+		}
+		if _, err := vpv.repo.DeleteAbsence(v); err != nil {
+			log.Error(err)
+			dialog.ShowError(err, vpv.av.window)
+		}
+	}, vpv.av.window)
+}
 
-	endDate := widget.NewEntry()
-	endDate.SetPlaceHolder("01.01.1970")
-	endDate.ActionItem = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
-		when := time.Now()
+func (vpv *VacationPlannerView) editAbsenceForm() {
+	if vpv.selectedAbsence == nil {
+		dialog.ShowError(errors.New(lang.L("noItemSelected")), vpv.av.window)
+		return
+	}
+	vpv.absenceForm(vpv.absences[*vpv.selectedAbsence])
+}
 
-		picker := datepicker.NewDatePicker(when, time.Monday, func(when time.Time, ok bool) {
-			if ok {
-				endDate.SetText(when.Format(model.DATEFORMAT))
-			}
-		})
+// Shows the form to add an absence or to edit the given absence
+func (vpv *VacationPlannerView) absenceForm(v *db.Absence) {
+	isAdd := v == nil
 
-		dialog.ShowCustomConfirm(
-			"Select a date",
-			"OK",
-			"Cancel",
-			picker,
-			picker.OnActioned,
-			vpv.av.window,
-		)
-	})
+	typeOptions := make([]string, 0, len(db.AbsenceTypes))
+	for _, t := range db.AbsenceTypes {
+		typeOptions = append(typeOptions, fwidget.AbsenceLabel(t))
+	}
+	absenceType := widget.NewSelect(typeOptions, nil)
+	absenceType.SetSelectedIndex(0)
 
-	dialog.ShowForm("Add Vacation", "Add", "Cancel",
+	startDate := newDateEntry(vpv.av.window)
+	endDate := newDateEntry(vpv.av.window)
+	halfDayStart := widget.NewCheck(lang.L("halfDayStart"), nil)
+	halfDayEnd := widget.NewCheck(lang.L("halfDayEnd"), nil)
+
+	title, confirm := lang.L("addAbsence"), lang.L("add")
+	if !isAdd {
+		title, confirm = lang.L("editAbsence"), lang.L("edit")
+		absenceType.SetSelectedIndex(slices.Index(db.AbsenceTypes, v.Type))
+		startDate.SetText(v.StartDate.Format(model.DATEFORMAT))
+		endDate.SetText(v.EndDate.Format(model.DATEFORMAT))
+		halfDayStart.SetChecked(v.HalfDayStart)
+		halfDayEnd.SetChecked(v.HalfDayEnd)
+	}
+
+	dialog.ShowForm(title, confirm, lang.L("cancel"),
 		[]*widget.FormItem{
-			{Text: "Start Date", Widget: startDate},
-			{Text: "End Date", Widget: endDate},
+			{Text: lang.L("absenceType"), Widget: absenceType},
+			{Text: lang.L("startDate"), Widget: startDate},
+			{Text: lang.L("endDate"), Widget: endDate},
+			{Text: lang.L("halfDay"), Widget: container.NewVBox(halfDayStart, halfDayEnd)},
 		}, func(submitted bool) {
 			if submitted {
 				s, err := time.Parse(model.DATEFORMAT, startDate.Text)
 				if err != nil {
 					log.Error(err)
 					dialog.ShowError(err, vpv.av.window)
+					return
 				}
 
 				e, err := time.Parse(model.DATEFORMAT, endDate.Text)
 				if err != nil {
 					log.Error(err)
 					dialog.ShowError(err, vpv.av.window)
+					return
 				}
 
-				vpv.addVacation(&db.Vacation{
-					StartDate: s,
-					EndDate:   e,
-				})
+				changed := &db.Absence{
+					Type:         db.AbsenceTypes[absenceType.SelectedIndex()],
+					StartDate:    s,
+					EndDate:      e,
+					HalfDayStart: halfDayStart.Checked,
+					HalfDayEnd:   halfDayEnd.Checked,
+				}
+				if isAdd {
+					vpv.confirmBudget(changed, func() { vpv.addAbsence(changed) })
+				} else {
+					changed.ID = v.ID
+					vpv.confirmBudget(changed, func() { vpv.updateAbsence(changed) })
+				}
 			}
 		}, vpv.av.window)
 }
 
-func (vpv *VacationPlannerView) deleteVacationForm() {
-	// TODO: Implement delete vacation form
-}
+// Creates a date entry with a button to open a date picker
+func newDateEntry(w fyne.Window) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("01.01.1970")
+	entry.ActionItem = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), func() {
+		when, err := time.Parse(model.DATEFORMAT, entry.Text)
+		if err != nil {
+			when = time.Now()
+		}
 
-func (vpv *VacationPlannerView) editVacationForm() {
-	// TODO: Implement edit vacation form
+		picker := datepicker.NewDatePicker(when, time.Monday, func(when time.Time, ok bool) {
+			if ok {
+				entry.SetText(when.Format(model.DATEFORMAT))
+			}
+		})
+
+		dialog.ShowCustomConfirm(
+			lang.L("selectDate"),
+			lang.L("confirm"),
+			lang.L("cancel"),
+			picker,
+			picker.OnActioned,
+			w,
+		)
+	})
+	return entry
 }
 
-func (vpv *VacationPlannerView) UpdateVacations(vacations []*db.Vacation) {
-	vpv.vacations = vacations
+func (vpv *VacationPlannerView) UpdateAbsences(absences []*db.Absence) {
+	calc := vpv.av.newCalculator(service.ReadProperties(vpv.av.a))
+	vpv.days = make(map[int64]float64, len(absences))
+	for _, a := range absences {
+		vpv.days[a.ID] = calc.AbsenceDays(a)
+	}
+
+	year := time.Now().Year()
+	vpv.budget.SetText(formatVacationBudget(calc.VacationBudget(year, time.Now())))
+
+	vpv.absences = absences
+	vpv.list.UnselectAll()
+	vpv.selectedAbsence = nil
 	vpv.container.Refresh()
 }
 
-func (vpv *VacationPlannerView) addVacation(v *db.Vacation) {
-	_, err := vpv.repo.AddVacation(v)
-	if err != nil {
+// Warns before saving a vacation which exceeds the vacation budget of a year
+func (vpv *VacationPlannerView) confirmBudget(v *db.Absence, save func()) {
+	if !service.ConsumesVacationBudget(v.Type) {
+		save()
+		return
+	}
+
+	calc := vpv.av.newCalculator(service.ReadProperties(vpv.av.a))
+	// An edited absence replaces its stored version
+	absences := []*db.Absence{v}
+	for _, stored := range calc.Absences {
+		if stored.ID != v.ID || v.ID == 0 {
+			absences = append(absences, stored)
+		}
+	}
+	calc.Absences = absences
+
+	for year := v.StartDate.Year(); year <= v.EndDate.Year(); year++ {
+		budget := calc.VacationBudget(year, time.Now())
+		if budget.Remaining >= 0 {
+			continue
+		}
+		msg := lang.L("vacationBudgetExceeded", map[string]any{
+			"Year": year,
+			"Days": formatDays(-budget.Remaining),
+		})
+		dialog.ShowConfirm(lang.L("vacationBudget"), msg, func(ok bool) {
+			if ok {
+				save()
+			}
+		}, vpv.av.window)
+		return
+	}
+	save()
+}
+
+func formatVacationBudget(b *service.VacationBudget) string {
+	text := lang.L("vacationBudgetSummary", map[string]any{
+		"Year":        b.Year,
+		"Entitlement": formatDays(b.Entitlement),
+		"Taken":       formatDays(b.Taken),
+		"Planned":     formatDays(b.Planned),
+		"Remaining":   formatDays(b.Remaining),
+	})
+	if b.CarryOver > 0 {
+		text += "\n" + lang.L("vacationCarryOver", map[string]any{
+			"Days":    formatDays(b.CarryOver),
+			"Until":   b.CarryOverCutoff.Format(model.DATEFORMAT),
+			"Expired": formatDays(b.CarryOverExpired),
+		})
+	}
+	return text
+}
+
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
+
+func (vpv *VacationPlannerView) addAbsence(v *db.Absence) {
+	// The scheduler updates the views after the change
+	if _, err := vpv.repo.AddAbsence(v); err != nil {
+		log.Error(err)
+		dialog.ShowError(err, vpv.av.window)
+	}
+}
+
+func (vpv *VacationPlannerView) updateAbsence(v *db.Absence) {
+	if _, err := vpv.repo.UpdateAbsence(v); err != nil {
 		log.Error(err)
 		dialog.ShowError(err, vpv.av.window)
-	} else {
-		vpv.av.RefreshData()
 	}
 }
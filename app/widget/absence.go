@@ -0,0 +1,48 @@
+package widget
+
+import (
+	"fyne.io/fyne/v2/lang"
+	"github.com/FyningTime/FyningTime/app/model/db"
+)
+
+// Markers of the absence types in the calendar
+var absenceMarkers = map[db.AbsenceType]string{
+	db.AbsenceVacation:     "🌴",
+	db.AbsenceSick:         "🤒",
+	db.AbsenceCompTime:     "⏳",
+	db.AbsenceBusinessTrip: "🧳",
+	db.AbsenceTraining:     "🎓",
+	db.AbsenceParental:     "👶",
+	db.AbsenceUnpaid:       "💤",
+}
+
+var absenceNames = map[db.AbsenceType]string{
+	db.AbsenceVacation:     "absenceVacation",
+	db.AbsenceSick:         "absenceSick",
+	db.AbsenceCompTime:     "absenceCompTime",
+	db.AbsenceBusinessTrip: "absenceBusinessTrip",
+	db.AbsenceTraining:     "absenceTraining",
+	db.AbsenceParental:     "absenceParental",
+	db.AbsenceUnpaid:       "absenceUnpaid",
+}
+
+// Returns the calendar marker of an absence type
+func AbsenceMarker(t db.AbsenceType) string {
+	if marker, ok := absenceMarkers[t]; ok {
+		return marker
+	}
+	return absenceMarkers[db.AbsenceVacation]
+}
+
+// Returns the translated name of an absence type
+func AbsenceName(t db.AbsenceType) string {
+	if key, ok := absenceNames[t]; ok {
+		return lang.L(key)
+	}
+	return string(t)
+}
+
+// Returns the marker followed by the name of an absence type
+func AbsenceLabel(t db.AbsenceType) string {
+	return AbsenceMarker(t) + " " + AbsenceName(t)
+}
@@ -108,10 +108,14 @@ type Calendar struct {
 	onSelected func(time.Time)
 
 	// --- Custom Code ---
-	w         fyne.Window
-	vacations []*db.Vacation
+	w        fyne.Window
+	absences []*db.Absence
+	holidays HolidayLookup
 }
 
+// HolidayLookup returns the holiday on the given date or nil
+type HolidayLookup func(date time.Time) *db.Holiday
+
 func (c *Calendar) daysOfMonth() []fyne.CanvasObject {
 	start := time.Date(c.currentTime.Year(), c.currentTime.Month(), 1, 0, 0, 0, 0, c.currentTime.Location())
 	buttons := []fyne.CanvasObject{}
@@ -150,36 +154,23 @@ func (c *Calendar) daysOfMonth() []fyne.CanvasObject {
 			c.onSelected(selectedDate)
 
 			var popupContent *fyne.Container
-			var vacationLbl *widget.Label
-
-			for _, vs := range c.vacations {
-				for _, v := range getAllDatesBetween(vs.StartDate, vs.EndDate) {
-					if v.Day() == selectedDate.Day() && v.Month() == selectedDate.Month() {
-						vacationLbl = widget.NewLabel("🌴 Vacation")
-						if v.Day() == time.Now().Day() && v.Month() == time.Now().Month() {
-							vacationLbl.Text = "Today is 🌴"
-						}
-						break
-					}
+
+			popupContent = container.NewVBox(
+				widget.NewLabel(selectedDate.Format("Monday, 02 January 2006")),
+			)
+			if c.holidays != nil {
+				if h := c.holidays(selectedDate); h != nil {
+					popupContent.Add(widget.NewLabel("🎉 " + h.Name))
 				}
 			}
-
-			if vacationLbl != nil {
-				popupContent = container.NewVBox(
-					widget.NewLabel(selectedDate.Format("Monday, 02 January 2006")),
-					vacationLbl,
-					widget.NewButton("Close", func() {
-						popup.Hide()
-					}),
-				)
-			} else {
-				popupContent = container.NewVBox(
-					widget.NewLabel(selectedDate.Format("Monday, 02 January 2006")),
-					widget.NewButton("Close", func() {
-						popup.Hide()
-					}),
-				)
+			for _, a := range c.absences {
+				if a.DayShare(selectedDate) > 0 {
+					popupContent.Add(widget.NewLabel(AbsenceLabel(a.Type)))
+				}
 			}
+			popupContent.Add(widget.NewButton("Close", func() {
+				popup.Hide()
+			}))
 
 			popup = widget.NewModalPopUp(popupContent, c.w.Canvas())
 			popup.Show()
@@ -226,7 +217,8 @@ func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
 		c.currentTime = time.Date(c.currentTime.Year(), c.currentTime.Month(), 1, 0, 0, 0, 0, c.currentTime.Location())
 		c.monthLabel.SetText(c.monthYear())
 		c.dates.Objects = c.calendarObjects()
-		c.renderVacations()
+		c.renderAbsences()
+		c.renderHolidays()
 		c.highlightToday()
 	})
 	c.monthPrevious.Importance = widget.LowImportance
@@ -235,7 +227,8 @@ func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
 		c.currentTime = c.currentTime.AddDate(0, 1, 0)
 		c.monthLabel.SetText(c.monthYear())
 		c.dates.Objects = c.calendarObjects()
-		c.renderVacations()
+		c.renderAbsences()
+		c.renderHolidays()
 		c.highlightToday()
 	})
 	c.monthNext.Importance = widget.LowImportance
@@ -248,7 +241,8 @@ func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
 	c.dates = container.New(newCalendarLayout(), c.calendarObjects()...)
 	dateContainer := container.NewBorder(nav, nil, nil, nil, c.dates)
 
-	c.renderVacations()
+	c.renderAbsences()
+	c.renderHolidays()
 	c.highlightToday()
 
 	return widget.NewSimpleRenderer(dateContainer)
@@ -256,10 +250,10 @@ func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
 
 // NewCalendar creates a calendar instance
 func NewCalendar(
-	w fyne.Window, v []*db.Vacation, cT time.Time, onSelected func(time.Time)) *Calendar {
+	w fyne.Window, a []*db.Absence, cT time.Time, onSelected func(time.Time)) *Calendar {
 	c := &Calendar{
 		w:           w,
-		vacations:   v,
+		absences:    a,
 		currentTime: cT,
 		onSelected:  onSelected,
 	}
@@ -273,49 +267,72 @@ func NewCalendar(
 func (c *Calendar) highlightToday() {
 	for _, o := range c.dates.Objects {
 		if b, ok := o.(*widget.Button); ok {
-			if b.Text == strconv.Itoa(time.Now().Day()) && time.Now().Month() == c.currentTime.Month() {
+			if buttonDay(b) == strconv.Itoa(time.Now().Day()) && time.Now().Month() == c.currentTime.Month() {
 				b.Importance = widget.HighImportance
 			}
 		}
 	}
 }
 
-func (c *Calendar) renderVacations() {
-	if c.vacations != nil && c.dates != nil {
-		if len(c.vacations) > 0 {
-			for _, vs := range c.vacations {
-				for _, v := range getAllDatesBetween(vs.StartDate, vs.EndDate) {
-					for _, o := range c.dates.Objects {
-						if b, ok := o.(*widget.Button); ok {
-							if b.Text == strconv.Itoa(v.Day()) && v.Month() == c.currentTime.Month() {
-								b.Text += "\n🌴"
-							}
-						}
-					}
-				}
+func (c *Calendar) renderAbsences() {
+	if c.dates == nil {
+		return
+	}
+
+	for _, o := range c.dates.Objects {
+		if b, ok := o.(*widget.Button); ok {
+			// Remove the markers of deleted absences
+			for _, marker := range absenceMarkers {
+				b.Text = strings.Replace(b.Text, "\n"+marker, "", -1)
 			}
-		} else {
-			for _, o := range c.dates.Objects {
-				if b, ok := o.(*widget.Button); ok {
-					if strings.Contains(b.Text, "🌴") {
-						b.Text = strings.Replace(b.Text, "\n🌴", "", -1)
-					}
+
+			day, err := strconv.Atoi(buttonDay(b))
+			if err != nil {
+				continue
+			}
+			date := time.Date(c.currentTime.Year(), c.currentTime.Month(), day, 0, 0, 0, 0, time.UTC)
+			for _, a := range c.absences {
+				if marker := AbsenceMarker(a.Type); a.DayShare(date) > 0 && !strings.Contains(b.Text, marker) {
+					b.Text += "\n" + marker
 				}
 			}
 		}
 	}
 }
 
-func (c *Calendar) UpdateVacations(v []*db.Vacation) {
-	c.vacations = v
-	c.renderVacations()
+func (c *Calendar) UpdateAbsences(a []*db.Absence) {
+	c.absences = a
+	c.renderAbsences()
 }
 
-// Funktion, um alle Daten zwischen zwei Daten zu erhalten
-func getAllDatesBetween(startDate, endDate time.Time) []time.Time {
-	var dates []time.Time
-	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		dates = append(dates, d)
+func (c *Calendar) renderHolidays() {
+	if c.holidays == nil || c.dates == nil {
+		return
 	}
-	return dates
+
+	for _, o := range c.dates.Objects {
+		if b, ok := o.(*widget.Button); ok {
+			day, err := strconv.Atoi(buttonDay(b))
+			if err != nil {
+				continue
+			}
+			date := time.Date(c.currentTime.Year(), c.currentTime.Month(), day, 0, 0, 0, 0, time.UTC)
+			if c.holidays(date) != nil && !strings.Contains(b.Text, "🎉") {
+				b.Text += "\n🎉"
+			} else if c.holidays(date) == nil && strings.Contains(b.Text, "🎉") {
+				b.Text = strings.Replace(b.Text, "\n🎉", "", -1)
+			}
+		}
+	}
+}
+
+func (c *Calendar) UpdateHolidays(h HolidayLookup) {
+	c.holidays = h
+	c.renderHolidays()
+}
+
+// Returns the day number of a date button without the markers
+func buttonDay(b *widget.Button) string {
+	day, _, _ := strings.Cut(b.Text, "\n")
+	return day
 }
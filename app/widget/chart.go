@@ -0,0 +1,216 @@
+package widget
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Declare conformity with the widget interfaces
+var (
+	_ fyne.Widget = (*BarChart)(nil)
+	_ fyne.Widget = (*LineChart)(nil)
+)
+
+// ChartValue is a labeled value of a chart
+type ChartValue struct {
+	Label string
+	Value float64
+	// Drawn as a marker on the bar, e.g. the target hours, NaN if missing
+	Mark float64
+}
+
+// BarChart draws a bar per value with its label below
+type BarChart struct {
+	widget.BaseWidget
+	values []ChartValue
+}
+
+func NewBarChart() *BarChart {
+	b := &BarChart{}
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+// Replaces the values and redraws the chart
+func (b *BarChart) SetValues(values []ChartValue) {
+	b.values = values
+	b.Refresh()
+}
+
+func (b *BarChart) CreateRenderer() fyne.WidgetRenderer {
+	return &chartRenderer{draw: b.draw}
+}
+
+func (b *BarChart) draw(size fyne.Size) []fyne.CanvasObject {
+	if len(b.values) == 0 {
+		return nil
+	}
+
+	low, high := 0.0, 0.0
+	for _, v := range b.values {
+		low, high = math.Min(low, v.Value), math.Max(high, v.Value)
+		if !math.IsNaN(v.Mark) {
+			high = math.Max(high, v.Mark)
+		}
+	}
+	area := newChartArea(size, low, high)
+
+	objects := []fyne.CanvasObject{area.axis()}
+	slot := size.Width / float32(len(b.values))
+	barWidth := slot * 0.7
+	for i, v := range b.values {
+		x := slot*float32(i) + (slot-barWidth)/2
+		top, bottom := area.y(math.Max(v.Value, 0)), area.y(math.Min(v.Value, 0))
+
+		bar := canvas.NewRectangle(theme.Color(theme.ColorNamePrimary))
+		if v.Value < 0 {
+			bar.FillColor = theme.Color(theme.ColorNameError)
+		}
+		bar.Move(fyne.NewPos(x, top))
+		bar.Resize(fyne.NewSize(barWidth, bottom-top))
+		objects = append(objects, bar)
+
+		if !math.IsNaN(v.Mark) {
+			mark := canvas.NewLine(theme.Color(theme.ColorNameForeground))
+			mark.StrokeWidth = 2
+			mark.Position1 = fyne.NewPos(x-2, area.y(v.Mark))
+			mark.Position2 = fyne.NewPos(x+barWidth+2, area.y(v.Mark))
+			objects = append(objects, mark)
+		}
+
+		objects = append(objects, area.label(v.Label, slot*float32(i), slot))
+	}
+	return objects
+}
+
+// LineChart connects the values with lines, e.g. the overtime over time
+type LineChart struct {
+	widget.BaseWidget
+	values []ChartValue
+}
+
+func NewLineChart() *LineChart {
+	l := &LineChart{}
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// Replaces the values and redraws the chart
+func (l *LineChart) SetValues(values []ChartValue) {
+	l.values = values
+	l.Refresh()
+}
+
+func (l *LineChart) CreateRenderer() fyne.WidgetRenderer {
+	return &chartRenderer{draw: l.draw}
+}
+
+func (l *LineChart) draw(size fyne.Size) []fyne.CanvasObject {
+	if len(l.values) == 0 {
+		return nil
+	}
+
+	low, high := 0.0, 0.0
+	for _, v := range l.values {
+		low, high = math.Min(low, v.Value), math.Max(high, v.Value)
+	}
+	area := newChartArea(size, low, high)
+
+	objects := []fyne.CanvasObject{area.axis()}
+	step := size.Width
+	if len(l.values) > 1 {
+		step = size.Width / float32(len(l.values)-1)
+	}
+	x := func(i int) float32 {
+		if len(l.values) == 1 {
+			return size.Width / 2
+		}
+		return step * float32(i)
+	}
+	for i := 1; i < len(l.values); i++ {
+		line := canvas.NewLine(theme.Color(theme.ColorNamePrimary))
+		line.StrokeWidth = 2
+		line.Position1 = fyne.NewPos(x(i-1), area.y(l.values[i-1].Value))
+		line.Position2 = fyne.NewPos(x(i), area.y(l.values[i].Value))
+		objects = append(objects, line)
+	}
+
+	// Only the first and the last label fit below a trend
+	labelWidth := size.Width / 3
+	objects = append(objects, area.label(l.values[0].Label, 0, labelWidth))
+	if len(l.values) > 1 {
+		objects = append(objects, area.label(l.values[len(l.values)-1].Label, size.Width-labelWidth, labelWidth))
+	}
+	return objects
+}
+
+// chartArea maps values to the height of a chart above the labels
+type chartArea struct {
+	size      fyne.Size
+	low, high float64
+	height    float32
+}
+
+func newChartArea(size fyne.Size, low float64, high float64) *chartArea {
+	if high == low {
+		high = low + 1
+	}
+	labelHeight := theme.CaptionTextSize() + theme.Padding()
+	return &chartArea{size: size, low: low, high: high, height: size.Height - labelHeight}
+}
+
+// Returns the vertical position of a value
+func (a *chartArea) y(value float64) float32 {
+	return a.height - float32((value-a.low)/(a.high-a.low))*a.height
+}
+
+// Returns the line of the value 0
+func (a *chartArea) axis() fyne.CanvasObject {
+	axis := canvas.NewLine(theme.Color(theme.ColorNameDisabled))
+	axis.Position1 = fyne.NewPos(0, a.y(0))
+	axis.Position2 = fyne.NewPos(a.size.Width, a.y(0))
+	return axis
+}
+
+// Returns a label centered below the chart
+func (a *chartArea) label(text string, x float32, width float32) fyne.CanvasObject {
+	label := canvas.NewText(text, theme.Color(theme.ColorNameForeground))
+	label.TextSize = theme.CaptionTextSize()
+	label.Alignment = fyne.TextAlignCenter
+	label.Move(fyne.NewPos(x, a.height+theme.Padding()/2))
+	label.Resize(fyne.NewSize(width, theme.CaptionTextSize()))
+	return label
+}
+
+// chartRenderer draws the chart again whenever its size or data changes
+type chartRenderer struct {
+	draw    func(size fyne.Size) []fyne.CanvasObject
+	size    fyne.Size
+	objects []fyne.CanvasObject
+}
+
+func (r *chartRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.objects = r.draw(size)
+}
+
+func (r *chartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(200, 120)
+}
+
+func (r *chartRenderer) Refresh() {
+	r.objects = r.draw(r.size)
+	for _, o := range r.objects {
+		o.Refresh()
+	}
+}
+
+func (r *chartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *chartRenderer) Destroy() {}
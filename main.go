@@ -3,9 +3,16 @@ package main
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/FyningTime/FyningTime/app/cli"
+	"github.com/FyningTime/FyningTime/app/model"
+	"github.com/FyningTime/FyningTime/app/repo"
 	"github.com/FyningTime/FyningTime/app/service"
 	apptheme "github.com/FyningTime/FyningTime/app/theme"
 	"github.com/FyningTime/FyningTime/app/view"
@@ -27,6 +34,12 @@ func main() {
 	var devFlag bool
 	initLogging(devFlag)
 
+	// Commands are run without a window
+	cliMode := flag.NArg() > 0
+	if cliMode && log.GetLevel() != log.DebugLevel {
+		log.SetLevel(log.WarnLevel)
+	}
+
 	progName := "FyningTime"
 	log.Info("Welcome to " + progName)
 	a := app.NewWithID("com.github.fyningtime.fyningtime")
@@ -41,6 +54,10 @@ func main() {
 	// Open database
 	db := GetDB(settings.SavedDbPath)
 
+	if cliMode {
+		os.Exit(runCLI(db, settings))
+	}
+
 	switch settings.ThemeVariant {
 	case 1:
 		a.Settings().SetTheme(apptheme.NewPastelleDark())
@@ -61,33 +78,81 @@ func main() {
 
 	mv := av.CreateUI(w, a)
 	av.RefreshData()
+	av.StartSession()
+	av.StartIdleMonitor()
+
+	// Clock out when the session of the user ends
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+	go func() {
+		sig := <-signals
+		log.Info("Received signal", "signal", sig)
+		av.Close()
+		fyne.Do(a.Quit)
+	}()
 
 	// Set shortcuts
 	setShortcuts(w, CreateAppShortcuts(av))
 
 	if desk, ok := a.(desktop.App); ok {
-		m := fyne.NewMenu("FyningTime",
-			fyne.NewMenuItem(lang.L("addTimeEntry"), func() {
-				av.AddTimeEntry()
-			}),
-			// Show overtime in dialog
-			fyne.NewMenuItem(lang.L("showOvertime"), func() {
-				overtime := av.GetOvertime()
-				dialog.ShowInformation("Overtime", fmt.Sprintf(lang.L("currentOvertime")+": %s", overtime), w)
-			}),
-			fyne.NewMenuItem(lang.L("about"), func() {
-				dialog.NewInformation(lang.L("about"), lang.L("about-ft"), w)
-			}),
-		)
-
-		desk.SetSystemTrayMenu(m)
+		trayMenu := func() {
+			m := fyne.NewMenu("FyningTime",
+				fyne.NewMenuItem(lang.L("addTimeEntry"), func() {
+					av.AddTimeEntry()
+				}),
+				av.ProjectMenuItem(),
+				// Show overtime in dialog
+				fyne.NewMenuItem(lang.L("showOvertime"), func() {
+					overtime := av.GetOvertime()
+					dialog.ShowInformation("Overtime", fmt.Sprintf(lang.L("currentOvertime")+": %s", overtime), w)
+				}),
+				fyne.NewMenuItem(lang.L("about"), func() {
+					dialog.NewInformation(lang.L("about"), lang.L("about-ft"), w)
+				}),
+			)
+
+			desk.SetSystemTrayMenu(m)
+		}
+		trayMenu()
+		// The current project is switched from the tray as well
+		av.OnProjectsLoaded = trayMenu
 	}
 
 	w.SetMainMenu(
 		fyne.NewMainMenu(
 			fyne.NewMenu(lang.L("file"),
 				fyne.NewMenuItem(lang.L("settings"), func() {
-					view.GetSettingsView(w, a).Show()
+					view.GetSettingsView(w, a, av.SettingsChanged).Show()
+				}),
+				fyne.NewMenuItem(lang.L("contracts"), func() {
+					av.ShowContracts()
+				}),
+				fyne.NewMenuItem(lang.L("projects"), func() {
+					av.ShowProjects()
+				}),
+				fyne.NewMenuItem(lang.L("projectReport"), func() {
+					av.ShowProjectReport()
+				}),
+				fyne.NewMenuItem(lang.L("projectBudgets"), func() {
+					av.ShowProjectBudgets()
+				}),
+				fyne.NewMenuItem(lang.L("importHolidays"), func() {
+					av.ImportHolidays()
+				}),
+				fyne.NewMenuItem(lang.L("deleteImportedHolidays"), func() {
+					av.DeleteImportedHolidays()
+				}),
+				fyne.NewMenuItem(lang.L("importWorktime"), func() {
+					av.ImportWorktime()
+				}),
+				fyne.NewMenuItem(lang.L("exportWorktime"), func() {
+					av.ExportWorktime()
+				}),
+				fyne.NewMenuItem(lang.L("timesheet"), func() {
+					av.ExportTimesheet()
+				}),
+				fyne.NewMenuItem(lang.L("complianceReport"), func() {
+					av.ShowComplianceReport()
 				}),
 				fyne.NewMenuItem(lang.L("about"), func() {
 					dialog.ShowInformation(lang.L("about"), lang.L("about-ft"), w)
@@ -97,6 +162,7 @@ func main() {
 	)
 
 	w.SetOnClosed(func() {
+		av.Close()
 		log.Info("Closing database")
 		db.Close()
 		a.Quit()
@@ -125,6 +191,27 @@ func GetDB(filePath string) *sql.DB {
 	return db
 }
 
+// Runs the command line interface and returns the exit code
+func runCLI(db *sql.DB, settings *model.Settings) int {
+	defer db.Close()
+
+	r := repo.NewSQLiteRepository(db)
+	if err := r.Migrate(); err != nil {
+		log.Error(err)
+		return 1
+	}
+
+	c := cli.NewCLI(os.Stdout, service.NewTimeEntryService(r), settings)
+	if err := c.Run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if errors.Is(err, cli.ErrUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
 func initLogging(devFlag bool) {
 	flag.BoolVar(&devFlag, "d", false, "Development flag")
 	flag.Parse()
@@ -197,11 +284,27 @@ func CreateAppShortcuts(av *view.AppView) []AppShortcuts {
 		modifier: fyne.KeyModifierControl,
 	}
 
+	undoShortcut := AppShortcuts{
+		name:     "Undo",
+		callback: func() { av.Undo() },
+		keyname:  fyne.KeyZ,
+		modifier: fyne.KeyModifierControl,
+	}
+
+	redoShortcut := AppShortcuts{
+		name:     "Redo",
+		callback: func() { av.Redo() },
+		keyname:  fyne.KeyZ,
+		modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}
+
 	return []AppShortcuts{
 		unselectShortcut,
 		deleteSelectedTimeEntryShortcut,
 		addNewTimeEntryShortcut,
 		editSelectedTimeEntryShortcut,
 		refreshDataShortcut,
+		undoShortcut,
+		redoShortcut,
 	}
 }